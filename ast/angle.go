@@ -0,0 +1,42 @@
+package ast
+
+import (
+	"regexp"
+
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+// dmsPattern 匹配度分秒形式的角度，如 30°、30°15'、30°15'10"，分和秒也可以写作 ′、″
+var dmsPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)°(?:(\d+(?:\.\d+)?)['′])?(?:(\d+(?:\.\d+)?)["″])?$`)
+
+// AngleLiteral 表示度分秒形式的角度常量，求值结果使用计算器的角度单位
+type AngleLiteral struct {
+	Degrees string
+	Minutes string
+	Seconds string
+	calc    *calculator.Calculator
+}
+
+func (a *AngleLiteral) Evaluate() (Value, error) {
+	return realResult(a.calc.DMS(a.Degrees, a.Minutes, a.Seconds))
+}
+
+func (a *AngleLiteral) Type() NodeType {
+	return AngleNode
+}
+
+// parseAngle 解析度分秒形式的角度常量，省略的分和秒按 0 处理
+func (p *Parser) parseAngle(token string) Node {
+	m := dmsPattern.FindStringSubmatch(token)
+	if m == nil {
+		panic(syntaxError("无效的角度: " + token))
+	}
+	angle := &AngleLiteral{Degrees: m[1], Minutes: "0", Seconds: "0", calc: p.calc}
+	if m[2] != "" {
+		angle.Minutes = m[2]
+	}
+	if m[3] != "" {
+		angle.Seconds = m[3]
+	}
+	return angle
+}
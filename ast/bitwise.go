@@ -0,0 +1,103 @@
+package ast
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+// BaseIntegerLiteral 表示带进制前缀的整数常量，如 0xFF、0b1010、0o17
+type BaseIntegerLiteral struct {
+	Value *big.Int
+}
+
+func (n *BaseIntegerLiteral) Evaluate() (Value, error) {
+	return Integer{n.Value}, nil
+}
+
+func (n *BaseIntegerLiteral) Type() NodeType {
+	return BaseIntegerNode
+}
+
+// BitwiseOperation 表示按位运算 & | xor << >> 与按位取反 ~，~ 只使用 Left
+//
+// 操作数必须为整数，结果为精确整数。
+type BitwiseOperation struct {
+	Left     Node
+	Right    Node
+	Operator string
+	calc     *calculator.Calculator
+}
+
+// bitOperand 对操作数求值并转换为整数字符串
+func (b *BitwiseOperation) bitOperand(n Node) (string, error) {
+	v, err := n.Evaluate()
+	if err != nil {
+		return "", err
+	}
+	d, err := toReal(toDecimal(b.calc, v), b.Operator)
+	if err != nil {
+		return "", err
+	}
+	return d.String(), nil
+}
+
+func (b *BitwiseOperation) Evaluate() (Value, error) {
+	left, err := b.bitOperand(b.Left)
+	if err != nil {
+		return nil, err
+	}
+	if b.Operator == "~" {
+		return integerResult(b.calc.BitNot(left))
+	}
+	right, err := b.bitOperand(b.Right)
+	if err != nil {
+		return nil, err
+	}
+	switch b.Operator {
+	case "&":
+		return integerResult(b.calc.BitAnd(left, right))
+	case "|":
+		return integerResult(b.calc.BitOr(left, right))
+	case "xor":
+		return integerResult(b.calc.BitXor(left, right))
+	case "<<":
+		return integerResult(b.calc.ShiftLeft(left, right))
+	case ">>":
+		return integerResult(b.calc.ShiftRight(left, right))
+	}
+	return nil, fmt.Errorf("未知的运算符: %s", b.Operator)
+}
+
+func (b *BitwiseOperation) Type() NodeType {
+	return BitwiseNode
+}
+
+// bitwiseLevels 按优先级从低到高排列的按位运算符，均低于加减
+var bitwiseLevels = [][]string{{"|"}, {"xor"}, {"&"}, {"<<", ">>"}}
+
+// parseBitwise 解析优先级不低于 bitwiseLevels[level] 的按位运算，如 1 << 4 | 0xF
+func (p *Parser) parseBitwise(level int) Node {
+	if level == len(bitwiseLevels) {
+		return p.parseSum()
+	}
+	left := p.parseBitwise(level + 1)
+	for p.pos < len(p.tokens) && slices.Contains(bitwiseLevels[level], p.tokens[p.pos]) {
+		operator := p.tokens[p.pos]
+		p.pos++
+		right := p.parseBitwise(level + 1)
+		left = &BitwiseOperation{Left: left, Right: right, Operator: operator, calc: p.calc}
+	}
+	return left
+}
+
+// parseBaseInteger 解析带进制前缀的整数常量
+func (p *Parser) parseBaseInteger(token string) Node {
+	n, err := calculator.ParseBaseInteger(token)
+	if err != nil {
+		panic(syntaxError(err.Error()))
+	}
+	return &BaseIntegerLiteral{Value: n}
+}
@@ -0,0 +1,141 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+// Money 表示以 Currency 计价的金额
+//
+// 运算过程中 Amount 保持工作精度，只在格式化时舍入到该货币的最小单位一次。
+type Money struct {
+	Amount   decimal.Decimal
+	Currency string
+}
+
+func (m Money) Format(calc *calculator.Calculator) string {
+	return calc.FormatMoney(m.Amount, m.Currency)
+}
+
+// moneyArithmetic 执行金额的四则运算，整数和有理数操作数应已转换为实数
+//
+// 不同货币相加减时右侧先按汇率换算为左侧的货币，零可以与任意金额相加减。
+// 金额与数相乘、金额除以数时保留货币，两个金额相除得到比值。
+func moneyArithmetic(calc *calculator.Calculator, op string, left, right Value) (Value, error) {
+	a, aok := left.(Money)
+	b, bok := right.(Money)
+	ra, raok := left.(Real)
+	rb, rbok := right.(Real)
+
+	switch {
+	case aok && bok && op != "*":
+		amount, err := calc.ConvertCurrency(b.Amount, b.Currency, a.Currency)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "+":
+			return Money{Amount: a.Amount.Add(amount), Currency: a.Currency}, nil
+		case "-":
+			return Money{Amount: a.Amount.Sub(amount), Currency: a.Currency}, nil
+		}
+		return realResult(calc.Divide(a.Amount.String(), amount.String()))
+
+	case aok && rbok:
+		return moneyScalar(calc, op, a, rb.Decimal, false)
+
+	case raok && bok:
+		return moneyScalar(calc, op, b, ra.Decimal, true)
+	}
+
+	for _, v := range []Value{left, right} {
+		if q, ok := v.(Quantity); ok {
+			return nil, fmt.Errorf("%w: 金额不能与带单位 %s 的量运算", calculator.ErrUnitMismatch, q.Unit.Name)
+		}
+	}
+	if aok && bok {
+		return nil, fmt.Errorf("%w: 金额 %s 与 %s 不能相乘", calculator.ErrUnitMismatch, a.Currency, b.Currency)
+	}
+	return nil, fmt.Errorf("%w: 金额只能与实数运算", calculator.ErrDomain)
+}
+
+// moneyScalar 计算金额 m 与实数 x 的运算，scalarLeft 表示 x 位于运算符左侧
+func moneyScalar(calc *calculator.Calculator, op string, m Money, x decimal.Decimal, scalarLeft bool) (Value, error) {
+	switch op {
+	case "+", "-":
+		if !x.IsZero() {
+			return nil, fmt.Errorf("%w: %s 金额不能与无货币的数相加减", calculator.ErrUnitMismatch, m.Currency)
+		}
+		if op == "-" && scalarLeft {
+			return Money{Amount: m.Amount.Neg(), Currency: m.Currency}, nil
+		}
+		return m, nil
+	case "*":
+		return Money{Amount: m.Amount.Mul(x), Currency: m.Currency}, nil
+	case "/":
+		if scalarLeft {
+			return nil, fmt.Errorf("%w: 数不能除以 %s 金额", calculator.ErrUnitMismatch, m.Currency)
+		}
+		amount, err := calc.DivideMoney(m.Amount, x)
+		if err != nil {
+			return nil, err
+		}
+		return Money{Amount: amount, Currency: m.Currency}, nil
+	}
+	return nil, fmt.Errorf("未知的运算符: %s", op)
+}
+
+// CurrencyLiteral 表示货币代码，如 USD，求值结果为 1 个该货币
+type CurrencyLiteral struct {
+	Currency string
+	calc     *calculator.Calculator
+}
+
+func (c *CurrencyLiteral) Evaluate() (Value, error) {
+	return Money{Amount: decimal.NewFromInt(1), Currency: c.Currency}, nil
+}
+
+func (c *CurrencyLiteral) Type() NodeType {
+	return CurrencyNode
+}
+
+// ExchangeOperation 表示货币换算，如 100 USD + 50 EUR in CNY
+type ExchangeOperation struct {
+	Operand  Node
+	Currency string
+	calc     *calculator.Calculator
+}
+
+func (e *ExchangeOperation) Evaluate() (Value, error) {
+	v, err := e.Operand.Evaluate()
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(Money)
+	if !ok {
+		return nil, fmt.Errorf("%w: 只有金额可以换算为 %s", calculator.ErrUnitMismatch, e.Currency)
+	}
+	amount, err := e.calc.ConvertCurrency(m.Amount, m.Currency, e.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return Money{Amount: amount, Currency: e.Currency}, nil
+}
+
+func (e *ExchangeOperation) Type() NodeType {
+	return ExchangeNode
+}
+
+// isCurrencyToken 判断下标为 i 的标记是否为汇率表中的货币代码
+func (p *Parser) isCurrencyToken(i int) bool {
+	return i < len(p.tokens) && p.calc.IsCurrency(p.tokens[i])
+}
+
+// parseCurrency 解析货币代码
+func (p *Parser) parseCurrency() Node {
+	code := p.tokens[p.pos]
+	p.pos++
+	return &CurrencyLiteral{Currency: code, calc: p.calc}
+}
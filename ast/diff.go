@@ -0,0 +1,249 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+// DiffOperation 表示符号求导 diff(expr, x)，给出 At 时计算导数在该点的值
+type DiffOperation struct {
+	Expr Node
+	Var  string
+	At   Node
+	calc *calculator.Calculator
+}
+
+// Evaluate 返回导数的中缀表达式；给出求值点时以精确模式代入计算，结果尽量为整数或分数
+func (d *DiffOperation) Evaluate() (Value, error) {
+	b := builder{d.calc}
+	expr, err := b.symbolic(d.Expr)
+	if err != nil {
+		return nil, err
+	}
+	deriv, err := b.derivative(expr, d.Var)
+	if err != nil {
+		return nil, err
+	}
+	if d.At == nil {
+		return Expression{deriv}, nil
+	}
+	exact := *d.calc
+	calculator.WithExact()(&exact)
+	at := transform(d.At, &exact, nil)
+	return substitute(deriv, d.Var, at, &exact).Evaluate()
+}
+
+func (d *DiffOperation) Type() NodeType {
+	return DiffNode
+}
+
+// derivative 返回表达式 n 对变量 x 的导数，n 应已经过 symbolic 处理
+func (b builder) derivative(n Node, x string) (Node, error) {
+	if !dependsOn(n, x) {
+		return b.num(decimal.Zero), nil
+	}
+	if name, u, ok := unaryOperand(n); ok {
+		du, err := b.derivative(u, x)
+		if err != nil {
+			return nil, err
+		}
+		return b.mul(b.outer(name, u), du), nil
+	}
+	switch n := n.(type) {
+	case *Variable:
+		return b.num(one), nil
+	case *BinaryOperator:
+		u, v := n.Left, n.Right
+		du, err := b.derivative(u, x)
+		if err != nil {
+			return nil, err
+		}
+		dv, err := b.derivative(v, x)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Operator {
+		case "+":
+			return b.add(du, dv), nil
+		case "-":
+			return b.sub(du, dv), nil
+		case "*":
+			return b.add(b.mul(du, v), b.mul(u, dv)), nil
+		case "/":
+			return b.div(b.sub(b.mul(du, v), b.mul(u, dv)), b.pow(v, b.num(two))), nil
+		}
+		return nil, fmt.Errorf("%w: 不支持对运算符 %s 求导", calculator.ErrDomain, n.Operator)
+	case *NegOperation:
+		du, err := b.derivative(n.Operand, x)
+		if err != nil {
+			return nil, err
+		}
+		return b.neg(du), nil
+	case *PowOperation:
+		u, e := n.Base, n.Exponent
+		du, err := b.derivative(u, x)
+		if err != nil {
+			return nil, err
+		}
+		de, err := b.derivative(e, x)
+		if err != nil {
+			return nil, err
+		}
+		switch _, isE := u.(*EConstant); {
+		case !dependsOn(e, x):
+			// (u^n)' = n·u^(n-1)·u'
+			return b.mul(b.mul(e, b.pow(u, b.sub(e, b.num(one)))), du), nil
+		case isE:
+			return b.mul(n, de), nil
+		case !dependsOn(u, x):
+			// (a^v)' = a^v·ln(a)·v'
+			return b.mul(b.mul(n, b.fn("ln", u)), de), nil
+		}
+		// (u^v)' = u^v·(v'·ln(u) + v·u'/u)
+		return b.mul(n, b.add(b.mul(de, b.fn("ln", u)), b.div(b.mul(e, du), u))), nil
+	case *LogOperation:
+		if dependsOn(n.Base, x) {
+			// log(v, a) = ln(v)/ln(a)
+			return b.derivative(b.div(b.fn("ln", n.Value), b.fn("ln", n.Base)), x)
+		}
+		dv, err := b.derivative(n.Value, x)
+		if err != nil {
+			return nil, err
+		}
+		return b.div(dv, b.mul(n.Value, b.fn("ln", n.Base))), nil
+	case *DiffOperation:
+		inner, err := b.derivative(n.Expr, n.Var)
+		if err != nil {
+			return nil, err
+		}
+		if n.At != nil {
+			inner = substitute(inner, n.Var, n.At, b.calc)
+		}
+		return b.derivative(inner, x)
+	case *SimplifyOperation:
+		s, err := b.simplify(n.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return b.derivative(b.node(s), x)
+	case *FunctionCall:
+		return nil, fmt.Errorf("%w: 不支持对函数 %s 求导", calculator.ErrDomain, n.Name)
+	}
+	return nil, fmt.Errorf("%w: 不支持对 %s 求导", calculator.ErrDomain, Infix(n))
+}
+
+// outer 返回单参数函数 name 在 u 处的导数
+//
+// 三角函数按计算器的角度单位求导：角度制下 sin(x)' = PI/180·cos(x)，反三角函数的导数相应除以该系数。
+func (b builder) outer(name string, u Node) Node {
+	var k Node
+	switch b.calc.AngleUnit() {
+	case calculator.Degrees:
+		k = b.div(&PIConstant{calc: b.calc}, b.num(decimal.NewFromInt(180)))
+	case calculator.Gradians:
+		k = b.div(&PIConstant{calc: b.calc}, b.num(decimal.NewFromInt(200)))
+	default:
+		k = b.num(one)
+	}
+	square := b.pow(u, b.num(two))
+	switch name {
+	case "sqrt":
+		return b.div(b.num(one), b.mul(b.num(two), b.fn("sqrt", u)))
+	case "sin":
+		return b.mul(k, b.fn("cos", u))
+	case "cos":
+		return b.neg(b.mul(k, b.fn("sin", u)))
+	case "tan":
+		return b.div(k, b.pow(b.fn("cos", u), b.num(two)))
+	case "asin":
+		return b.div(b.num(one), b.mul(k, b.fn("sqrt", b.sub(b.num(one), square))))
+	case "acos":
+		return b.neg(b.div(b.num(one), b.mul(k, b.fn("sqrt", b.sub(b.num(one), square)))))
+	case "atan":
+		return b.div(b.num(one), b.mul(k, b.add(b.num(one), square)))
+	case "ln":
+		return b.div(b.num(one), u)
+	case "exp":
+		return b.fn("exp", u)
+	case "sinh":
+		return b.fn("cosh", u)
+	case "cosh":
+		return b.fn("sinh", u)
+	case "tanh":
+		return b.div(b.num(one), b.pow(b.fn("cosh", u), b.num(two)))
+	case "coth":
+		return b.neg(b.div(b.num(one), b.pow(b.fn("sinh", u), b.num(two))))
+	case "sech":
+		return b.neg(b.mul(b.fn("sech", u), b.fn("tanh", u)))
+	case "csch":
+		return b.neg(b.mul(b.fn("csch", u), b.fn("coth", u)))
+	case "asinh":
+		return b.div(b.num(one), b.fn("sqrt", b.add(square, b.num(one))))
+	case "acosh":
+		return b.div(b.num(one), b.fn("sqrt", b.sub(square, b.num(one))))
+	case "atanh":
+		return b.div(b.num(one), b.sub(b.num(one), square))
+	}
+	panic("未知的函数: " + name)
+}
+
+// parseDiff 解析 diff(expr, x) 与 diff(expr, x, at)，函数名已被读取
+//
+// 变量名只在第一个参数中有效，at 中不能含有该变量。
+func (p *Parser) parseDiff() Node {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
+		panic(syntaxError("diff后需要括号"))
+	}
+	p.pos++
+
+	// 解析第一个参数前先找到其后的变量名，以便识别参数中的变量
+	comma, depth := -1, 0
+scan:
+	for i := p.pos; i < len(p.tokens); i++ {
+		switch p.tokens[i] {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			if depth--; depth < 0 {
+				break scan
+			}
+		case ",":
+			if depth == 0 {
+				comma = i
+				break scan
+			}
+		}
+	}
+	if comma < 0 || comma+1 >= len(p.tokens) {
+		panic(syntaxError("diff需要求导变量，如 diff(x^2, x)"))
+	}
+	name := p.tokens[comma+1]
+	if !isVariableName(name) {
+		panic(syntaxError("无效的变量名: " + name))
+	}
+
+	outer := p.vars
+	p.vars = map[string]bool{name: true}
+	for v := range outer {
+		p.vars[v] = true
+	}
+	expr := p.parseExpression()
+	p.vars = outer
+	if p.pos != comma {
+		panic(syntaxError("diff的参数需要用逗号分隔"))
+	}
+	p.pos = comma + 2
+
+	d := &DiffOperation{Expr: expr, Var: name, calc: p.calc}
+	if p.pos < len(p.tokens) && p.tokens[p.pos] == "," {
+		p.pos++
+		d.At = p.parseExpression()
+	}
+	if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+		panic(syntaxError("diff缺少右括号"))
+	}
+	p.pos++
+	return d
+}
@@ -0,0 +1,421 @@
+package ast
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/shopspring/decimal"
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+// function 描述可以通过名称调用的函数
+type function struct {
+	minArgs int // 最少参数个数
+	maxArgs int // 最多参数个数，-1 表示不限
+	eval    func(calc *calculator.Calculator, args []Value) (Value, error)
+}
+
+// functions 按名称注册的函数表，解析器遇到这些名称时生成 FunctionCall 节点
+var functions = map[string]function{
+	"abs": {1, 1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		z, err := toComplex(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return realResult(calc.Abs(z))
+	}},
+	"arg": {1, 1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		z, err := toComplex(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return realResult(calc.Arg(z))
+	}},
+	"conj": {1, 1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		z, err := toComplex(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return complexResult(calc.Conj(z))
+	}},
+	"re": {1, 1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		z, err := toComplex(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return Real{z.Re}, nil
+	}},
+	"im": {1, 1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		z, err := toComplex(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return Real{z.Im}, nil
+	}},
+	"polar": {2, 2, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		r, err := toReal(args[0], "polar")
+		if err != nil {
+			return nil, err
+		}
+		theta, err := toReal(args[1], "polar")
+		if err != nil {
+			return nil, err
+		}
+		return complexResult(calc.Polar(r, theta))
+	}},
+	"fact": {1, 1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		n, err := toReal(args[0], "fact")
+		if err != nil {
+			return nil, err
+		}
+		return integerResult(calc.Factorial(n.String()))
+	}},
+	"dfact": {1, 1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		n, err := toReal(args[0], "dfact")
+		if err != nil {
+			return nil, err
+		}
+		return integerResult(calc.DoubleFactorial(n.String()))
+	}},
+	"nCr": {2, 2, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		n, r, err := realPair(args, "nCr")
+		if err != nil {
+			return nil, err
+		}
+		return integerResult(calc.Binomial(n, r))
+	}},
+	"nPr": {2, 2, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		n, r, err := realPair(args, "nPr")
+		if err != nil {
+			return nil, err
+		}
+		return integerResult(calc.Permutations(n, r))
+	}},
+	"mod": {2, 2, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		a, b, err := realPair(args, "mod")
+		if err != nil {
+			return nil, err
+		}
+		return integerResult(calc.Mod(a, b))
+	}},
+	"gcd": {2, -1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		values, err := realArgs(args, "gcd")
+		if err != nil {
+			return nil, err
+		}
+		return integerResult(calc.GCD(values...))
+	}},
+	"lcm": {2, -1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		values, err := realArgs(args, "lcm")
+		if err != nil {
+			return nil, err
+		}
+		return integerResult(calc.LCM(values...))
+	}},
+	"isprime":   {1, 1, integerFunction("isprime", (*calculator.Calculator).IsPrime)},
+	"nextprime": {1, 1, integerFunction("nextprime", (*calculator.Calculator).NextPrime)},
+	"totient":   {1, 1, integerFunction("totient", (*calculator.Calculator).Totient)},
+	"factor": {1, 1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		n, err := toReal(args[0], "factor")
+		if err != nil {
+			return nil, err
+		}
+		return factorsResult(calc.Factorize(n.String()))
+	}},
+	"powmod": {3, 3, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		values, err := realArgs(args, "powmod")
+		if err != nil {
+			return nil, err
+		}
+		return integerResult(calc.PowMod(values[0], values[1], values[2]))
+	}},
+	"modinv": {2, 2, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		a, m, err := realPair(args, "modinv")
+		if err != nil {
+			return nil, err
+		}
+		return integerResult(calc.ModInverse(a, m))
+	}},
+	"sum":        {1, -1, statFunction("sum", (*calculator.Calculator).Sum)},
+	"prod":       {1, -1, statFunction("prod", (*calculator.Calculator).Product)},
+	"min":        {1, -1, statFunction("min", (*calculator.Calculator).Min)},
+	"max":        {1, -1, statFunction("max", (*calculator.Calculator).Max)},
+	"mean":       {1, -1, statFunction("mean", (*calculator.Calculator).Mean)},
+	"median":     {1, -1, statFunction("median", (*calculator.Calculator).Median)},
+	"mode":       {1, -1, statFunction("mode", (*calculator.Calculator).Mode)},
+	"variance":   {1, -1, statFunction("variance", (*calculator.Calculator).Variance)},
+	"variance_s": {2, -1, statFunction("variance_s", (*calculator.Calculator).SampleVariance)},
+	"stddev":     {1, -1, statFunction("stddev", (*calculator.Calculator).StdDev)},
+	"stddev_s":   {2, -1, statFunction("stddev_s", (*calculator.Calculator).SampleStdDev)},
+	"geomean":    {1, -1, statFunction("geomean", (*calculator.Calculator).GeoMean)},
+	"percentile": {2, -1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		values, err := realArgs(args, "percentile")
+		if err != nil {
+			return nil, err
+		}
+		return realResult(calc.Percentile(values[0], values[1:]...))
+	}},
+	"normpdf": {1, 3, normalFunction("normpdf", (*calculator.Calculator).NormPDF)},
+	"normcdf": {1, 3, normalFunction("normcdf", (*calculator.Calculator).NormCDF)},
+	"norminv": {1, 3, normalFunction("norminv", (*calculator.Calculator).NormInv)},
+	"binompdf": {3, 3, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		values, err := realArgs(args, "binompdf")
+		if err != nil {
+			return nil, err
+		}
+		return realResult(calc.BinomPDF(values[0], values[1], values[2]))
+	}},
+	"binomcdf": {3, 3, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		values, err := realArgs(args, "binomcdf")
+		if err != nil {
+			return nil, err
+		}
+		return realResult(calc.BinomCDF(values[0], values[1], values[2]))
+	}},
+	"poissonpdf": {2, 2, realFunction2("poissonpdf", (*calculator.Calculator).PoissonPDF)},
+	"poissoncdf": {2, 2, realFunction2("poissoncdf", (*calculator.Calculator).PoissonCDF)},
+	"tcdf":       {2, 2, realFunction2("tcdf", (*calculator.Calculator).TCDF)},
+	"chi2cdf":    {2, 2, realFunction2("chi2cdf", (*calculator.Calculator).Chi2CDF)},
+	"expcdf":     {2, 2, realFunction2("expcdf", (*calculator.Calculator).ExpCDF)},
+	"npv": {2, -1, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		values, err := realArgs(args, "npv")
+		if err != nil {
+			return nil, err
+		}
+		return realResult(calc.NPV(values[0], values[1:]...))
+	}},
+	"irr":  {2, -1, statFunction("irr", (*calculator.Calculator).IRR)},
+	"pmt":  {3, 5, tvmFunction("pmt", (*calculator.Calculator).PMT)},
+	"fv":   {3, 5, tvmFunction("fv", (*calculator.Calculator).FV)},
+	"pv":   {3, 5, tvmFunction("pv", (*calculator.Calculator).PV)},
+	"nper": {3, 5, tvmFunction("nper", (*calculator.Calculator).NPER)},
+	"rate": {3, 6, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		values, err := realArgs(args, "rate")
+		if err != nil {
+			return nil, err
+		}
+		defaults := []string{"0", "0", "0.1"}
+		params := append(values, defaults[len(values)-3:]...)
+		return realResult(calc.Rate(params[0], params[1], params[2], params[3], params[4], params[5]))
+	}},
+	"deg":     {1, 1, angleConverter("deg", calculator.Degrees)},
+	"rad":     {1, 1, angleConverter("rad", calculator.Radians)},
+	"grad":    {1, 1, angleConverter("grad", calculator.Gradians)},
+	"gamma":   {1, 1, realFunction("gamma", (*calculator.Calculator).Gamma)},
+	"lgamma":  {1, 1, realFunction("lgamma", (*calculator.Calculator).LGamma)},
+	"digamma": {1, 1, realFunction("digamma", (*calculator.Calculator).Digamma)},
+	"beta": {2, 2, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		a, b, err := realPair(args, "beta")
+		if err != nil {
+			return nil, err
+		}
+		return realResult(calc.Beta(a, b))
+	}},
+	"erf":  {1, 1, realFunction("erf", (*calculator.Calculator).Erf)},
+	"erfc": {1, 1, realFunction("erfc", (*calculator.Calculator).Erfc)},
+	"j0":   {1, 1, realFunction("j0", (*calculator.Calculator).BesselJ0)},
+	"j1":   {1, 1, realFunction("j1", (*calculator.Calculator).BesselJ1)},
+	"y0":   {1, 1, realFunction("y0", (*calculator.Calculator).BesselY0)},
+	"y1":   {1, 1, realFunction("y1", (*calculator.Calculator).BesselY1)},
+
+	// 矩阵与向量
+	"det":       {1, 1, matrixRealFunction("det", (*calculator.Calculator).Det)},
+	"inv":       {1, 1, matrixFunction("inv", (*calculator.Calculator).Inv)},
+	"transpose": {1, 1, matrixFunction("transpose", (*calculator.Calculator).MTranspose)},
+	"trace":     {1, 1, matrixRealFunction("trace", (*calculator.Calculator).Trace)},
+	"rank":      {1, 1, rankFunction},
+	"norm":      {1, 1, matrixRealFunction("norm", (*calculator.Calculator).Norm)},
+	"dot": {2, 2, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		a, b, err := matrixPair(args, "dot")
+		if err != nil {
+			return nil, err
+		}
+		return realResult(calc.Dot(a, b))
+	}},
+	"cross": {2, 2, func(calc *calculator.Calculator, args []Value) (Value, error) {
+		a, b, err := matrixPair(args, "cross")
+		if err != nil {
+			return nil, err
+		}
+		return matrixResult(calc.Cross(a, b))
+	}},
+}
+
+// realArgs 要求所有参数均为实数，返回其字符串形式
+func realArgs(args []Value, name string) ([]string, error) {
+	values := make([]string, len(args))
+	for i, arg := range args {
+		d, err := toReal(arg, name)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = d.String()
+	}
+	return values, nil
+}
+
+// realPair 要求两个参数均为实数，返回其字符串形式
+func realPair(args []Value, name string) (string, string, error) {
+	values, err := realArgs(args, name)
+	if err != nil {
+		return "", "", err
+	}
+	return values[0], values[1], nil
+}
+
+// angleConverter 返回把以 unit 为单位的参数换算为计算器角度单位的函数
+func angleConverter(name string, unit calculator.AngleUnit) func(calc *calculator.Calculator, args []Value) (Value, error) {
+	return func(calc *calculator.Calculator, args []Value) (Value, error) {
+		x, err := toReal(args[0], name)
+		if err != nil {
+			return nil, err
+		}
+		return realResult(calc.ConvertAngle(x.String(), unit))
+	}
+}
+
+// realFunction 返回对单个实数参数调用计算器方法 f 的函数
+func realFunction(name string, f func(*calculator.Calculator, string) (decimal.Decimal, error)) func(calc *calculator.Calculator, args []Value) (Value, error) {
+	return func(calc *calculator.Calculator, args []Value) (Value, error) {
+		x, err := toReal(args[0], name)
+		if err != nil {
+			return nil, err
+		}
+		return realResult(f(calc, x.String()))
+	}
+}
+
+// integerFunction 返回对单个实数参数调用计算器方法 f 并得到精确整数的函数
+func integerFunction(name string, f func(*calculator.Calculator, string) (*big.Int, error)) func(calc *calculator.Calculator, args []Value) (Value, error) {
+	return func(calc *calculator.Calculator, args []Value) (Value, error) {
+		n, err := toReal(args[0], name)
+		if err != nil {
+			return nil, err
+		}
+		return integerResult(f(calc, n.String()))
+	}
+}
+
+// realFunction2 返回对两个实数参数调用计算器方法 f 的函数
+func realFunction2(name string, f func(*calculator.Calculator, string, string) (decimal.Decimal, error)) func(calc *calculator.Calculator, args []Value) (Value, error) {
+	return func(calc *calculator.Calculator, args []Value) (Value, error) {
+		a, b, err := realPair(args, name)
+		if err != nil {
+			return nil, err
+		}
+		return realResult(f(calc, a, b))
+	}
+}
+
+// normalFunction 返回正态分布函数 f(x, μ, σ)，省略的 μ、σ 默认为标准正态分布的 0 和 1
+func normalFunction(name string, f func(*calculator.Calculator, string, string, string) (decimal.Decimal, error)) func(calc *calculator.Calculator, args []Value) (Value, error) {
+	return func(calc *calculator.Calculator, args []Value) (Value, error) {
+		values, err := realArgs(args, name)
+		if err != nil {
+			return nil, err
+		}
+		defaults := []string{"0", "1"}
+		params := append(values, defaults[len(values)-1:]...)
+		return realResult(f(calc, params[0], params[1], params[2]))
+	}
+}
+
+// tvmFunction 返回货币时间价值函数 f(a, b, c, fv 或 pv, type)，省略的后两个参数默认为 0
+func tvmFunction(name string, f func(*calculator.Calculator, string, string, string, string, string) (decimal.Decimal, error)) func(calc *calculator.Calculator, args []Value) (Value, error) {
+	return func(calc *calculator.Calculator, args []Value) (Value, error) {
+		values, err := realArgs(args, name)
+		if err != nil {
+			return nil, err
+		}
+		defaults := []string{"0", "0"}
+		params := append(values, defaults[len(values)-3:]...)
+		return realResult(f(calc, params[0], params[1], params[2], params[3], params[4]))
+	}
+}
+
+// statFunction 返回对全部实数参数调用统计方法 f 的函数
+func statFunction(name string, f func(*calculator.Calculator, ...string) (decimal.Decimal, error)) func(calc *calculator.Calculator, args []Value) (Value, error) {
+	return func(calc *calculator.Calculator, args []Value) (Value, error) {
+		values, err := realArgs(args, name)
+		if err != nil {
+			return nil, err
+		}
+		return realResult(f(calc, values...))
+	}
+}
+
+// workingPrecisionFunctions 参数按工作精度求值、只对结果舍入的函数
+//
+// 金融函数的结果对利率非常敏感：精度为 2 时 pmt(0.05/12, 360, 200000) 的利率若先舍入为 0.00，
+// 结果为 -555.56 而不是 -1073.64。
+var workingPrecisionFunctions = map[string]bool{
+	"npv": true, "irr": true, "pmt": true, "fv": true, "pv": true, "nper": true, "rate": true,
+}
+
+// FunctionCall 表示通过名称调用的函数，例如 abs(z)、polar(r, θ)
+type FunctionCall struct {
+	Name string
+	Args []Node
+	calc *calculator.Calculator
+}
+
+func (f *FunctionCall) Evaluate() (Value, error) {
+	fn, ok := functions[f.Name]
+	if !ok {
+		return nil, fmt.Errorf("未知的函数: %s", f.Name)
+	}
+	calc := f.calc
+	if workingPrecisionFunctions[f.Name] {
+		work := *f.calc
+		calculator.WithWorkingPrecision()(&work)
+		calc = &work
+	}
+	args := make([]Value, len(f.Args))
+	for i, arg := range f.Args {
+		if calc != f.calc {
+			arg = transform(arg, calc, nil)
+		}
+		v, err := arg.Evaluate()
+		if err != nil {
+			return nil, err
+		}
+		args[i] = toDecimal(calc, v)
+	}
+	return fn.eval(f.calc, args)
+}
+
+func (f *FunctionCall) Type() NodeType {
+	return FunctionNode
+}
+
+// parseFunctionCall 解析函数调用 name(arg1, arg2, ...)，name 已被读取
+func (p *Parser) parseFunctionCall(name string, fn function) Node {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
+		panic(syntaxError(name + "后需要括号"))
+	}
+	p.pos++
+
+	var args []Node
+	for {
+		args = append(args, p.parseExpression())
+		if p.pos < len(p.tokens) && p.tokens[p.pos] == "," {
+			p.pos++
+			continue
+		}
+		break
+	}
+	if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+		panic(syntaxError(name + "缺少右括号"))
+	}
+	p.pos++
+
+	switch {
+	case fn.minArgs == fn.maxArgs && len(args) != fn.minArgs:
+		panic(syntaxError(fmt.Sprintf("%s函数需要%d个参数", name, fn.minArgs)))
+	case len(args) < fn.minArgs:
+		panic(syntaxError(fmt.Sprintf("%s函数至少需要%d个参数", name, fn.minArgs)))
+	case fn.maxArgs >= 0 && len(args) > fn.maxArgs:
+		panic(syntaxError(fmt.Sprintf("%s函数最多接受%d个参数", name, fn.maxArgs)))
+	}
+	return &FunctionCall{Name: name, Args: args, calc: p.calc}
+}
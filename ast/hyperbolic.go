@@ -0,0 +1,131 @@
+package ast
+
+import (
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+// SinhOperation 表示双曲正弦操作
+type SinhOperation struct {
+	Operand Node
+	calc    *calculator.Calculator
+}
+
+func (h *SinhOperation) Evaluate() (Value, error) {
+	return applyUnary(h.calc, h.Operand, h.calc.Sinh, h.calc.CSinh)
+}
+
+func (h *SinhOperation) Type() NodeType {
+	return SinhNode
+}
+
+// CoshOperation 表示双曲余弦操作
+type CoshOperation struct {
+	Operand Node
+	calc    *calculator.Calculator
+}
+
+func (h *CoshOperation) Evaluate() (Value, error) {
+	return applyUnary(h.calc, h.Operand, h.calc.Cosh, h.calc.CCosh)
+}
+
+func (h *CoshOperation) Type() NodeType {
+	return CoshNode
+}
+
+// TanhOperation 表示双曲正切操作
+type TanhOperation struct {
+	Operand Node
+	calc    *calculator.Calculator
+}
+
+func (h *TanhOperation) Evaluate() (Value, error) {
+	return applyUnary(h.calc, h.Operand, h.calc.Tanh, h.calc.CTanh)
+}
+
+func (h *TanhOperation) Type() NodeType {
+	return TanhNode
+}
+
+// CothOperation 表示双曲余切操作
+type CothOperation struct {
+	Operand Node
+	calc    *calculator.Calculator
+}
+
+func (h *CothOperation) Evaluate() (Value, error) {
+	return applyUnary(h.calc, h.Operand, h.calc.Coth, h.calc.CCoth)
+}
+
+func (h *CothOperation) Type() NodeType {
+	return CothNode
+}
+
+// SechOperation 表示双曲正割操作
+type SechOperation struct {
+	Operand Node
+	calc    *calculator.Calculator
+}
+
+func (h *SechOperation) Evaluate() (Value, error) {
+	return applyUnary(h.calc, h.Operand, h.calc.Sech, h.calc.CSech)
+}
+
+func (h *SechOperation) Type() NodeType {
+	return SechNode
+}
+
+// CschOperation 表示双曲余割操作
+type CschOperation struct {
+	Operand Node
+	calc    *calculator.Calculator
+}
+
+func (h *CschOperation) Evaluate() (Value, error) {
+	return applyUnary(h.calc, h.Operand, h.calc.Csch, h.calc.CCsch)
+}
+
+func (h *CschOperation) Type() NodeType {
+	return CschNode
+}
+
+// AsinhOperation 表示反双曲正弦操作
+type AsinhOperation struct {
+	Operand Node
+	calc    *calculator.Calculator
+}
+
+func (h *AsinhOperation) Evaluate() (Value, error) {
+	return applyUnary(h.calc, h.Operand, h.calc.Asinh, h.calc.CAsinh)
+}
+
+func (h *AsinhOperation) Type() NodeType {
+	return AsinhNode
+}
+
+// AcoshOperation 表示反双曲余弦操作
+type AcoshOperation struct {
+	Operand Node
+	calc    *calculator.Calculator
+}
+
+func (h *AcoshOperation) Evaluate() (Value, error) {
+	return applyUnary(h.calc, h.Operand, h.calc.Acosh, h.calc.CAcosh)
+}
+
+func (h *AcoshOperation) Type() NodeType {
+	return AcoshNode
+}
+
+// AtanhOperation 表示反双曲正切操作
+type AtanhOperation struct {
+	Operand Node
+	calc    *calculator.Calculator
+}
+
+func (h *AtanhOperation) Evaluate() (Value, error) {
+	return applyUnary(h.calc, h.Operand, h.calc.Atanh, h.calc.CAtanh)
+}
+
+func (h *AtanhOperation) Type() NodeType {
+	return AtanhNode
+}
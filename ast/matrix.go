@@ -0,0 +1,197 @@
+package ast
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/shopspring/decimal"
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+// Matrix 表示矩阵或向量结果，输出为 [a, b; c, d]
+type Matrix struct {
+	calculator.Matrix
+}
+
+func (m Matrix) Format(calc *calculator.Calculator) string {
+	return calc.FormatMatrix(m.Matrix)
+}
+
+// matrixResult 将矩阵计算结果包装为 Value
+func matrixResult(m calculator.Matrix, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return Matrix{m}, nil
+}
+
+// toMatrix 要求 v 为矩阵，name 用于错误信息
+func toMatrix(v Value, name string) (calculator.Matrix, error) {
+	if m, ok := v.(Matrix); ok {
+		return m.Matrix, nil
+	}
+	return nil, fmt.Errorf("%w: %s的参数必须为矩阵或向量", calculator.ErrDimensionMismatch, name)
+}
+
+// matrixArithmetic 执行矩阵的四则运算，整数和有理数操作数应已转换为实数
+//
+// 同形矩阵逐元素相加减，.* 逐元素相乘。两个矩阵用 * 相乘时为矩阵乘积，
+// 形状不满足矩阵乘法而彼此同形时逐元素相乘，如 [1, 2, 3] * [1, 2, 3] = [1, 4, 9]。
+// 矩阵与实数相乘或除以实数时逐元素计算。
+func matrixArithmetic(calc *calculator.Calculator, op string, left, right Value) (Value, error) {
+	a, lok := left.(Matrix)
+	b, rok := right.(Matrix)
+	if lok && rok {
+		switch op {
+		case "+":
+			return matrixResult(calc.MAdd(a.Matrix, b.Matrix))
+		case "-":
+			return matrixResult(calc.MSubtract(a.Matrix, b.Matrix))
+		case "*":
+			if a.Cols() != b.Rows() && a.Rows() == b.Rows() && a.Cols() == b.Cols() {
+				return matrixResult(calc.MHadamard(a.Matrix, b.Matrix))
+			}
+			return matrixResult(calc.MMultiply(a.Matrix, b.Matrix))
+		case ".*":
+			return matrixResult(calc.MHadamard(a.Matrix, b.Matrix))
+		case "/":
+			return nil, fmt.Errorf("%w: 矩阵不能作除数，请使用 inv", calculator.ErrDomain)
+		}
+		return nil, fmt.Errorf("未知的运算符: %s", op)
+	}
+	if op == ".*" {
+		op = "*"
+	}
+	if rok && op == "*" {
+		left, right, a = right, left, b
+	}
+	if op != "*" && op != "/" || !lok && !rok {
+		return nil, fmt.Errorf("%w: 矩阵与实数之间只支持 * 和 /", calculator.ErrDimensionMismatch)
+	}
+	if !lok && op == "/" {
+		return nil, fmt.Errorf("%w: 矩阵不能作除数，请使用 inv", calculator.ErrDomain)
+	}
+	k, err := toReal(right, op)
+	if err != nil {
+		return nil, err
+	}
+	if op == "*" {
+		return matrixResult(calc.MScale(a.Matrix, k))
+	}
+	return matrixResult(calc.MDivide(a.Matrix, k))
+}
+
+// matrixPower 计算方阵的整数次幂
+func matrixPower(calc *calculator.Calculator, m Matrix, exponent Value) (Value, error) {
+	e, err := toReal(toDecimal(calc, exponent), "^")
+	if err != nil {
+		return nil, err
+	}
+	return matrixResult(calc.MPower(m.Matrix, e.String()))
+}
+
+// matrixFunction 返回对单个矩阵参数调用计算器方法 f 并得到矩阵的函数
+func matrixFunction(name string, f func(*calculator.Calculator, calculator.Matrix) (calculator.Matrix, error)) func(calc *calculator.Calculator, args []Value) (Value, error) {
+	return func(calc *calculator.Calculator, args []Value) (Value, error) {
+		m, err := toMatrix(args[0], name)
+		if err != nil {
+			return nil, err
+		}
+		return matrixResult(f(calc, m))
+	}
+}
+
+// matrixRealFunction 返回对单个矩阵参数调用计算器方法 f 并得到实数的函数
+func matrixRealFunction(name string, f func(*calculator.Calculator, calculator.Matrix) (decimal.Decimal, error)) func(calc *calculator.Calculator, args []Value) (Value, error) {
+	return func(calc *calculator.Calculator, args []Value) (Value, error) {
+		m, err := toMatrix(args[0], name)
+		if err != nil {
+			return nil, err
+		}
+		return realResult(f(calc, m))
+	}
+}
+
+// matrixPair 要求两个参数均为矩阵
+func matrixPair(args []Value, name string) (calculator.Matrix, calculator.Matrix, error) {
+	a, err := toMatrix(args[0], name)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := toMatrix(args[1], name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, b, nil
+}
+
+// rankFunction 计算矩阵的秩，结果为整数
+func rankFunction(calc *calculator.Calculator, args []Value) (Value, error) {
+	m, err := toMatrix(args[0], "rank")
+	if err != nil {
+		return nil, err
+	}
+	r, err := calc.Rank(m)
+	if err != nil {
+		return nil, err
+	}
+	return Integer{big.NewInt(int64(r))}, nil
+}
+
+// MatrixLiteral 表示矩阵或向量 [a, b, c; d, e, f]，各行以分号分隔
+type MatrixLiteral struct {
+	Rows [][]Node
+	calc *calculator.Calculator
+}
+
+func (m *MatrixLiteral) Evaluate() (Value, error) {
+	rows := make([][]decimal.Decimal, len(m.Rows))
+	for i, row := range m.Rows {
+		rows[i] = make([]decimal.Decimal, len(row))
+		for j, n := range row {
+			d, err := evaluateReal(m.calc, n, "矩阵元素")
+			if err != nil {
+				return nil, err
+			}
+			rows[i][j] = d
+		}
+	}
+	return matrixResult(calculator.NewMatrix(rows))
+}
+
+func (m *MatrixLiteral) Type() NodeType {
+	return MatrixNode
+}
+
+// parseBracket 解析方括号内的内容，左方括号已被读取
+//
+// 行内元素以逗号分隔，各行以分号分隔，如 [1, 2, 3]、[1, 2; 3, 4]、列向量 [1; 2]。
+// 区间写作 interval(lo, hi)，见 parseInterval。
+func (p *Parser) parseBracket() Node {
+	var rows [][]Node
+	row := []Node{p.parseExpression()}
+	for {
+		if p.pos >= len(p.tokens) {
+			panic(syntaxError("缺少右方括号"))
+		}
+		token := p.tokens[p.pos]
+		p.pos++
+		switch token {
+		case ",":
+			row = append(row, p.parseExpression())
+		case ";":
+			rows = append(rows, row)
+			row = []Node{p.parseExpression()}
+		case "]":
+			rows = append(rows, row)
+			for _, r := range rows[1:] {
+				if len(r) != len(rows[0]) {
+					panic(syntaxError("矩阵各行的元素个数必须相同"))
+				}
+			}
+			return &MatrixLiteral{Rows: rows, calc: p.calc}
+		default:
+			panic(syntaxError("矩阵元素需要用逗号分隔，各行用分号分隔"))
+		}
+	}
+}
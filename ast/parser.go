@@ -1,8 +1,14 @@
 package ast
 
 import (
+	"errors"
+	"math/big"
+	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/shopspring/decimal"
 	"github.com/to404hanga/calculator-mcp/calculator"
 )
 
@@ -22,29 +28,91 @@ const (
 	AsinNode
 	AcosNode
 	AtanNode
-	ENode    // 自然对数e常量
-	LogNode  // 对数运算
+	ENode           // 自然对数e常量
+	LogNode         // 对数运算
+	LnNode          // 自然对数运算
+	ExpNode         // 以e为底的指数运算
+	ImaginaryNode   // 虚数常量，如 i、4i
+	FunctionNode    // 按名称调用的函数
+	SinhNode        // 双曲正弦
+	CoshNode        // 双曲余弦
+	TanhNode        // 双曲正切
+	CothNode        // 双曲余切
+	SechNode        // 双曲正割
+	CschNode        // 双曲余割
+	AsinhNode       // 反双曲正弦
+	AcoshNode       // 反双曲余弦
+	AtanhNode       // 反双曲正切
+	AngleNode       // 度分秒角度常量，如 30°15'10"
+	UnitNode        // 单位常量，如 km、m/s^2
+	ConvertNode     // 单位换算，如 5 km in mi
+	CurrencyNode    // 货币代码，如 USD
+	ExchangeNode    // 货币换算，如 100 USD in CNY
+	BaseIntegerNode // 带进制前缀的整数常量，如 0xFF
+	BitwiseNode     // 按位运算，如 0xF0 | 0x0F
+	PlusMinusNode   // 带不确定度的测量值，如 3.00±0.05
+	IntervalNode    // 区间，如 interval(2.9, 3.1)
+	MatrixNode      // 矩阵或向量，如 [1, 2; 3, 4]
+	VariableNode    // 符号表达式中的变量，如 x
+	DiffNode        // 符号求导，如 diff(x^2, x)
+	SimplifyNode    // 符号化简，如 simplify(x + x)
 )
 
 // Node 接口定义了所有 AST 节点必须实现的方法
 type Node interface {
-	Evaluate() string
+	Evaluate() (Value, error)
 	Type() NodeType
 }
 
+// SyntaxError 表示表达式存在语法错误
+type SyntaxError struct {
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Msg
+}
+
+// syntaxError 创建语法错误，解析过程中通过 panic 抛出，由 Parse 统一恢复
+func syntaxError(msg string) *SyntaxError {
+	return &SyntaxError{Msg: msg}
+}
+
 // NumberNode 表示数字常量
 type NumberLiteral struct {
 	Value string
+	calc  *calculator.Calculator
 }
 
-func (n *NumberLiteral) Evaluate() string {
-	return n.Value
+func (n *NumberLiteral) Evaluate() (Value, error) {
+	// 精确模式下数字按有理数保存，后续四则运算不做舍入
+	if n.calc != nil && n.calc.Exact() {
+		return ratResult(calculator.ParseRational(n.Value))
+	}
+	return realResult(calculator.ParseNumber(n.Value))
 }
 
 func (n *NumberLiteral) Type() NodeType {
 	return NumberNode
 }
 
+// ImaginaryLiteral 表示虚数常量，Value 为虚部，如 4i 的虚部为 4
+type ImaginaryLiteral struct {
+	Value string
+}
+
+func (n *ImaginaryLiteral) Evaluate() (Value, error) {
+	im, err := calculator.ParseNumber(n.Value)
+	if err != nil {
+		return nil, err
+	}
+	return complexResult(calculator.NewComplex(decimal.Zero, im), nil)
+}
+
+func (n *ImaginaryLiteral) Type() NodeType {
+	return ImaginaryNode
+}
+
 // BinaryOperator 表示二元运算符节点
 type BinaryOperator struct {
 	Left     Node
@@ -53,34 +121,48 @@ type BinaryOperator struct {
 	calc     *calculator.Calculator
 }
 
-func (b *BinaryOperator) Evaluate() string {
-	left := b.Left.Evaluate()
-	right := b.Right.Evaluate()
-
-	switch b.Operator {
-	case "+":
-		return b.calc.Add(left, right)
-	case "-":
-		return b.calc.Subtract(left, right)
-	case "*":
-		return b.calc.Multiply(left, right)
-	case "/":
-		return b.calc.Divide(left, right)
+func (b *BinaryOperator) Evaluate() (Value, error) {
+	left, err := b.Left.Evaluate()
+	if err != nil {
+		return nil, err
+	}
+	right, err := b.Right.Evaluate()
+	if err != nil {
+		return nil, err
 	}
-	panic("未知的运算符: " + b.Operator)
+	return arithmetic(b.calc, b.Operator, left, right)
 }
 
 func (b *BinaryOperator) Type() NodeType {
 	return BinaryOpNode
 }
 
+// NegOperation 表示前缀负号，如 -3、2^-x
+type NegOperation struct {
+	Operand Node
+	calc    *calculator.Calculator
+}
+
+func (n *NegOperation) Evaluate() (Value, error) {
+	v, err := n.Operand.Evaluate()
+	if err != nil {
+		return nil, err
+	}
+	// 乘以 -1 而不是从 0 中减去，矩阵、区间与带单位的量都按相同的规则取反
+	return arithmetic(n.calc, "*", Integer{big.NewInt(-1)}, v)
+}
+
+func (n *NegOperation) Type() NodeType {
+	return UnaryOpNode
+}
+
 // PIConstant 表示π常量
 type PIConstant struct {
 	calc *calculator.Calculator
 }
 
-func (p *PIConstant) Evaluate() string {
-	return p.calc.PI()
+func (p *PIConstant) Evaluate() (Value, error) {
+	return Real{p.calc.PI()}, nil
 }
 
 func (p *PIConstant) Type() NodeType {
@@ -93,8 +175,18 @@ type SqrtOperation struct {
 	calc    *calculator.Calculator
 }
 
-func (s *SqrtOperation) Evaluate() string {
-	return s.calc.Sqrt(s.Operand.Evaluate())
+func (s *SqrtOperation) Evaluate() (Value, error) {
+	v, err := s.Operand.Evaluate()
+	if err != nil {
+		return nil, err
+	}
+	if q, ok := v.(Quantity); ok {
+		return quantitySqrt(s.calc, q)
+	}
+	if isMeasured(v) {
+		return measuredUnary(s.calc, v, s.calc.ISqrt, s.calc.USqrt)
+	}
+	return unaryValue(s.calc, v, s.calc.Sqrt, s.calc.CSqrt)
 }
 
 func (s *SqrtOperation) Type() NodeType {
@@ -108,8 +200,45 @@ type PowOperation struct {
 	calc     *calculator.Calculator
 }
 
-func (p *PowOperation) Evaluate() string {
-	return p.calc.Power(p.Base.Evaluate(), p.Exponent.Evaluate())
+func (p *PowOperation) Evaluate() (Value, error) {
+	// E^x 直接按 exp(x) 计算，避免先把 e 舍入到当前精度
+	if p.Base.Type() == ENode {
+		return applyUnary(p.calc, p.Exponent, p.calc.Exp, p.calc.CExp)
+	}
+	base, err := p.Base.Evaluate()
+	if err != nil {
+		return nil, err
+	}
+	exponent, err := p.Exponent.Evaluate()
+	if err != nil {
+		return nil, err
+	}
+	if q, ok := base.(Quantity); ok {
+		return quantityPower(p.calc, q, exponent)
+	}
+	if m, ok := base.(Matrix); ok {
+		return matrixPower(p.calc, m, exponent)
+	}
+	if isMeasured(base) || isMeasured(exponent) {
+		return measuredBinary(p.calc, toDecimal(p.calc, base), toDecimal(p.calc, exponent), p.calc.IPower, p.calc.UPower)
+	}
+	// 精确模式下有理数的整数次幂、以及整数的非负整数次幂保持精确，结果过大时退回近似计算
+	b, bok := toRational(base)
+	e, eok := toRational(exponent)
+	if bok && eok && e.IsInt() {
+		_, bint := base.(Integer)
+		_, eint := exponent.(Integer)
+		if p.calc.Exact() || bint && eint && e.Sign() >= 0 {
+			res, err := p.calc.RatPower(b, e)
+			if !errors.Is(err, calculator.ErrOverflow) {
+				if err == nil && !p.calc.Exact() {
+					return Integer{res.Num()}, nil
+				}
+				return ratResult(res, err)
+			}
+		}
+	}
+	return binaryValue(p.calc, base, exponent, p.calc.Power, p.calc.CPower)
 }
 
 func (p *PowOperation) Type() NodeType {
@@ -122,8 +251,8 @@ type SinOperation struct {
 	calc    *calculator.Calculator
 }
 
-func (s *SinOperation) Evaluate() string {
-	return s.calc.Sin(s.Operand.Evaluate())
+func (s *SinOperation) Evaluate() (Value, error) {
+	return applyMeasured(s.calc, s.Operand, s.calc.Sin, s.calc.CSin, s.calc.ISin, s.calc.USin)
 }
 
 func (s *SinOperation) Type() NodeType {
@@ -136,21 +265,48 @@ type CosOperation struct {
 	calc    *calculator.Calculator
 }
 
-func (c *CosOperation) Evaluate() string {
-	return c.calc.Cos(c.Operand.Evaluate())
+func (c *CosOperation) Evaluate() (Value, error) {
+	return applyMeasured(c.calc, c.Operand, c.calc.Cos, c.calc.CCos, c.calc.ICos, c.calc.UCos)
 }
 
 func (c *CosOperation) Type() NodeType {
 	return CosNode
 }
 
-// Parse 解析整个表达式
-func (p *Parser) Parse() Node {
-	return p.parseExpression()
+// Parse 解析整个表达式，表达式不合法时返回 *SyntaxError
+func (p *Parser) Parse() (node Node, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			se, ok := r.(*SyntaxError)
+			if !ok {
+				panic(r)
+			}
+			node, err = nil, se
+		}
+	}()
+
+	node = p.parseExpression()
+	if p.pos < len(p.tokens) {
+		return nil, syntaxError("无法解析的内容: " + strings.Join(p.tokens[p.pos:], " "))
+	}
+	return node, nil
 }
 
 // parseExpression 解析表达式
 func (p *Parser) parseExpression() Node {
+	left := p.parseBitwise(0)
+
+	// 表达式末尾的 in 表示单位或货币换算，如 5 km + 300 m in mi、100 USD in CNY
+	if p.pos < len(p.tokens) && p.tokens[p.pos] == "in" {
+		p.pos++
+		left = p.parseConversion(left)
+	}
+
+	return left
+}
+
+// parseSum 解析加减运算
+func (p *Parser) parseSum() Node {
 	left := p.parseTerm()
 
 	for p.pos < len(p.tokens) {
@@ -167,20 +323,16 @@ func (p *Parser) parseExpression() Node {
 	return left
 }
 
-// parseTerm 解析项
+// parseTerm 解析乘除运算，.* 为矩阵的逐元素乘法
 func (p *Parser) parseTerm() Node {
-	left := p.parseFactor()
+	left := p.parseUnary()
 
 	for p.pos < len(p.tokens) {
-		if p.tokens[p.pos] == "*" || p.tokens[p.pos] == "/" || p.tokens[p.pos] == "^" {
+		if p.tokens[p.pos] == "*" || p.tokens[p.pos] == "/" || p.tokens[p.pos] == ".*" {
 			operator := p.tokens[p.pos]
 			p.pos++
-			right := p.parseFactor()
-			if operator == "^" {
-				left = &PowOperation{Base: left, Exponent: right, calc: p.calc}
-			} else {
-				left = &BinaryOperator{Left: left, Right: right, Operator: operator, calc: p.calc}
-			}
+			right := p.parseUnary()
+			left = &BinaryOperator{Left: left, Right: right, Operator: operator, calc: p.calc}
 		} else {
 			break
 		}
@@ -189,14 +341,67 @@ func (p *Parser) parseTerm() Node {
 	return left
 }
 
+// parseUnary 解析前缀正负号，正负号高于乘除、低于乘方，-2^2 即 -(2^2)，2*-3 即 2*(-3)
+func (p *Parser) parseUnary() Node {
+	if p.pos < len(p.tokens) && (p.tokens[p.pos] == "-" || p.tokens[p.pos] == "+") {
+		if p.pos == len(p.tokens)-1 {
+			panic(syntaxError("无效的表达式"))
+		}
+		operator := p.tokens[p.pos]
+		p.pos++
+		operand := p.parseUnary()
+		if operator == "+" {
+			return operand
+		}
+		return &NegOperation{Operand: operand, calc: p.calc}
+	}
+	return p.parsePower()
+}
+
+// parsePower 解析乘方，乘方高于乘除且右结合，2^3^2 即 2^(3^2)，指数可以带负号，如 2^-1
+func (p *Parser) parsePower() Node {
+	base := p.parsePostfix()
+	if p.pos < len(p.tokens) && p.tokens[p.pos] == "^" {
+		p.pos++
+		return &PowOperation{Base: base, Exponent: p.parseUnary(), calc: p.calc}
+	}
+	return base
+}
+
+// parsePostfix 解析因子及其后的后缀阶乘运算符、不确定度、单位与货币，如 5!、7!!、(2+3)!、3.00±0.05、9.81 m/s^2、100 USD
+//
+// 相连的 !! 是双阶乘，(5!)! 需要写括号或在两个 ! 之间加空格，如 5! !。
+func (p *Parser) parsePostfix() Node {
+	node := p.parseFactor()
+	for p.pos < len(p.tokens) && (p.tokens[p.pos] == "!" || p.tokens[p.pos] == "!!") {
+		name := "fact"
+		if p.tokens[p.pos] == "!!" {
+			name = "dfact"
+		}
+		p.pos++
+		node = &FunctionCall{Name: name, Args: []Node{node}, calc: p.calc}
+	}
+	if p.pos < len(p.tokens) && p.tokens[p.pos] == "±" {
+		p.pos++
+		node = p.parsePlusMinus(node)
+	}
+	// 紧跟的单位先与前面的数结合，10 m / 2 s 的结果为 5 m/s
+	if p.isCurrencyToken(p.pos) {
+		node = &BinaryOperator{Left: node, Right: p.parseCurrency(), Operator: "*", calc: p.calc}
+	} else if p.isUnitToken(p.pos) {
+		node = &BinaryOperator{Left: node, Right: &UnitLiteral{Unit: p.parseUnit()}, Operator: "*", calc: p.calc}
+	}
+	return node
+}
+
 // TanOperation 表示正切操作
 type TanOperation struct {
 	Operand Node
 	calc    *calculator.Calculator
 }
 
-func (t *TanOperation) Evaluate() string {
-	return t.calc.Tan(t.Operand.Evaluate())
+func (t *TanOperation) Evaluate() (Value, error) {
+	return applyMeasured(t.calc, t.Operand, t.calc.Tan, t.calc.CTan, t.calc.ITan, t.calc.UTan)
 }
 
 func (t *TanOperation) Type() NodeType {
@@ -209,8 +414,8 @@ type AsinOperation struct {
 	calc    *calculator.Calculator
 }
 
-func (a *AsinOperation) Evaluate() string {
-	return a.calc.Asin(a.Operand.Evaluate())
+func (a *AsinOperation) Evaluate() (Value, error) {
+	return applyMeasured(a.calc, a.Operand, a.calc.Asin, a.calc.CAsin, a.calc.IAsin, a.calc.UAsin)
 }
 
 func (a *AsinOperation) Type() NodeType {
@@ -223,8 +428,8 @@ type AcosOperation struct {
 	calc    *calculator.Calculator
 }
 
-func (a *AcosOperation) Evaluate() string {
-	return a.calc.Acos(a.Operand.Evaluate())
+func (a *AcosOperation) Evaluate() (Value, error) {
+	return applyMeasured(a.calc, a.Operand, a.calc.Acos, a.calc.CAcos, a.calc.IAcos, a.calc.UAcos)
 }
 
 func (a *AcosOperation) Type() NodeType {
@@ -237,8 +442,8 @@ type AtanOperation struct {
 	calc    *calculator.Calculator
 }
 
-func (a *AtanOperation) Evaluate() string {
-	return a.calc.Atan(a.Operand.Evaluate())
+func (a *AtanOperation) Evaluate() (Value, error) {
+	return applyMeasured(a.calc, a.Operand, a.calc.Atan, a.calc.CAtan, a.calc.IAtan, a.calc.UAtan)
 }
 
 func (a *AtanOperation) Type() NodeType {
@@ -250,8 +455,8 @@ type EConstant struct {
 	calc *calculator.Calculator
 }
 
-func (e *EConstant) Evaluate() string {
-	return e.calc.E()
+func (e *EConstant) Evaluate() (Value, error) {
+	return Real{e.calc.E()}, nil
 }
 
 func (e *EConstant) Type() NodeType {
@@ -265,18 +470,46 @@ type LogOperation struct {
 	calc  *calculator.Calculator
 }
 
-func (l *LogOperation) Evaluate() string {
-	return l.calc.Log(l.Value.Evaluate(), l.Base.Evaluate())
+func (l *LogOperation) Evaluate() (Value, error) {
+	return applyBinary(l.calc, l.Value, l.Base, l.calc.Log, l.calc.CLog)
 }
 
 func (l *LogOperation) Type() NodeType {
 	return LogNode
 }
 
+// LnOperation 表示自然对数操作
+type LnOperation struct {
+	Operand Node
+	calc    *calculator.Calculator
+}
+
+func (l *LnOperation) Evaluate() (Value, error) {
+	return applyMeasured(l.calc, l.Operand, l.calc.Ln, l.calc.CLn, l.calc.ILn, l.calc.ULn)
+}
+
+func (l *LnOperation) Type() NodeType {
+	return LnNode
+}
+
+// ExpOperation 表示以e为底的指数操作
+type ExpOperation struct {
+	Operand Node
+	calc    *calculator.Calculator
+}
+
+func (e *ExpOperation) Evaluate() (Value, error) {
+	return applyMeasured(e.calc, e.Operand, e.calc.Exp, e.calc.CExp, e.calc.IExp, e.calc.UExp)
+}
+
+func (e *ExpOperation) Type() NodeType {
+	return ExpNode
+}
+
 // parseFactor 解析因子
 func (p *Parser) parseFactor() Node {
 	if p.pos >= len(p.tokens) {
-		panic("表达式不完整")
+		panic(syntaxError("表达式不完整"))
 	}
 
 	token := p.tokens[p.pos]
@@ -284,11 +517,11 @@ func (p *Parser) parseFactor() Node {
 	// 检查单个运算符和前缀运算符的情况
 	if token == "+" || token == "-" || token == "*" || token == "/" || token == "^" {
 		if p.pos == 0 || p.pos == len(p.tokens)-1 {
-			panic("无效的表达式")
+			panic(syntaxError("无效的表达式"))
 		}
 		// 如果运算符在开头，也是无效的表达式
 		if p.pos == 0 {
-			panic("无效的表达式")
+			panic(syntaxError("无效的表达式"))
 		}
 	}
 
@@ -298,11 +531,14 @@ func (p *Parser) parseFactor() Node {
 	case token == "(":
 		node := p.parseExpression()
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
-			panic("缺少右括号")
+			panic(syntaxError("缺少右括号"))
 		}
 		p.pos++
 		return node
 
+	case token == "[":
+		return p.parseBracket()
+
 	case token == "PI":
 		return &PIConstant{calc: p.calc}
 
@@ -311,143 +547,232 @@ func (p *Parser) parseFactor() Node {
 
 	case token == "sqrt":
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
-			panic("sqrt后需要括号")
+			panic(syntaxError("sqrt后需要括号"))
 		}
 		p.pos++
 		operand := p.parseExpression()
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
-			panic("sqrt缺少右括号")
+			panic(syntaxError("sqrt缺少右括号"))
 		}
 		p.pos++
 		return &SqrtOperation{Operand: operand, calc: p.calc}
 
 	case token == "sin":
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
-			panic("sin后需要括号")
+			panic(syntaxError("sin后需要括号"))
 		}
 		p.pos++
 		operand := p.parseExpression()
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
-			panic("sin缺少右括号")
+			panic(syntaxError("sin缺少右括号"))
 		}
 		p.pos++
 		return &SinOperation{Operand: operand, calc: p.calc}
 
 	case token == "cos":
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
-			panic("cos后需要括号")
+			panic(syntaxError("cos后需要括号"))
 		}
 		p.pos++
 		operand := p.parseExpression()
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
-			panic("cos缺少右括号")
+			panic(syntaxError("cos缺少右括号"))
 		}
 		p.pos++
 		return &CosOperation{Operand: operand, calc: p.calc}
 
 	case token == "tan":
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
-			panic("tan后需要括号")
+			panic(syntaxError("tan后需要括号"))
 		}
 		p.pos++
 		operand := p.parseExpression()
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
-			panic("tan缺少右括号")
+			panic(syntaxError("tan缺少右括号"))
 		}
 		p.pos++
 		return &TanOperation{Operand: operand, calc: p.calc}
 
 	case token == "asin":
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
-			panic("asin后需要括号")
+			panic(syntaxError("asin后需要括号"))
 		}
 		p.pos++
 		operand := p.parseExpression()
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
-			panic("asin缺少右括号")
+			panic(syntaxError("asin缺少右括号"))
 		}
 		p.pos++
 		return &AsinOperation{Operand: operand, calc: p.calc}
 
 	case token == "acos":
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
-			panic("acos后需要括号")
+			panic(syntaxError("acos后需要括号"))
 		}
 		p.pos++
 		operand := p.parseExpression()
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
-			panic("acos缺少右括号")
+			panic(syntaxError("acos缺少右括号"))
 		}
 		p.pos++
 		return &AcosOperation{Operand: operand, calc: p.calc}
 
 	case token == "atan":
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
-			panic("atan后需要括号")
+			panic(syntaxError("atan后需要括号"))
 		}
 		p.pos++
 		operand := p.parseExpression()
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
-			panic("atan缺少右括号")
+			panic(syntaxError("atan缺少右括号"))
 		}
 		p.pos++
 		return &AtanOperation{Operand: operand, calc: p.calc}
 
 	case token == "log":
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
-			panic("log后需要括号")
+			panic(syntaxError("log后需要括号"))
 		}
 		p.pos++
 		value := p.parseExpression()
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != "," {
-			panic("log函数需要两个参数，用逗号分隔")
+			panic(syntaxError("log函数需要两个参数，用逗号分隔"))
 		}
 		p.pos++
 		base := p.parseExpression()
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
-			panic("log缺少右括号")
+			panic(syntaxError("log缺少右括号"))
 		}
 		p.pos++
 		return &LogOperation{Value: value, Base: base, calc: p.calc}
 
 	case token == "lg":
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
-			panic("lg后需要括号")
+			panic(syntaxError("lg后需要括号"))
 		}
 		p.pos++
 		operand := p.parseExpression()
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
-			panic("lg缺少右括号")
+			panic(syntaxError("lg缺少右括号"))
 		}
 		p.pos++
 		// 使用 LogOperation，将 10 作为底数
 		return &LogOperation{
 			Value: operand,
-			Base:  &NumberLiteral{Value: "10"},
+			Base:  &NumberLiteral{Value: "10", calc: p.calc},
 			calc:  p.calc,
 		}
 
 	case token == "ln":
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
-			panic("ln后需要括号")
+			panic(syntaxError("ln后需要括号"))
 		}
 		p.pos++
 		operand := p.parseExpression()
 		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
-			panic("ln缺少右括号")
+			panic(syntaxError("ln缺少右括号"))
 		}
 		p.pos++
-		// 使用 LogOperation，将 E 作为底数
-		return &LogOperation{
-			Value: operand,
-			Base:  &EConstant{calc: p.calc},
-			calc:  p.calc,
+		return &LnOperation{Operand: operand, calc: p.calc}
+
+	case token == "exp":
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
+			panic(syntaxError("exp后需要括号"))
 		}
+		p.pos++
+		operand := p.parseExpression()
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			panic(syntaxError("exp缺少右括号"))
+		}
+		p.pos++
+		return &ExpOperation{Operand: operand, calc: p.calc}
+
+	case token == "sinh":
+		return &SinhOperation{Operand: p.parseOperand(token), calc: p.calc}
+
+	case token == "cosh":
+		return &CoshOperation{Operand: p.parseOperand(token), calc: p.calc}
+
+	case token == "tanh":
+		return &TanhOperation{Operand: p.parseOperand(token), calc: p.calc}
+
+	case token == "coth":
+		return &CothOperation{Operand: p.parseOperand(token), calc: p.calc}
+
+	case token == "sech":
+		return &SechOperation{Operand: p.parseOperand(token), calc: p.calc}
+
+	case token == "csch":
+		return &CschOperation{Operand: p.parseOperand(token), calc: p.calc}
+
+	case token == "asinh":
+		return &AsinhOperation{Operand: p.parseOperand(token), calc: p.calc}
+
+	case token == "acosh":
+		return &AcoshOperation{Operand: p.parseOperand(token), calc: p.calc}
+
+	case token == "atanh":
+		return &AtanhOperation{Operand: p.parseOperand(token), calc: p.calc}
+
+	case token == "~":
+		return &BitwiseOperation{Left: p.parsePostfix(), Operator: "~", calc: p.calc}
+
+	case token == "i":
+		return &ImaginaryLiteral{Value: "1"}
+
+	case token == "diff":
+		return p.parseDiff()
+
+	case token == "simplify":
+		return p.parseSimplify()
+
+	case token == "interval":
+		return p.parseInterval()
 
 	default:
+		if p.vars[token] || p.free && isVariableName(token) {
+			return &Variable{Name: token}
+		}
+		if p.isCurrencyToken(p.pos - 1) {
+			p.pos--
+			return p.parseCurrency()
+		}
+		if p.isUnitToken(p.pos - 1) {
+			p.pos--
+			return &UnitLiteral{Unit: p.parseUnit()}
+		}
+		if fn, ok := functions[token]; ok {
+			return p.parseFunctionCall(token, fn)
+		}
+		if calculator.HasBasePrefix(token) {
+			return p.parseBaseInteger(token)
+		}
+		if strings.Contains(token, "°") {
+			return p.parseAngle(token)
+		}
+		// 以 i 结尾的数字表示虚数，如 4i
+		if im, ok := strings.CutSuffix(token, "i"); ok {
+			if _, err := decimal.NewFromString(im); err == nil {
+				return &ImaginaryLiteral{Value: im}
+			}
+		}
 		// 直接将数字作为字符串存储
-		return &NumberLiteral{Value: token}
+		return &NumberLiteral{Value: token, calc: p.calc}
+	}
+}
+
+// parseOperand 解析单参数函数括号内的操作数，函数名 name 已被读取
+func (p *Parser) parseOperand(name string) Node {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
+		panic(syntaxError(name + "后需要括号"))
+	}
+	p.pos++
+	operand := p.parseExpression()
+	if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+		panic(syntaxError(name + "缺少右括号"))
 	}
+	p.pos++
+	return operand
 }
 
 // Parser 结构体用于解析表达式
@@ -455,23 +780,73 @@ type Parser struct {
 	tokens []string
 	pos    int
 	calc   *calculator.Calculator
+	vars   map[string]bool // 当前 diff 中绑定的变量
+	free   bool            // 是否将未识别的标识符视为变量，用于 simplify
 }
 
 // NewParser 创建新的解析器
 func NewParser(expression string, calc *calculator.Calculator) *Parser {
-	// 将表达式转换为标记序列
-	expression = strings.ReplaceAll(expression, "(", " ( ")
-	expression = strings.ReplaceAll(expression, ")", " ) ")
-	expression = strings.ReplaceAll(expression, ",", " , ") // 添加对逗号的处理
-	expression = strings.ReplaceAll(expression, "+", " + ")
-	expression = strings.ReplaceAll(expression, "-", " - ")
-	expression = strings.ReplaceAll(expression, "*", " * ")
-	expression = strings.ReplaceAll(expression, "/", " / ")
-	expression = strings.ReplaceAll(expression, "^", " ^ ")
-	tokens := strings.Fields(expression)
 	return &Parser{
-		tokens: tokens,
+		tokens: tokenize(expression),
 		pos:    0,
 		calc:   calc,
 	}
 }
+
+// operatorTokens 单独成为标记的运算符与分隔符，较长的运算符排在前面
+var operatorTokens = []string{"<<", ">>", ".*", "!!", "(", ")", ",", "+", "-", "*", "/", "^", "!", "&", "|", "~", "±", "[", "]", ";"}
+
+// exponentPrefix 匹配科学计数法中指数符号之前的部分，如 1e、2.5E、.5e
+var exponentPrefix = regexp.MustCompile(`^([0-9]+\.?[0-9]*|\.[0-9]+)[eE]$`)
+
+// tokenize 将表达式转换为标记序列
+//
+// 运算符和分隔符各自成为一个标记，其余字符以空白和运算符为界组成数字、名称等标记。
+// 数字常量中指数部分的符号属于该数字，如 1e-30、2.5E+3。
+func tokenize(expression string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for i := 0; i < len(expression); {
+		rest := expression[i:]
+		r, size := utf8.DecodeRuneInString(rest)
+		if unicode.IsSpace(r) {
+			flush()
+			i += size
+			continue
+		}
+		if (r == '+' || r == '-') && len(rest) > 1 && isDigit(rest[1]) && exponentPrefix.MatchString(current.String()) {
+			current.WriteRune(r)
+			i += size
+			continue
+		}
+		op := ""
+		for _, t := range operatorTokens {
+			if strings.HasPrefix(rest, t) {
+				op = t
+				break
+			}
+		}
+		if op == "" {
+			current.WriteRune(r)
+			i += size
+			continue
+		}
+		flush()
+		tokens = append(tokens, op)
+		i += len(op)
+	}
+	flush()
+	return tokens
+}
+
+// isDigit 判断字节 b 是否为十进制数字
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
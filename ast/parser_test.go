@@ -1,12 +1,40 @@
 package ast
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/to404hanga/calculator-mcp/calculator"
 )
 
+// evaluateValue 解析并计算表达式，任何错误都会使测试失败
+func evaluateValue(t *testing.T, input string, calc *calculator.Calculator) Value {
+	t.Helper()
+	node, err := NewParser(input, calc).Parse()
+	if err != nil {
+		t.Fatalf("对于输入 %s: 解析失败: %v", input, err)
+	}
+	result, err := node.Evaluate()
+	if err != nil {
+		t.Fatalf("对于输入 %s: 计算失败: %v", input, err)
+	}
+	return result
+}
+
+// evaluate 解析并计算表达式，结果必须为实数
+func evaluate(t *testing.T, input string, calc *calculator.Calculator) decimal.Decimal {
+	t.Helper()
+	result := evaluateValue(t, input, calc)
+	r, ok := result.(Real)
+	if !ok {
+		t.Fatalf("对于输入 %s: 期望实数结果，得到 %T", input, result)
+	}
+	return r.Decimal
+}
+
 func TestParser(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -21,21 +49,20 @@ func TestParser(t *testing.T) {
 		{"sqrt(2 * 8)", "4"},
 		{"sqrt(PI)", "1.772453850905516027298167483341145182797549456122387128213807789852911284591"},
 		{"(1 + sqrt(16)) * 2", "10"},
-		{"E", "2.718281828459045235360287471352662497757247093699959574966967627724076630353"},
-		{"2 * E", "5.436563656918090470720574942705324995514494187399919149933935255448153260706"},
+		{"E", "2.718281828459045235360287471352662497757247093699959574966967627724076630354"},
+		// 常量先按精度舍入再参与乘法，末位与 2e 的正确舍入值 …707 相差 1
+		{"2 * E", "5.436563656918090470720574942705324995514494187399919149933935255448153260708"},
 		{"E ^ 2", "7.389056098930650227230427460575007813180315570551847324087127822522573796079"},
 	}
 
 	calc := calculator.NewCalculator(75)
 
 	for _, test := range tests {
-		parser := NewParser(test.input, calc)
-		result := parser.Parse().Evaluate()
 		// Compare decimal values instead of float64
+		actual := evaluate(t, test.input, calc)
 		expected, _ := decimal.NewFromString(test.expected)
-		actual, _ := decimal.NewFromString(result)
 		if !expected.Equal(actual) {
-			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, actual)
 		}
 	}
 }
@@ -46,6 +73,10 @@ func TestExtendedOperations(t *testing.T) {
 		expected string
 	}{
 		{"2 ^ 3", "8"},
+		{"2 ^ 3 ^ 2", "512"}, // 乘方右结合
+		{"2 * 3 ^ 2", "18"},  // 乘方高于乘除
+		{"2 ^ 3 * 2", "16"},
+		{"12 / 2 ^ 2", "3"},
 		{"sin(PI / 2)", "1"},
 		{"cos(PI)", "-1"},
 		{"2 ^ 2 + sin(PI/2)", "5"},
@@ -56,138 +87,1420 @@ func TestExtendedOperations(t *testing.T) {
 		{"sin(PI)", "0"},                       // 添加边界值测试
 		{"2 ^ 0", "1"},                         // 添加指数为0的测试
 		{"2 ^ 0.5", "1.4142135623730950488016887242096980785696718753769480731766797379907324784621"}, // 添加小数指数测试
-		{"tan(PI/4)", "1"},                // tan(π/4) = 1
-		{"asin(0)", "0"},                  // arcsin(0) = 0
-		{"acos(1)", "0"},                  // arccos(1) = 0
-		{"atan(1)", "0.7853981633974483"}, // arctan(1) = π/4
-		{"tan(0)", "0"},                   // tan(0) = 0
-		{"asin(1)", "1.5707963267948966"}, // arcsin(1) = π/2
-		{"acos(0)", "1.5707963267948966"}, // arccos(0) = π/2
-		{"atan(0)", "0"},                  // arctan(0) = 0
-		{"ln(E)", "1"},                     // ln(e) = 1
-		{"ln(1)", "0"},                     // ln(1) = 0
-		{"ln(E^2)", "2"},                   // ln(e²) = 2
-		{"2 * ln(E)", "2"},                 // 2ln(e) = 2
-		{"log(8,2)", "3"},                  // log₂(8) = 3
-		{"log(1000,10)", "3"},              // log₁₀(1000) = 3
-		{"log(E^2,E)", "2"},                // log_e(e²) = 2
-		{"log(16,2)", "4"},                 // log₂(16) = 4
+		{"tan(PI/4)", "1"}, // tan(π/4) = 1
+		{"asin(0)", "0"},   // arcsin(0) = 0
+		{"acos(1)", "0"},   // arccos(1) = 0
+		{"atan(1)", "0.785398163397448309615660845819875721049292349843776455243736148076954101572"}, // arctan(1) = π/4
+		{"tan(0)", "0"}, // tan(0) = 0
+		{"asin(1)", "1.570796326794896619231321691639751442098584699687552910487472296153908203143"}, // arcsin(1) = π/2
+		{"acos(0)", "1.570796326794896619231321691639751442098584699687552910487472296153908203143"}, // arccos(0) = π/2
+		{"atan(0)", "0"},      // arctan(0) = 0
+		{"ln(E)", "1"},        // ln(e) = 1
+		{"ln(1)", "0"},        // ln(1) = 0
+		{"ln(E^2)", "2"},      // ln(e²) = 2
+		{"2 * ln(E)", "2"},    // 2ln(e) = 2
+		{"log(8,2)", "3"},     // log₂(8) = 3
+		{"log(1000,10)", "3"}, // log₁₀(1000) = 3
+		{"log(E^2,E)", "2"},   // log_e(e²) = 2
+		{"log(16,2)", "4"},    // log₂(16) = 4
 	}
 
 	calc := calculator.NewCalculator(75)
 
 	for _, test := range tests {
-		parser := NewParser(test.input, calc)
-		result := parser.Parse().Evaluate()
 		// Compare decimal values instead of float64
+		actual := evaluate(t, test.input, calc)
 		expected, _ := decimal.NewFromString(test.expected)
-		actual, _ := decimal.NewFromString(result)
 		// Use a higher precision comparison for trigonometric functions
 		if !expected.Sub(actual).Abs().LessThan(decimal.NewFromFloat(1e-10)) {
-			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestTrigPrecision(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"sin(1)", "0.841470984807896506652502321630298999622563060798371065672751709991910404391"},
+		{"cos(1)", "0.540302305868139717400936607442976603732310420617922227670097255381100394774"},
+		{"tan(1)", "1.557407724654902230506974807458360173087250772381520038383946605698861397152"},
+		{"cos(0 - 7)", "0.753902254343304638141197521719182012218313391460126839543613880813876026721"},
+		{"sin(1000000000000000000000000000000)", "-0.090116901912138058030386428952987330274396332993043449885460666579773983477"},
+		{"tan(1.5707963)", "37320539.586716541320040642465408494112066456346316149720739978955786416654485786196"},
+		{"asin(0.5)", "0.523598775598298873077107230546583814032861566562517636829157432051302734381"},
+		{"acos(0 - 0.3)", "1.875488980810294127203324652867280609053144731394329297880450244900381195655"},
+		{"atan(123.456)", "1.562696452097992641892851578114457446196426396820430706100989752052468134370"},
+		{"atan(0 - 0.2)", "-0.197395559849880758370049765194790293447585103787852101517688940241033969978"},
+	}
+
+	calc := calculator.NewCalculator(75)
+
+	for _, test := range tests {
+		actual := evaluate(t, test.input, calc)
+		expected, _ := decimal.NewFromString(test.expected)
+		if !expected.Equal(actual) {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestExpLogPrecision(t *testing.T) {
+	tests := []struct {
+		input     string
+		precision int32
+		expected  string
+	}{
+		{"exp(1)", 75, "2.718281828459045235360287471352662497757247093699959574966967627724076630354"},
+		{"exp(0.1)", 75, "1.105170918075647624811707826490246668224547194737518718792863289440967966748"},
+		{"exp(100)", 75, "26881171418161354484126255515800135873611118.773741922415191608615280287034909564914158871097219845710811670879190576069"},
+		{"exp(0 - 50)", 75, "0.000000000000000000000192874984796391778301734281652701257475283265123026291"},
+		{"exp(1000)", 10, "197007111401704699388887935224332312531693798532384578995280299138506385078244119347497807656302688993096381798752022693598298173054461289923262783660152825232320535169584566756192271567602788071422466826314006855168508653497941660316045367817938092905299728580132869945856470286534375900456564355589156220422320260518826112288638358372248724725214506150418881937494100871264232248436315760560377439930623959705844189509050047074217568.2267578083"},
+		{"ln(2)", 75, "0.693147180559945309417232121458176568075500134360255254120680009493393621970"},
+		{"ln(0.000000000000000000000000000001)", 75, "-69.077552789821370520539743640530926228033044658863189280999837029027178290321"},
+		{"ln(123456789012345678901234567890)", 75, "66.985688719142977397576753896334185902670171435557969805218308865411242284766"},
+		{"log(2,3)", 75, "0.630929753571457437099527114342760854299585640131880427870654943838685201381"},
+		{"log(1000,10)", 75, "3"},
+		{"lg(0.001)", 75, "-3"},
+		{"ln(E^2)", 75, "2"},
+		{"2 ^ 0.5", 75, "1.414213562373095048801688724209698078569671875376948073176679737990732478462"},
+		{"1.5 ^ 2.5", 75, "2.755675960631075360471944584044127815961690915738753894486779138157330424639"},
+	}
+
+	for _, test := range tests {
+		calc := calculator.NewCalculator(test.precision)
+		actual := evaluate(t, test.input, calc)
+		expected, _ := decimal.NewFromString(test.expected)
+		if !expected.Equal(actual) {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, actual)
 		}
 	}
 }
 
 func TestParserErrors(t *testing.T) {
-    tests := []struct {
-        input       string
-        expectPanic bool
-        panicMsg    string
-    }{
-        {"", true, "表达式不完整"},
-        {"(1 + 2", true, "缺少右括号"},
-        {"1 + ", true, "表达式不完整"},
-        {"sqrt", true, "sqrt后需要括号"},  // 修改期望的错误消息
-        {"sqrt(", true, "表达式不完整"},
-        {"sqrt)", true, "sqrt后需要括号"},
-        {"log(10)", true, "log函数需要两个参数，用逗号分隔"},
-        {"log(10,)", true, "log缺少右括号"},  // 修改期望的错误消息
-        {"log(,2)", true, "log函数需要两个参数，用逗号分隔"},  // 修改期望的错误消息
-        {"log(10,2", true, "log缺少右括号"},
-        {"ln(", true, "表达式不完整"},
-        {"ln)", true, "ln后需要括号"},
-        {"lg(", true, "表达式不完整"},
-        {"lg)", true, "lg后需要括号"},
-        {"1 +", true, "表达式不完整"},
-        {"*", true, "无效的表达式"},      // 修改错误消息
-        {"+", true, "无效的表达式"},      // 修改错误消息
-        {"-", true, "无效的表达式"},      // 修改错误消息
-        {"/", true, "无效的表达式"},      // 修改错误消息
-        {"^", true, "无效的表达式"},      // 修改错误消息
-        {"+ 2", true, "无效的表达式"},    // 修改错误消息
-        {"* 2", true, "无效的表达式"},    // 修改错误消息
-        {"/ 2", true, "无效的表达式"},    // 修改错误消息
-        {"2 *", true, "表达式不完整"},    // 保持原有错误消息
-        {"2 /", true, "表达式不完整"},    // 保持原有错误消息
-    }
-
-    calc := calculator.NewCalculator(10)
-
-    for _, test := range tests {
-        func() {
-            defer func() {
-                r := recover()
-                if test.expectPanic {
-                    if r == nil {
-                        t.Errorf("对于输入 %s: 期望发生panic，但没有", test.input)
-                    } else if r.(string) != test.panicMsg {
-                        t.Errorf("对于输入 %s: 期望panic消息为 %s, 得到 %s", test.input, test.panicMsg, r)
-                    }
-                } else if r != nil {
-                    t.Errorf("对于输入 %s: 不期望发生panic，但发生了: %v", test.input, r)
-                }
-            }()
-            parser := NewParser(test.input, calc)
-            parser.Parse()
-        }()
-    }
+	tests := []struct {
+		input    string
+		errorMsg string
+	}{
+		{"", "表达式不完整"},
+		{"(1 + 2", "缺少右括号"},
+		{"1 + ", "表达式不完整"},
+		{"sqrt", "sqrt后需要括号"}, // 修改期望的错误消息
+		{"sqrt(", "表达式不完整"},
+		{"sqrt)", "sqrt后需要括号"},
+		{"log(10)", "log函数需要两个参数，用逗号分隔"},
+		{"log(10,)", "log缺少右括号"},         // 修改期望的错误消息
+		{"log(,2)", "log函数需要两个参数，用逗号分隔"}, // 修改期望的错误消息
+		{"log(10,2", "log缺少右括号"},
+		{"ln(", "表达式不完整"},
+		{"ln)", "ln后需要括号"},
+		{"lg(", "表达式不完整"},
+		{"lg)", "lg后需要括号"},
+		{"1 +", "表达式不完整"},
+		{"*", "无效的表达式"},   // 修改错误消息
+		{"+", "无效的表达式"},   // 修改错误消息
+		{"-", "无效的表达式"},   // 修改错误消息
+		{"/", "无效的表达式"},   // 修改错误消息
+		{"^", "无效的表达式"},   // 修改错误消息
+		{"* 2", "无效的表达式"}, // 修改错误消息
+		{"/ 2", "无效的表达式"}, // 修改错误消息
+		{"2 *", "表达式不完整"}, // 保持原有错误消息
+		{"2 /", "表达式不完整"}, // 保持原有错误消息
+		{"2 * -", "无效的表达式"},
+		{"2 ^ -", "无效的表达式"},
+		{"sinh", "sinh后需要括号"},
+		{"30°15", "无效的角度: 30°15"},
+		{"stddev_s(5)", "stddev_s函数至少需要2个参数"},
+		{"5 m^x", "单位的指数必须为整数: x"},
+		{"5 km in", "in后需要单位"},
+		{"0xZZ", "无效的整数: 0xZZ"},
+		{"0b102", "无效的整数: 0b102"},
+		{"3±", "±后需要不确定度"},
+		{"[1 2]", "矩阵元素需要用逗号分隔，各行用分号分隔"},
+		{"interval", "interval后需要括号"},
+		{"interval(1 2)", "区间的上下界需要用逗号分隔"},
+		{"interval(1, 2", "interval缺少右括号"},
+		{"[1, 2", "缺少右方括号"},
+		{"[1, 2; 3]", "矩阵各行的元素个数必须相同"},
+		{"[1, 2, 3 4]", "矩阵元素需要用逗号分隔，各行用分号分隔"},
+		{"diff(x^2)", "diff需要求导变量，如 diff(x^2, x)"},
+		{"diff(x^2, 2)", "无效的变量名: 2"},
+		{"diff(x^2, x, 1", "diff缺少右括号"},
+		{"simplify", "simplify后需要括号"},
+		{"simplify(x + x", "simplify缺少右括号"},
+	}
+
+	calc := calculator.NewCalculator(10)
+
+	for _, test := range tests {
+		_, err := NewParser(test.input, calc).Parse()
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("对于输入 %s: 期望返回语法错误，得到 %v", test.input, err)
+		} else if syntaxErr.Msg != test.errorMsg {
+			t.Errorf("对于输入 %s: 期望错误消息为 %s, 得到 %s", test.input, test.errorMsg, syntaxErr.Msg)
+		}
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected error
+	}{
+		{"abc + 1", calculator.ErrInvalidNumber},
+		{"1 / 0", calculator.ErrDivisionByZero},
+		{"0 ^ (0 - 1)", calculator.ErrDivisionByZero},
+		{"ln(0)", calculator.ErrDomain},
+		{"log(8,1)", calculator.ErrDomain},
+		{"arg(0)", calculator.ErrDomain},
+		{"atan(i)", calculator.ErrDomain},
+		{"polar(i, 1)", calculator.ErrDomain},
+		{"i / 0", calculator.ErrDivisionByZero},
+		{"coth(0)", calculator.ErrDomain},
+		{"csch(0)", calculator.ErrDomain},
+		{"atanh(1)", calculator.ErrDomain},
+		{"cosh(1000000)", calculator.ErrOverflow},
+		{"exp(1000000)", calculator.ErrOverflow},
+		{"10 ^ 1000000", calculator.ErrOverflow},
+	}
+
+	calc := calculator.NewCalculator(10)
+
+	for _, test := range tests {
+		node, err := NewParser(test.input, calc).Parse()
+		if err != nil {
+			t.Errorf("对于输入 %s: 解析失败: %v", test.input, err)
+			continue
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, test.expected) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", test.input, test.expected, err)
+		}
+	}
+
+	// 结果位数过多的近似计算应当立即返回溢出，而不是长时间占用服务器
+	for _, input := range []string{"exp(1e6)", "exp(50000)", "sinh(100000)", "2 ^ 50000.5", "exp(50000 + i)", "gamma(3000.5)"} {
+		node, err := NewParser(input, calc).Parse()
+		if err != nil {
+			t.Errorf("对于输入 %s: 解析失败: %v", input, err)
+			continue
+		}
+		start := time.Now()
+		if _, err := node.Evaluate(); !errors.Is(err, calculator.ErrOverflow) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", input, calculator.ErrOverflow, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("对于输入 %s: 返回溢出耗时 %v", input, elapsed)
+		}
+	}
 }
 
 func TestParserPrecision(t *testing.T) {
-    tests := []struct {
-        input     string
-        precision int32
-        expected  string
-    }{
-        {"PI", 5, "3.14159"},
-        {"E", 4, "2.7183"},
-        {"1.23456789", 4, "1.2346"},
-        {"sin(PI/6)", 4, "0.5000"},
-    }
-
-    for _, test := range tests {
-        calc := calculator.NewCalculator(test.precision)
-        parser := NewParser(test.input, calc)
-        result := parser.Parse().Evaluate()
-        if result != test.expected {
-            t.Errorf("对于输入 %s (精度 %d): 期望 %s, 得到 %s", 
-                test.input, test.precision, test.expected, result)
-        }
-    }
+	tests := []struct {
+		input     string
+		precision int32
+		expected  string
+	}{
+		{"PI", 5, "3.14159"},
+		{"E", 4, "2.7183"},
+		{"E", 75, "2.718281828459045235360287471352662497757247093699959574966967627724076630354"},
+		{"E", 76, "2.7182818284590452353602874713526624977572470936999595749669676277240766303535"},
+		{"1.23456789", 4, "1.2346"},
+		{"sin(PI/6)", 4, "0.5"},
+	}
+
+	for _, test := range tests {
+		calc := calculator.NewCalculator(test.precision)
+		result := calc.Format(evaluate(t, test.input, calc))
+		if result != test.expected {
+			t.Errorf("对于输入 %s (精度 %d): 期望 %s, 得到 %s",
+				test.input, test.precision, test.expected, result)
+		}
+	}
+}
+
+func TestRoundingModes(t *testing.T) {
+	tests := []struct {
+		input     string
+		precision int32
+		mode      calculator.RoundingMode
+		expected  string
+	}{
+		{"2.5", 0, calculator.RoundHalfUp, "3"},
+		{"2.5", 0, calculator.RoundHalfEven, "2"},
+		{"2.5", 0, calculator.RoundHalfDown, "2"},
+		{"0 - 2.5", 0, calculator.RoundHalfUp, "-3"},
+		{"0 - 2.5", 0, calculator.RoundHalfEven, "-2"},
+		{"0 - 2.5", 0, calculator.RoundCeiling, "-2"},
+		{"0 - 2.5", 0, calculator.RoundFloor, "-3"},
+		{"1 / 8", 2, calculator.RoundHalfUp, "0.13"},
+		{"1 / 8", 2, calculator.RoundHalfEven, "0.12"},
+		{"3 / 8", 2, calculator.RoundHalfEven, "0.38"},
+		{"1 / 3", 4, calculator.RoundCeiling, "0.3334"},
+		{"1 / 3", 4, calculator.RoundFloor, "0.3333"},
+		{"2 / 3", 4, calculator.RoundDown, "0.6666"},
+		{"2 / 3", 2, calculator.RoundToOdd, "0.67"},
+		{"1 / 3", 2, calculator.RoundToOdd, "0.33"},
+		{"0.5 / 1", 0, calculator.RoundToOdd, "1"},
+		{"(0 - 2) / 3", 4, calculator.RoundFloor, "-0.6667"},
+		{"sqrt(2)", 5, calculator.RoundFloor, "1.41421"},
+		{"sqrt(2)", 5, calculator.RoundCeiling, "1.41422"},
+		{"sqrt(16)", 5, calculator.RoundCeiling, "4"},
+		{"sqrt(0.0625)", 3, calculator.RoundHalfEven, "0.25"},
+		{"2 ^ 0.5", 3, calculator.RoundCeiling, "1.415"},
+		{"1.0000000001 ^ 3", 10, calculator.RoundCeiling, "1.0000000004"},
+		{"1.0000000001 ^ 3", 10, calculator.RoundFloor, "1.0000000003"},
+		{"log(8,2)", 5, calculator.RoundCeiling, "3"},
+		{"log(8,2)", 5, calculator.RoundFloor, "3"},
+		{"sin(0)", 5, calculator.RoundCeiling, "0"},
+		{"PI", 4, calculator.RoundFloor, "3.1415"},
+		{"PI", 4, calculator.RoundCeiling, "3.1416"},
+	}
+
+	for _, test := range tests {
+		calc := calculator.NewCalculator(test.precision, calculator.WithRounding(test.mode))
+		result := calc.Format(evaluate(t, test.input, calc))
+		if result != test.expected {
+			t.Errorf("对于输入 %s (精度 %d, 舍入 %s): 期望 %s, 得到 %s",
+				test.input, test.precision, test.mode, test.expected, result)
+		}
+	}
+}
+
+func TestSignificantDigits(t *testing.T) {
+	tests := []struct {
+		input     string
+		precision int32
+		expected  string
+	}{
+		{"0.000000000000000000000000000001 * 3", 10, "0.000000000000000000000000000003000000000"},
+		{"0.000000000000000000001 * 3", 5, "0.0000000000000000000030000"},
+		{"1e-21 * 3", 5, "0.0000000000000000000030000"},
+		{"1e-30 * 3", 10, "0.000000000000000000000000000003000000000"},
+		{"2 / 3", 0, "0.7"},
+		{"1 / 3", 5, "0.33333"},
+		{"200000 / 3", 5, "66667"},
+		{"2000000 / 3", 3, "667000"},
+		{"123456789 * 1000", 3, "123000000000"},
+		{"9.996", 3, "10.0"},
+		{"1 / 2", 4, "0.5000"},
+		{"sqrt(2)", 5, "1.4142"},
+		{"sqrt(0.0000000002)", 4, "0.00001414"},
+		{"sin(0.000000000000000000001)", 5, "0.0000000000000000000010000"},
+		{"ln(1.000000000000000000001)", 5, "0.0000000000000000000010000"},
+		{"exp(0 - 100)", 6, "0.0000000000000000000000000000000000000000000372008"},
+		{"PI * 1000000", 4, "3142000"},
+		{"2 ^ 100", 5, "1267700000000000000000000000000"},
+	}
+
+	for _, test := range tests {
+		calc := calculator.NewCalculator(test.precision, calculator.WithPrecisionMode(calculator.SignificantDigits))
+		result := calc.Format(evaluate(t, test.input, calc))
+		if result != test.expected {
+			t.Errorf("对于输入 %s (有效数字 %d): 期望 %s, 得到 %s", test.input, test.precision, test.expected, result)
+		}
+	}
+}
+
+func TestScientificNotation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2.5E+3", "2500"},
+		{"1e5 + 1", "100001"},
+		{"1.5e-3 * 2", "0.003"},
+		{".5e1", "5"},
+		{"ln(1e-300)", "-690.7755278982"},
+		{"2e-3 - 1e-3", "0.001"},
+		{"1e+2-1", "99"},
+		{"0x1e-3", "27"},
+	}
+
+	calc := calculator.NewCalculator(10)
+	for _, test := range tests {
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	node, err := NewParser("1e1000000000 + 1", calc).Parse()
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if _, err := node.Evaluate(); !errors.Is(err, calculator.ErrOverflow) {
+		t.Errorf("对于输入 1e1000000000 + 1: 期望错误 %v, 得到 %v", calculator.ErrOverflow, err)
+	}
+}
+
+func TestUnaryMinus(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"-3", "-3"},
+		{"+ 2", "2"},
+		{"2*-3", "-6"},
+		{"2^-1", "0.5"},
+		{"-2^2", "-4"}, // 负号低于乘方
+		{"(-2)^2", "4"},
+		{"-2*3 + 1", "-5"},
+		{"1 - -2", "3"},
+		{"--2", "2"},
+		{"-(1 + 2)", "-3"},
+		{"2^-2^2", "0.0625"},
+		{"sqrt(-4)", "0 + 2i"},
+		{"ln(-1)", "0 + 3.1415926536i"},
+		{"asin(2)", "1.5707963268 - 1.3169578969i"},
+		{"-i", "0 - 1i"},
+		{"-5 km", "-5 km"},
+		{"-[1, 2; 3, 4]", "[-1, -2; -3, -4]"},
+		{"-(3±0.1)", "-3.0000000000 ± 0.1000000000"},
+	}
+
+	calc := calculator.NewCalculator(10)
+	for _, test := range tests {
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
 }
 
 func TestComplexExpressions(t *testing.T) {
-    tests := []struct {
-        input    string
-        expected string
-    }{
-        {"2 * PI + sin(PI/2) * cos(PI/3)", "7.062177826491071"},
-        {"log(E^2, E) + ln(E^3)", "5"},
-        {"sqrt(PI^2 + E^2)", "4.180754863754716"},
-        {"sin(PI/4)^2 + cos(PI/4)^2", "1"},
-        {"log(1000,10) + ln(E) + lg(100)", "6"},
-    }
-
-    calc := calculator.NewCalculator(12)
-
-    for _, test := range tests {
-        parser := NewParser(test.input, calc)
-        result := parser.Parse().Evaluate()
-        expected, _ := decimal.NewFromString(test.expected)
-        actual, _ := decimal.NewFromString(result)
-        if !expected.Sub(actual).Abs().LessThan(decimal.NewFromFloat(1e-10)) {
-            t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
-        }
-    }
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2 * PI + sin(PI/2) * cos(PI/3)", "6.783185307180"},
+		{"log(E^2, E) + ln(E^3)", "5"},
+		{"sqrt(PI^2 + E^2)", "4.154354402313"},
+		{"sin(PI/4)^2 + cos(PI/4)^2", "1"},
+		{"log(1000,10) + ln(E) + lg(100)", "6"},
+	}
+
+	calc := calculator.NewCalculator(12)
+
+	for _, test := range tests {
+		actual := evaluate(t, test.input, calc)
+		expected, _ := decimal.NewFromString(test.expected)
+		if !expected.Sub(actual).Abs().LessThan(decimal.NewFromFloat(1e-10)) {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestComplexNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"sqrt(0 - 4)", "0 + 2i"},
+		{"i * i", "-1"},
+		{"(1 + i) ^ 2", "0 + 2i"},
+		{"(2 + 3i) / (1 - i)", "-0.5 + 2.5i"},
+		{"E ^ (i * PI)", "-1"},
+		{"ln(0 - 1)", "0 + 3.1415926536i"},
+		{"asin(2)", "1.5707963268 - 1.3169578969i"},
+		{"acos(0 - 1.5)", "3.1415926536 - 0.9624236501i"},
+		{"(0 - 8) ^ 0.5", "0 + 2.8284271247i"},
+		{"sqrt(i)", "0.7071067812 + 0.7071067812i"},
+		{"cos(1 + i)", "0.8337300251 - 0.9888977058i"},
+		{"i ^ i", "0.2078795764"},
+		{"abs(3 + 4i)", "5"},
+		{"arg(0 - 1)", "3.1415926536"},
+		{"conj(3 + 4i)", "3 - 4i"},
+		{"re(3 + 4i)", "3"},
+		{"im(3 - 4i)", "-4"},
+		{"polar(2, PI/2)", "0 + 2i"},
+		{"sinh(i)", "0 + 0.8414709848i"},
+		{"acosh(0.5)", "0 + 1.0471975512i"},
+	}
+
+	calc := calculator.NewCalculator(10)
+
+	for _, test := range tests {
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	// 有效数字模式下实部和虚部各自保留有效数字
+	significant := []struct {
+		input    string
+		expected string
+	}{
+		{"exp(0.000000000000000000001i) - 1", "0.00000 + 0.0000000000000000000010000i"},
+		{"sinh(0.000000000000000000001i)", "0.00000 + 0.0000000000000000000010000i"},
+		{"sqrt(i) * 0.000000000000000000001", "0.00000000000000000000070711 + 0.00000000000000000000070711i"},
+		{"ln(0 - 1)", "0.00000 + 3.1416i"},
+	}
+	calc = calculator.NewCalculator(5, calculator.WithPrecisionMode(calculator.SignificantDigits))
+	for _, test := range significant {
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s (有效数字 5): 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+}
+
+func TestExactMode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1/3*3", "1"},
+		{"1/3+1/4", "7/12 ≈ 0.5833333333"},
+		{"0.1 + 0.2", "3/10 = 0.3"},
+		{"(2/3)^3", "8/27 ≈ 0.2962962963"},
+		{"2^(0-2)", "1/4 = 0.25"},
+		{"2^100", "1267650600228229401496703205376"},
+		{"1/3 + sqrt(4)", "2.3333333333"},
+		{"(1/4)^0.5", "0.5"},
+		{"abs(0 - 1/3)", "0.3333333333"},
+	}
+
+	calc := calculator.NewCalculator(10, calculator.WithExact())
+
+	for _, test := range tests {
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	for _, input := range []string{"1/(1/3 - 1/3)", "0^(0-1)"} {
+		node, err := NewParser(input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, calculator.ErrDivisionByZero) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", input, calculator.ErrDivisionByZero, err)
+		}
+	}
+}
+
+func TestHyperbolicFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"sinh(1)", "1.1752011936438014568823818505956008151557179813341"},
+		{"cosh(0.5)", "1.12762596520638078522622516140267201254784711809867"},
+		{"tanh(2)", "0.96402758007581688394641372410092315025502997624093"},
+		{"coth(3)", "1.00496982331368917109315124282800285381772306638422"},
+		{"sech(1)", "0.64805427366388539957497735322615032310848931207194"},
+		{"csch(0.1)", "9.98335275729610963794688412764321480709145431332729"},
+		{"asinh(2)", "1.44363547517881034249327674027310526940555300315698"},
+		{"acosh(3)", "1.76274717403908605046521864995958461805632065652327"},
+		{"atanh(0.5)", "0.54930614433405484569762261846126285232374527891137"},
+		{"tanh(1000)", "1"},
+		{"sinh(0)", "0"},
+	}
+
+	calc := calculator.NewCalculator(50)
+
+	for _, test := range tests {
+		result := calc.Format(evaluate(t, test.input, calc))
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+}
+
+func TestHyperbolicIdentities(t *testing.T) {
+	identities := []string{
+		"cosh(2.5)^2 - sinh(2.5)^2",
+		"cosh(0.001)^2 - sinh(0.001)^2",
+		"tanh(1.7)^2 + sech(1.7)^2",
+		"coth(0.3)^2 - csch(0.3)^2",
+		"sinh(asinh(7)) / 7",
+		"cosh(acosh(1.25)) / 1.25",
+		"tanh(atanh(0.9)) / 0.9",
+	}
+
+	// 每一步都会舍入，以 60 位计算后检验前 50 位小数
+	calc := calculator.NewCalculator(60)
+	expected := decimal.NewFromInt(1)
+
+	for _, input := range identities {
+		result := evaluate(t, input, calc).Round(50)
+		if !result.Equal(expected) {
+			t.Errorf("对于输入 %s: 期望 1, 得到 %s", input, result)
+		}
+	}
+}
+
+func TestAngleUnits(t *testing.T) {
+	tests := []struct {
+		input    string
+		unit     calculator.AngleUnit
+		expected string
+	}{
+		{"sin(30)", calculator.Degrees, "0.5"},
+		{"cos(60)", calculator.Degrees, "0.5"},
+		{"tan(135)", calculator.Degrees, "-1"},
+		{"sin(180)", calculator.Degrees, "0"},
+		{"cos(90)", calculator.Degrees, "0"},
+		{"sin(1000000000000000000000030)", calculator.Degrees, "-0.7660444431"},
+		{"asin(0.5)", calculator.Degrees, "30"},
+		{"acos(0)", calculator.Degrees, "90"},
+		{"atan(1)", calculator.Degrees, "45"},
+		{"arg(i)", calculator.Degrees, "90"},
+		{"polar(2, 90)", calculator.Degrees, "0 + 2i"},
+		{"cos(100)", calculator.Gradians, "0"},
+		{"atan(1)", calculator.Gradians, "50"},
+		{"asin(0.5)", calculator.Gradians, "33.3333333333"},
+		{"sin(deg(30))", calculator.Radians, "0.5"},
+		{"sin(deg(30))", calculator.Gradians, "0.5"},
+		{"deg(180)", calculator.Radians, "3.1415926536"},
+		{"grad(100)", calculator.Degrees, "90"},
+		{"rad(1)", calculator.Degrees, "57.2957795131"},
+		{"30°15'10\"", calculator.Degrees, "30.2527777778"},
+		{"30°15′", calculator.Degrees, "30.25"},
+		{"sin(30°)", calculator.Radians, "0.5"},
+		{"90°", calculator.Gradians, "100"},
+	}
+
+	for _, test := range tests {
+		calc := calculator.NewCalculator(10, calculator.WithAngleUnit(test.unit))
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s (%s): 期望 %s, 得到 %s", test.input, test.unit, test.expected, result)
+		}
+	}
+
+	calc := calculator.NewCalculator(10, calculator.WithAngleUnit(calculator.Degrees))
+	for _, input := range []string{"tan(90)", "30°75'"} {
+		node, err := NewParser(input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, calculator.ErrDomain) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", input, calculator.ErrDomain, err)
+		}
+	}
+}
+
+func TestIntegerFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5!", "120"},
+		{"(2 + 1)!", "6"},
+		{"0!", "1"},
+		{"fact(20)", "2432902008176640000"},
+		{"fact(5) * fact(3)", "720"},
+		{"fact(5) - 5!", "0"},
+		{"5!!", "15"},
+		{"6!!", "48"},
+		{"0!! + 1!!", "2"},
+		{"dfact(9)", "945"},
+		{"5! !", "6689502913449127057588118054090372586752746333138029810295671352301633557244962989366874165271984981308157637893214090552534408589408121859898481114389650005964960521256960000000000000000000000000000"},
+		{"(3!)!!", "48"},
+		{"2 ^ 3!!", "8"},
+		{"-5!!", "-15"},
+		{"fact(5) / fact(7)", "0.0238095238"},
+		{"sqrt(fact(4))", "4.8989794856"},
+		{"nCr(10, 3)", "120"},
+		{"nCr(5, 7)", "0"},
+		{"nCr(100000000000000000000, 2)", "4999999999999999999950000000000000000000"},
+		{"nPr(10, 3)", "720"},
+		{"nPr(10, 0)", "1"},
+		{"gcd(12, 18, 27)", "3"},
+		{"gcd(0 - 12, 18)", "6"},
+		{"lcm(4, 6, 10)", "60"},
+		{"lcm(4, 0)", "0"},
+		{"mod(17, 5)", "2"},
+		{"mod(0 - 7, 3)", "2"},
+		{"mod(7, 0 - 3)", "-2"},
+		{"nCr(1000, 500)", "270288240945436569515614693625975275496152008446548287007392875106625428705522193898612483924502370165362606085021546104802209750050679917549894219699518475423665484263751733356162464079737887344364574161119497604571044985756287880514600994219426752366915856603136862602484428109296905863799821216320"},
+	}
+
+	calc := calculator.NewCalculator(10)
+	for _, test := range tests {
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	// 整数结果保留全部数字，不按精度舍入
+	significant := calculator.NewCalculator(3, calculator.WithPrecisionMode(calculator.SignificantDigits))
+	if result := evaluateValue(t, "fact(30)", significant).Format(significant); result != "265252859812191058636308480000000" {
+		t.Errorf("对于输入 fact(30): 期望保留全部数字, 得到 %s", result)
+	}
+	// 500! 共 1135 位，末尾恰有 124 个零
+	fact500 := evaluateValue(t, "fact(500)", calc).Format(calc)
+	if len(fact500) != 1135 || !strings.HasPrefix(fact500, "122013682599111006870123878542") ||
+		len(fact500)-len(strings.TrimRight(fact500, "0")) != 124 {
+		t.Errorf("fact(500) 的结果不正确: %s", fact500)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected error
+	}{
+		{"fact(2.5)", calculator.ErrDomain},
+		{"fact(0 - 1)", calculator.ErrDomain},
+		{"(0 - 3)!", calculator.ErrDomain},
+		{"nCr(5, 0 - 1)", calculator.ErrDomain},
+		{"nPr(4.5, 2)", calculator.ErrDomain},
+		{"gcd(4, 0.5)", calculator.ErrDomain},
+		{"mod(5, 0)", calculator.ErrDivisionByZero},
+		{"fact(100000)", calculator.ErrOverflow},
+		{"dfact(2.5)", calculator.ErrDomain},
+		{"(0 - 1)!!", calculator.ErrDomain},
+		{"dfact(200000)", calculator.ErrOverflow},
+		{"dfact(200001)", calculator.ErrOverflow},
+	}
+	for _, test := range errorTests {
+		node, err := NewParser(test.input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", test.input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, test.expected) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", test.input, test.expected, err)
+		}
+	}
+}
+
+func TestSpecialFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"gamma(0.5)", "1.772453850905516027298167483341"},
+		{"gamma(5)", "24"},
+		{"gamma(0 - 1.5)", "2.363271801207354703064223311122"},
+		{"lgamma(100)", "359.134205369575398776044010460287"},
+		{"beta(2, 3)", "0.083333333333333333333333333333"},
+		{"beta(0.5, 0.5)", "3.14159265358979323846264338328"},
+		{"digamma(1)", "-0.577215664901532860606512090082"},
+		{"digamma(0.5)", "-1.963510026021423479440976332999"},
+		{"erf(0.5)", "0.520499877813046537682746653892"},
+		{"erf(0 - 1)", "-0.842700792949714869341220635083"},
+		{"erfc(4)", "0.00000001541725790028001885216"},
+		{"erfc(0 - 1)", "1.842700792949714869341220635083"},
+		{"j0(1)", "0.765197686557966551449717526103"},
+		{"j1(2.5)", "0.497094102464274038010816276264"},
+		{"y0(1)", "0.088256964215676957982926766024"},
+		{"y1(0.5)", "-1.471472392670243069188584635323"},
+		{"j0(100)", "0.019985850304223122424228390951"},
+	}
+
+	calc := calculator.NewCalculator(30)
+	for _, test := range tests {
+		result := calc.Format(evaluate(t, test.input, calc))
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	// 1 - erf(x) 在 x 较大时几乎完全相消，erfc 仍应保留全部有效数字
+	significant := calculator.NewCalculator(20, calculator.WithPrecisionMode(calculator.SignificantDigits))
+	if result := significant.Format(evaluate(t, "erfc(10)", significant)); result != "0.0000000000000000000000000000000000000000000020884875837625447570" {
+		t.Errorf("对于输入 erfc(10): 期望 20 位有效数字, 得到 %s", result)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected error
+	}{
+		{"gamma(0)", calculator.ErrDomain},
+		{"gamma(0 - 3)", calculator.ErrDomain},
+		{"lgamma(0 - 2)", calculator.ErrDomain},
+		{"beta(1, 0 - 1)", calculator.ErrDomain},
+		{"y0(0)", calculator.ErrDomain},
+		{"y1(0 - 2)", calculator.ErrDomain},
+		{"gamma(100000)", calculator.ErrOverflow},
+	}
+	for _, test := range errorTests {
+		node, err := NewParser(test.input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", test.input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, test.expected) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", test.input, test.expected, err)
+		}
+	}
+}
+
+func TestPrimeFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"isprime(97)", "1"},
+		{"isprime(561)", "0"},
+		{"isprime(1)", "0"},
+		{"isprime(0 - 7)", "0"},
+		{"isprime(2^127 - 1)", "1"},
+		{"nextprime(100)", "101"},
+		{"nextprime(0 - 5)", "2"},
+		{"nextprime(2^89)", "618970019642690137449562141"},
+		{"factor(168)", "2^3 * 3 * 7"},
+		{"factor(0 - 12)", "-1 * 2^2 * 3"},
+		{"factor(1)", "1"},
+		{"factor(97)", "97"},
+		{"factor(fact(20))", "2^18 * 3^8 * 5^4 * 7^2 * 11 * 13 * 17 * 19"},
+		{"factor(2^64 + 1)", "274177 * 67280421310721"},
+		{"factor(1000000007 * 998244353)", "998244353 * 1000000007"},
+		{"factor(12) + 1", "13"},
+		{"totient(36)", "12"},
+		{"totient(97)", "96"},
+		{"totient(1)", "1"},
+		{"powmod(4, 13, 497)", "445"},
+		{"powmod(0 - 2, 3, 5)", "2"},
+		{"powmod(3, 0 - 1, 7)", "5"},
+		{"powmod(2, 10^30, 10^9 + 7)", "312267046"},
+		{"modinv(3, 7)", "5"},
+		{"modinv(0 - 3, 7)", "2"},
+	}
+
+	calc := calculator.NewCalculator(10)
+	for _, test := range tests {
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected error
+	}{
+		{"factor(0)", calculator.ErrDomain},
+		{"factor(2.5)", calculator.ErrDomain},
+		{"totient(0)", calculator.ErrDomain},
+		{"powmod(2, 0 - 1, 4)", calculator.ErrDomain},
+		{"powmod(2, 3, 0)", calculator.ErrDomain},
+		{"modinv(2, 4)", calculator.ErrDomain},
+		{"isprime(10^1000)", calculator.ErrOverflow},
+	}
+	for _, test := range errorTests {
+		node, err := NewParser(test.input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", test.input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, test.expected) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", test.input, test.expected, err)
+		}
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"stddev(2, 4, 4, 4, 5, 5, 7, 9)", "2"},
+		{"variance(2, 4, 4, 4, 5, 5, 7, 9)", "4"},
+		{"stddev_s(2, 4, 4, 4, 5, 5, 7, 9)", "2.1380899353"},
+		{"variance_s(1, 2, 3, 4)", "1.6666666667"},
+		{"stddev(1, 1, 1)", "0"},
+		{"mean(1, 2, 2)", "1.6666666667"},
+		{"median(3, 1, 2)", "2"},
+		{"median(4, 1, 3, 2)", "2.5"},
+		{"mode(3, 1, 3, 2, 1)", "1"},
+		{"min(3, 0 - 1, 2)", "-1"},
+		{"max(3, 0 - 1, 2)", "3"},
+		{"sum(1, 2, 3.5)", "6.5"},
+		{"prod(1, 2, 3, 4)", "24"},
+		{"percentile(50, 4, 1, 3, 2)", "2.5"},
+		{"percentile(90, 15, 20, 35, 40, 50)", "46"},
+		{"percentile(0, 7, 3)", "3"},
+		{"percentile(100, 7, 3)", "7"},
+		{"geomean(2, 8)", "4"},
+		{"geomean(1, 2, 3)", "1.8171205928"},
+		{"mean(sqrt(4), 2^3, 5!)", "43.3333333333"},
+	}
+
+	calc := calculator.NewCalculator(10)
+	for _, test := range tests {
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected error
+	}{
+		{"geomean(0, 1)", calculator.ErrDomain},
+		{"percentile(101, 1, 2)", calculator.ErrDomain},
+		{"sum(1, 2i)", calculator.ErrDomain},
+	}
+	for _, test := range errorTests {
+		node, err := NewParser(test.input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", test.input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, test.expected) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", test.input, test.expected, err)
+		}
+	}
+}
+
+func TestDistributions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 - normcdf(6)", "0.000000000986587645037698140701"},
+		{"normcdf(0 - 6)", "0.000000000986587645037698140701"},
+		{"normcdf(110, 100, 15)", "0.747507462453077086935938175611"},
+		{"normpdf(0)", "0.398942280401432677939946059934"},
+		{"normpdf(1, 0, 2)", "0.176032663382149738887340220798"},
+		{"norminv(0.975)", "1.959963984540054235524594430521"},
+		{"norminv(0.999)", "3.090232306167813541540399830107"},
+		{"norminv(0.00000000000000001)", "-8.493793224109598074444718813229"},
+		{"norminv(0.99999999999999999)", "8.493793224109598074444718813229"},
+		{"norminv(0.5, 100, 15)", "100"},
+		{"binompdf(3, 10, 0.5)", "0.1171875"},
+		{"binomcdf(3, 10, 0.5)", "0.171875"},
+		{"binomcdf(50, 1000, 0.1)", "0.000000005995167632379620315021"},
+		{"binomcdf(500, 1000, 0.5)", "0.512612509089180400953420844381"},
+		{"poissonpdf(3, 2)", "0.18044704431548358919199932663"},
+		{"poissoncdf(1000, 1000)", "0.508409367168505991214259092872"},
+		{"tcdf(2, 5)", "0.949030260585070821877319447079"},
+		{"tcdf(0 - 2, 5)", "0.050969739414929178122680552921"},
+		{"tcdf(1, 1)", "0.75"},
+		{"chi2cdf(3.84, 1)", "0.949956478751294901052336335838"},
+		{"chi2cdf(10, 5)", "0.924764753853487821277923132995"},
+		{"expcdf(1, 2)", "0.864664716763387308106000505028"},
+	}
+
+	calc := calculator.NewCalculator(30)
+	for _, test := range tests {
+		result := calc.Format(evaluate(t, test.input, calc))
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	// 尾部概率按有效数字计算时仍然精确
+	significant := calculator.NewCalculator(15, calculator.WithPrecisionMode(calculator.SignificantDigits))
+	if result := significant.Format(evaluate(t, "normcdf(0 - 20)", significant)); result != "0.0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000275362411860623" {
+		t.Errorf("对于输入 normcdf(0 - 20): 得到 %s", result)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected error
+	}{
+		{"normpdf(0, 0, 0)", calculator.ErrDomain},
+		{"norminv(1)", calculator.ErrDomain},
+		{"binompdf(2.5, 10, 0.5)", calculator.ErrDomain},
+		{"binomcdf(2, 10, 1.5)", calculator.ErrDomain},
+		{"poissoncdf(2, 0)", calculator.ErrDomain},
+		{"tcdf(1, 0 - 1)", calculator.ErrDomain},
+	}
+	for _, test := range errorTests {
+		node, err := NewParser(test.input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", test.input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, test.expected) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", test.input, test.expected, err)
+		}
+	}
+}
+
+func TestFinancialFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"pmt(0.005, 360, 200000)", "-1199.10105030550478918292"},
+		{"pmt(0.005, 360, 200000, 0, 1)", "-1193.13537343831322306758"},
+		{"pmt(0, 10, 1000)", "-100"},
+		{"fv(0.005, 120, 0 - 100, 0 - 1000)", "18207.33141467857786293981"},
+		{"pv(0.005, 240, 500)", "-69790.38584146457915645846"},
+		{"pv(0.005, 240, 500, 10000, 1)", "-73160.2991865254441365949"},
+		{"nper(0.01, 0 - 100, 1000)", "10.58864445942323599519"},
+		{"nper(0, 0 - 100, 1000)", "10"},
+		{"npv(0.1, 0 - 10000, 3000, 4200, 6800)", "1188.44341233522300389318"},
+		{"irr(0 - 70000, 12000, 15000, 18000, 21000, 26000)", "0.08663094803653161429"},
+		{"irr(0 - 70000, 12000, 15000, 18000, 21000)", "-0.02124484827341099103"},
+		{"rate(48, 0 - 200, 8000)", "0.00770147248820204382"},
+		{"rate(10, 0 - 100, 1000)", "0"},
+	}
+
+	calc := calculator.NewCalculator(20)
+	for _, test := range tests {
+		result := calc.Format(evaluate(t, test.input, calc))
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	// 参数按工作精度求值，利率不会先被舍入为 0.00
+	cents := calculator.NewCalculator(2)
+	for input, expected := range map[string]string{
+		"pmt(0.05/12, 360, 200000)":       "-1073.64",
+		"nper(0.05/12, -1073.64, 200000)": "360",
+		"fv(0.05/12, 12, -100)":           "1227.89",
+		"npv(0.05/12, 100, 100)":          "198.76",
+	} {
+		if result := cents.Format(evaluate(t, input, cents)); result != expected {
+			t.Errorf("对于输入 %s (精度 2): 期望 %s, 得到 %s", input, expected, result)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected error
+	}{
+		{"irr(1, 0 - 2, 1.5)", calculator.ErrNotConverged},
+		{"rate(10, 100, 1000)", calculator.ErrNotConverged},
+		{"irr(1, 2)", calculator.ErrDomain},
+		{"pmt(0.1, 10, 1000, 0, 2)", calculator.ErrDomain},
+		{"pmt(0 - 1, 10, 1000)", calculator.ErrDomain},
+		{"nper(0.1, 0 - 50, 1000)", calculator.ErrDomain},
+		{"npv(0 - 1, 100)", calculator.ErrDivisionByZero},
+	}
+	for _, test := range errorTests {
+		node, err := NewParser(test.input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", test.input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, test.expected) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", test.input, test.expected, err)
+		}
+	}
+}
+
+func TestUnits(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5 km + 300 m in mi", "3.2932673189 mi"},
+		{"9.81 m/s^2 * 70 kg", "686.7 N"},
+		{"5 km + 300 m", "5300 m"},
+		{"5 km + 3 km", "8 km"},
+		{"0 - 5 m", "-5 m"},
+		{"10 m / 2 s", "5 m/s"},
+		{"100 km/h in m/s", "27.7777777778 m/s"},
+		{"60 mph in km/h", "96.56064 km/h"},
+		{"(5 km)^2", "25 km^2"},
+		{"(2 m/s)^2", "4 (m/s)^2"},
+		{"sqrt(16 m^2)", "4 m"},
+		{"(4 m^2)^0.5", "2 m"},
+		{"2 N * 3 m", "6 J"},
+		{"1 kWh in J", "3600000 J"},
+		{"2 A * 3 s", "6 C"},
+		{"3 J / 2 K", "1.5 J/K"},
+		{"1 kg/(m*s^2)", "1 Pa"},
+		{"3 m * 2 m * 1 m in L", "6000 L"},
+		{"72 inch in ft", "6 ft"},
+		{"5 lb in kg", "2.26796185 kg"},
+		{"5 μm in nm", "5000 nm"},
+		{"5 min in s", "300 s"},
+		{"min(3, 4) * 1 h in min", "180 min"},
+	}
+
+	calc := calculator.NewCalculator(10)
+	for _, test := range tests {
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	// 量纲相互抵消时结果为实数
+	if result := evaluate(t, "1 km / 1 m", calc); !result.Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("对于输入 1 km / 1 m: 期望 1000, 得到 %s", result)
+	}
+
+	for _, input := range []string{"5 m + 3 s", "5 m + 3", "5 km in s", "sin(5 m)", "2 ^ (3 m)", "(2 m)^0.5", "sqrt(2 m)"} {
+		node, err := NewParser(input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, calculator.ErrUnitMismatch) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", input, calculator.ErrUnitMismatch, err)
+		}
+	}
+}
+
+func TestCurrency(t *testing.T) {
+	rates, err := calculator.NewRates("USD", "2024-01-31", map[string]decimal.Decimal{
+		"EUR": decimal.RequireFromString("0.92"),
+		"CNY": decimal.RequireFromString("7.18"),
+		"JPY": decimal.RequireFromString("148.2"),
+		"KWD": decimal.RequireFromString("0.3075"),
+	})
+	if err != nil {
+		t.Fatalf("创建汇率表失败: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"100 USD + 50 EUR in CNY", "1108.22 CNY"},
+		{"100 USD + 50 EUR", "154.35 USD"},
+		{"100 USD in JPY", "14820 JPY"},
+		{"100 USD in KWD", "30.750 KWD"},
+		{"1000 JPY / 3", "333 JPY"},
+		{"10 EUR / 3", "3.33 EUR"},
+		{"1000 JPY / 3 * 3", "1000 JPY"},
+		{"2 * (3 USD)", "6.00 USD"},
+		{"0 - 5 USD", "-5.00 USD"},
+		{"100 EUR / 50 USD", "2.1739130435"},
+	}
+
+	calc := calculator.NewCalculator(10, calculator.WithRates(rates))
+	for _, test := range tests {
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	for _, input := range []string{"100 USD + 5", "100 USD * 2 EUR", "5 m + 3 USD", "sqrt(4 USD)", "5 km in USD", "100 USD in m"} {
+		node, err := NewParser(input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, calculator.ErrUnitMismatch) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", input, calculator.ErrUnitMismatch, err)
+		} else if strings.Contains(err.Error(), "ast.") {
+			t.Errorf("对于输入 %s: 错误信息不应包含内部类型名, 得到 %v", input, err)
+		}
+	}
+
+	// 汇率表中没有的货币
+	if _, err := NewParser("100 USD in GBP", calc).Parse(); err == nil || !strings.Contains(err.Error(), "汇率表中没有货币GBP") {
+		t.Errorf("对于输入 100 USD in GBP: 期望汇率表中没有货币的错误, 得到 %v", err)
+	}
+
+	// 没有汇率表时货币代码不能解析
+	if _, err := NewParser("100 USD", calculator.NewCalculator(10)).Parse(); err == nil {
+		t.Errorf("没有汇率表时期望解析失败")
+	}
+}
+
+func TestBitwise(t *testing.T) {
+	tests := []struct {
+		input    string
+		base     calculator.NumberBase
+		expected string
+	}{
+		{"0xFF", calculator.Base10, "255"},
+		{"0b1010 + 0o17", calculator.Base10, "25"},
+		{"0xFFFF_FFFF", calculator.Base10, "4294967295"},
+		{"0xF0 | 0x0F", calculator.Base16, "0xff"},
+		{"0xFF & 0x0F", calculator.Base2, "0b1111"},
+		{"0xFF xor 0x0F", calculator.Base8, "0o360"},
+		{"~0", calculator.Base10, "-1"},
+		{"~0xFF", calculator.Base16, "-0x100"},
+		{"1 << 2 + 1", calculator.Base10, "8"},
+		{"0 - 256 >> 4", calculator.Base10, "-16"},
+		{"0 - 5 >> 100", calculator.Base10, "-1"},
+		{"0xFF & 0x0F | 0x100", calculator.Base16, "0x10f"},
+		{"(1 << 64) - 1", calculator.Base16, "0xffffffffffffffff"},
+		{"2^10 | 1", calculator.Base10, "1025"},
+		{"0xFF / 2", calculator.Base16, "127.5"},
+	}
+
+	for _, test := range tests {
+		calc := calculator.NewCalculator(10, calculator.WithBase(test.base))
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s (进制 %s): 期望 %s, 得到 %s", test.input, test.base, test.expected, result)
+		}
+	}
+
+	calc := calculator.NewCalculator(10)
+	for input, expected := range map[string]error{
+		"1.5 & 1":         calculator.ErrDomain,
+		"1 << (0 - 1)":    calculator.ErrDomain,
+		"1 << 1000000000": calculator.ErrOverflow,
+		"(2 + 3i) | 1":    calculator.ErrDomain,
+	} {
+		node, err := NewParser(input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, expected) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", input, expected, err)
+		}
+	}
+}
+
+func TestUncertainty(t *testing.T) {
+	tests := []struct {
+		input    string
+		mode     calculator.UncertaintyMode
+		expected string
+	}{
+		{"3.00±0.05", calculator.IntervalArithmetic, "3.000000 ± 0.050000"},
+		{"interval(2.9, 3.1)", calculator.IntervalArithmetic, "3.000000 ± 0.100000"},
+		{"2 * 3.00±0.05 + 1", calculator.IntervalArithmetic, "7.000000 ± 0.100000"},
+		{"(3±0.1) * (2±0.2)", calculator.IntervalArithmetic, "6.020000 ± 0.800000"},
+		{"(3±0.1) / (2±0.2)", calculator.IntervalArithmetic, "1.520202 ± 0.202021"},
+		{"interval(1, 2) - interval(1, 2)", calculator.IntervalArithmetic, "0.000000 ± 1.000000"},
+		{"interval(0 - 2, 3)^2", calculator.IntervalArithmetic, "4.500000 ± 4.500000"},
+		{"sqrt(interval(4, 9))", calculator.IntervalArithmetic, "2.500000 ± 0.500000"},
+		{"sin(interval(0, 3))", calculator.IntervalArithmetic, "0.500000 ± 0.500000"},
+		{"cos(interval(0 - 1, 1))", calculator.IntervalArithmetic, "0.770151 ± 0.229849"},
+		{"asin(0.5±0.1)", calculator.IntervalArithmetic, "0.527509 ± 0.115993"},
+		{"acos(0.5±0.1)", calculator.IntervalArithmetic, "1.043288 ± 0.115993"},
+		{"atan(1±0.1)", calculator.IntervalArithmetic, "0.782899 ± 0.050084"},
+		{"ln(interval(1, 2))", calculator.IntervalArithmetic, "0.346574 ± 0.346574"},
+		{"exp(interval(0, 1))", calculator.IntervalArithmetic, "1.859141 ± 0.859141"},
+		{"3.00±0.05", calculator.GaussianPropagation, "3.000000 ± 0.050000"},
+		{"(3±0.1) * (2±0.2)", calculator.GaussianPropagation, "6.000000 ± 0.632456"},
+		{"(3±0.1) / (2±0.2)", calculator.GaussianPropagation, "1.500000 ± 0.158114"},
+		{"interval(1, 2) - interval(1, 2)", calculator.GaussianPropagation, "0.000000 ± 0.707107"},
+		{"sqrt(2±0.1)", calculator.GaussianPropagation, "1.414214 ± 0.035355"},
+		{"sin(3±0.1)", calculator.GaussianPropagation, "0.141120 ± 0.098999"},
+		{"(2±0.1)^(3±0.1)", calculator.GaussianPropagation, "8.000000 ± 1.321927"},
+		{"asin(0.5±0.1)", calculator.GaussianPropagation, "0.523599 ± 0.115470"},
+		{"acos(0.5±0.1)", calculator.GaussianPropagation, "1.047198 ± 0.115470"},
+		{"atan(1±0.1)", calculator.GaussianPropagation, "0.785398 ± 0.050000"},
+		{"ln(2±0.1)", calculator.GaussianPropagation, "0.693147 ± 0.050000"},
+		{"exp(1±0.1)", calculator.GaussianPropagation, "2.718282 ± 0.271828"},
+	}
+
+	for _, test := range tests {
+		calc := calculator.NewCalculator(6, calculator.WithUncertainty(test.mode))
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s (%s): 期望 %s, 得到 %s", test.input, test.mode, test.expected, result)
+		}
+	}
+
+	// 区间的端点向外舍入，保证包含真实结果
+	calc := calculator.NewCalculator(6)
+	x := evaluateValue(t, "(3±0.1) / (2±0.2)", calc).(Interval)
+	if !x.Lo.Equal(decimal.RequireFromString("1.318181")) || !x.Hi.Equal(decimal.RequireFromString("1.722223")) {
+		t.Errorf("对于输入 (3±0.1) / (2±0.2): 期望 [1.318181, 1.722223], 得到 [%s, %s]", x.Lo, x.Hi)
+	}
+
+	// 高精度下中点与半径不受 decimal 默认除法精度的限制
+	precise := calculator.NewCalculator(40)
+	if result := evaluateValue(t, "1 / interval(3, 3)", precise).Format(precise); result != "0.3333333333333333333333333333333333333334 ± 0.0000000000000000000000000000000000000001" {
+		t.Errorf("对于输入 1 / interval(3, 3) (精度 40): 得到 %s", result)
+	}
+
+	for input, expected := range map[string]error{
+		"1 / interval(0 - 1, 1)":   calculator.ErrDivisionByZero,
+		"sqrt(interval(0 - 1, 1))": calculator.ErrDomain,
+		"tan(interval(1, 2))":      calculator.ErrDomain,
+		"interval(3, 2)":           calculator.ErrDomain,
+		"3±(0 - 1)":                calculator.ErrDomain,
+		"ln(interval(0, 1))":       calculator.ErrDomain,
+		"asin(1±0.1)":              calculator.ErrDomain,
+		"(3±0.1) m":                calculator.ErrUnitMismatch,
+		"(3±0.1) * i":              calculator.ErrDomain,
+	} {
+		node, err := NewParser(input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, expected) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", input, expected, err)
+		} else if strings.Contains(err.Error(), "ast.") {
+			t.Errorf("对于输入 %s: 错误信息不应包含内部类型名: %v", input, err)
+		}
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2; 3, 4]", "[1, 2; 3, 4]"},
+		{"[1; 2]", "[1; 2]"},
+		{"[1, 2; 3, 4] * [5; 6]", "[17; 39]"},
+		{"[1, 2; 3, 4] + [1, 1; 1, 1]", "[2, 3; 4, 5]"},
+		{"2 * [1, 2, 3]", "[2, 4, 6]"},
+		{"[1, 2, 3] / 3", "[0.3333, 0.6667, 1]"},
+		{"[1, 2; 3, 4]^2", "[7, 10; 15, 22]"},
+		{"[1, 2; 3, 4]^(0 - 1)", "[-2, 1; 1.5, -0.5]"},
+		{"det([1, 2; 3, 4])", "-2"},
+		{"det([2, 0, 1; 1, 3, 2; 1, 1, 2])", "6"},
+		{"inv([1, 2; 3, 4])", "[-2, 1; 1.5, -0.5]"},
+		{"transpose([1, 2, 3])", "[1; 2; 3]"},
+		{"trace([1, 2; 3, 4])", "5"},
+		{"rank([1, 2; 2, 4])", "1"},
+		{"dot([1, 2, 3], [4; 5; 6])", "32"},
+		{"cross([1, 0, 0], [0, 1, 0])", "[0, 0, 1]"},
+		{"norm([3; 4])", "5"},
+		{"norm([1, 2; 3, 4])", "5.4772"},
+		{"norm([3, 4])", "5"},
+		{"dot([1, 2], [3, 4])", "11"},
+		{"cross([1, 2], [3, 4])", "[0, 0, -2]"},
+		{"[1, 2] * [1, 2; 3, 4]", "[7, 10]"},
+		{"[1, 2, 3] * [1, 2, 3]", "[1, 4, 9]"}, // 不满足矩阵乘法的同形矩阵逐元素相乘
+		{"[1, 2; 3, 4] .* [1, 2; 3, 4]", "[1, 4; 9, 16]"},
+		{"[1; 2] .* [3; 4]", "[3; 8]"},
+		{"2 .* [1, 2]", "[2, 4]"},
+		{"2 .* 3", "6"},
+		{"interval(2.9, 3.1)", "3.0000 ± 0.1000"},
+	}
+
+	calc := calculator.NewCalculator(4)
+	for _, test := range tests {
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	for input, expected := range map[string]error{
+		"inv([1, 2; 2, 4])":        calculator.ErrSingular,
+		"det([1, 2, 3])":           calculator.ErrDimensionMismatch,
+		"[1, 2; 3, 4] * [1, 2]":    calculator.ErrDimensionMismatch,
+		"[1, 2, 3] .* [1, 2]":      calculator.ErrDimensionMismatch,
+		"[1, 2] * interval(1, 2)":  calculator.ErrDomain,
+		"[1, 2, 3] + [1; 2; 3]":    calculator.ErrDimensionMismatch,
+		"[1, 2, 3] + 1":            calculator.ErrDimensionMismatch,
+		"cross([1, 2, 3], [1; 2])": calculator.ErrDimensionMismatch,
+		"sin([1, 2, 3])":           calculator.ErrDimensionMismatch,
+		"1 / [1, 2, 3]":            calculator.ErrDomain,
+		"[1, 2; 3, 4]^0.5":         calculator.ErrDomain,
+		"[1, 1; 1, 1]^(0 - 1)":     calculator.ErrSingular,
+		"[1, 2, 3] / 0":            calculator.ErrDivisionByZero,
+	} {
+		node, err := NewParser(input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, expected) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", input, expected, err)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"diff(x^2*sin(x), x)", "2*x*sin(x) + x^2*cos(x)"},
+		{"diff(x^3, x, 2)", "12"},
+		{"diff(x^3, x, 0.5)", "3/4 = 0.75"},
+		{"diff(1/x, x)", "-1/x^2"},
+		{"diff(1/x, x, 3)", "-1/9 ≈ -0.1111111111"},
+		{"diff(ln(x), x)", "1/x"},
+		{"diff(lg(x), x)", "1/(x*ln(10))"},
+		{"diff(2^x, x)", "2^x*ln(2)"},
+		{"diff(E^x, x)", "E^x"},
+		{"diff(exp(2*x), x)", "2*exp(2*x)"},
+		{"diff(sqrt(x), x, 4)", "0.25"},
+		{"diff(cos(x), x)", "-sin(x)"},
+		{"diff(-x^2, x)", "-2*x"},
+		{"diff(cos(2*x), x)", "-2*sin(2*x)"},
+		{"diff(x^-2, x)", "-2*x^(-3)"},
+		{"diff(tan(x), x)", "1/cos(x)^2"},
+		{"diff(atan(x), x)", "1/(1 + x^2)"},
+		{"diff(atanh(x), x, 0.5)", "4/3 ≈ 1.3333333333"},
+		{"diff(sin(x), x, PI)", "-1"},
+		{"diff(diff(x^3, x), x)", "6*x"},
+		{"diff(0xFF*x, x)", "255"},
+	}
+
+	calc := calculator.NewCalculator(10)
+	for _, test := range tests {
+		result := evaluateValue(t, test.input, calc).Format(calc)
+		if result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	deg := calculator.NewCalculator(10, calculator.WithAngleUnit(calculator.Degrees))
+	if result := evaluateValue(t, "diff(sin(x), x)", deg).Format(deg); result != "PI/180*cos(x)" {
+		t.Errorf("角度制下 diff(sin(x), x): 期望 PI/180*cos(x), 得到 %s", result)
+	}
+
+	for _, input := range []string{"diff(abs(x), x)", "diff(x^2, x) + 1"} {
+		node, err := NewParser(input, calc).Parse()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", input, err)
+		}
+		if _, err := node.Evaluate(); !errors.Is(err, calculator.ErrDomain) {
+			t.Errorf("对于输入 %s: 期望错误 %v, 得到 %v", input, calculator.ErrDomain, err)
+		}
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x + x", "2*x"},
+		{"x*1", "x"},
+		{"x^0", "1"},
+		{"ln(E^x)", "x"},
+		{"sin(x)^2 + cos(x)^2", "1"},
+		{"sin(y)^2*3 + cos(y)^2*3 + x", "x + 3"},
+		{"cosh(x)^2 - sinh(x)^2", "1"},
+		{"2 + 3*4", "14"},
+		{"1/3 + 1/6", "1/2"},
+		{"0.5*x + x/2", "x"},
+		{"x*x*x", "x^3"},
+		{"x/x", "1"},
+		{"x - x", "0"},
+		{"y + x + 1 + x^2", "x^2 + x + y + 1"},
+		{"z*y*x + x*y*z", "2*x*y*z"},
+		{"sin(x)*x", "x*sin(x)"},
+		{"2*(x + 1) - 2", "2*x"},
+		{"1 - x/2", "-x/2 + 1"},
+		{"x - 3*x", "-2*x"},
+		{"-(x + 1)", "-x - 1"},
+		{"diff(x^-2, x)", "-2/x^3"},
+		{"1/x + 2/x", "3/x"},
+		{"(x + 1)*(x + 1)/(x + 1)", "x + 1"},
+		{"x^y*x^2", "x^(y + 2)"},
+		{"x^2*x^3", "x^5"},
+		{"-x + x", "0"},
+		{"x^-1*x^2", "x"},
+		{"2*sin(x)^2 + 2*cos(x)^2", "2"},
+		{"x^(1/2)*x^(1/2)", "x"},
+		{"x^2^3", "x^8"},
+		{"2*x^2/x", "2*x"},
+		{"E^ln(x) + ln(exp(x))", "2*x"},
+		{"sqrt(16) + sqrt(9/4) + lg(1000) + log(x^3, x)", "23/2"},
+		{"sin(0) + cos(0) + gcd(12, 18)*PI", "6*PI + 1"},
+		{"diff(x/(x + 1), x)", "1/(x + 1)^2"},
+		{"diff(x^2*sin(x), x)", "x^2*cos(x) + 2*x*sin(x)"},
+	}
+
+	calc := calculator.NewCalculator(10)
+	for _, test := range tests {
+		node, err := NewParser(test.input, calc).ParseSymbolic()
+		if err != nil {
+			t.Fatalf("对于输入 %s: 解析失败: %v", test.input, err)
+		}
+		res, err := Simplify(node, calc)
+		if err != nil {
+			t.Fatalf("对于输入 %s: 化简失败: %v", test.input, err)
+		}
+		if result := Infix(res); result != test.expected {
+			t.Errorf("对于输入 %s: 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+		if result := evaluateValue(t, "simplify("+test.input+")", calc).Format(calc); result != test.expected {
+			t.Errorf("对于输入 simplify(%s): 期望 %s, 得到 %s", test.input, test.expected, result)
+		}
+	}
+
+	node, err := NewParser("x/(x - x)", calc).ParseSymbolic()
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if _, err := Simplify(node, calc); !errors.Is(err, calculator.ErrDivisionByZero) {
+		t.Errorf("对于输入 x/(x - x): 期望错误 %v, 得到 %v", calculator.ErrDivisionByZero, err)
+	}
 }
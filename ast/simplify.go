@@ -0,0 +1,666 @@
+package ast
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+// SimplifyOperation 表示符号化简 simplify(expr)，expr 中的标识符均视为变量
+type SimplifyOperation struct {
+	Expr Node
+	calc *calculator.Calculator
+}
+
+// Evaluate 返回化简后的中缀表达式
+func (s *SimplifyOperation) Evaluate() (Value, error) {
+	n, err := Simplify(s.Expr, s.calc)
+	if err != nil {
+		return nil, err
+	}
+	return Expression{n}, nil
+}
+
+func (s *SimplifyOperation) Type() NodeType {
+	return SimplifyNode
+}
+
+// Simplify 化简符号表达式：折叠数字常量、合并同类项、应用 x*1、x^0、ln(E^x)、sin(x)^2 + cos(x)^2 等恒等式，
+// 并按规范顺序排列各项与各因子
+//
+// 数字常量以有理数精确计算，结果中的系数为整数或分数。表达式中的 diff 先求导再化简。
+// 不展开多项式的乘积与乘方，只把数字系数分配到和式的各项上。
+func Simplify(n Node, calc *calculator.Calculator) (Node, error) {
+	b := builder{calc}
+	expr, err := b.symbolic(n)
+	if err != nil {
+		return nil, err
+	}
+	s, err := b.simplify(expr)
+	if err != nil {
+		return nil, err
+	}
+	return b.node(s), nil
+}
+
+// ParseSymbolic 将表达式解析为符号表达式，其中除常量、函数名与关键字外的标识符均视为变量
+func (p *Parser) ParseSymbolic() (Node, error) {
+	p.free = true
+	return p.Parse()
+}
+
+// parseSimplify 解析 simplify(expr)，函数名已被读取
+func (p *Parser) parseSimplify() Node {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
+		panic(syntaxError("simplify后需要括号"))
+	}
+	p.pos++
+	free := p.free
+	p.free = true
+	expr := p.parseExpression()
+	p.free = free
+	if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+		panic(syntaxError("simplify缺少右括号"))
+	}
+	p.pos++
+	return &SimplifyOperation{Expr: expr, calc: p.calc}
+}
+
+// sum 表示化简后的和式，同类项已合并并按规范顺序排列，空和式表示 0
+type sum []term
+
+// term 表示和式中的一项 coef·f1·f2·…，因子按规范顺序排列且底数互不相同，不含因子的项为常数项
+type term struct {
+	coef    *big.Rat
+	factors []factor
+}
+
+// factor 表示项中的因子 base^exp，exp 不为零；底数为数字常量时指数不是可以折叠的整数
+type factor struct {
+	base Node
+	exp  sum
+}
+
+// ratOne 有理数 1，只读
+var ratOne = big.NewRat(1, 1)
+
+// constSum 返回常数 r 构成的和式
+func constSum(r *big.Rat) sum {
+	if r.Sign() == 0 {
+		return nil
+	}
+	return sum{{coef: r}}
+}
+
+// atom 返回单个不可再分的表达式 n 构成的和式
+func atom(n Node) sum {
+	return sum{{coef: ratOne, factors: []factor{{n, constSum(ratOne)}}}}
+}
+
+// constant 若和式为常数，返回其值
+func (s sum) constant() (*big.Rat, bool) {
+	switch {
+	case len(s) == 0:
+		return new(big.Rat), true
+	case len(s) == 1 && len(s[0].factors) == 0:
+		return s[0].coef, true
+	}
+	return nil, false
+}
+
+// isConst 判断和式是否为常数 r
+func (s sum) isConst(r *big.Rat) bool {
+	c, ok := s.constant()
+	return ok && c.Cmp(r) == 0
+}
+
+// single 若和式只有一项，返回该项；0 视为系数为 0 的项
+func (s sum) single() (term, bool) {
+	switch len(s) {
+	case 0:
+		return term{coef: new(big.Rat)}, true
+	case 1:
+		return s[0], true
+	}
+	return term{}, false
+}
+
+// atomBase 若和式为不带系数与指数的单个因子，返回其底数
+func (s sum) atomBase() (Node, bool) {
+	if len(s) == 1 && s[0].coef.Cmp(ratOne) == 0 && len(s[0].factors) == 1 && s[0].factors[0].exp.isConst(ratOne) {
+		return s[0].factors[0].base, true
+	}
+	return nil, false
+}
+
+// scale 返回和式与常数 r 之积
+func (s sum) scale(r *big.Rat) sum {
+	if r.Sign() == 0 {
+		return nil
+	}
+	res := make(sum, len(s))
+	for i, t := range s {
+		res[i] = term{new(big.Rat).Mul(t.coef, r), t.factors}
+	}
+	return res
+}
+
+// simplify 化简表达式 n，n 应已经过 symbolic 处理
+func (b builder) simplify(n Node) (sum, error) {
+	if name, operand, ok := unaryOperand(n); ok {
+		u, err := b.simplify(operand)
+		if err != nil {
+			return nil, err
+		}
+		return b.function(name, u), nil
+	}
+	switch n := n.(type) {
+	case *NumberLiteral:
+		d, err := decimal.NewFromString(n.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", calculator.ErrInvalidNumber, n.Value)
+		}
+		return constSum(d.Rat()), nil
+	case *Variable, *PIConstant, *EConstant:
+		return atom(n), nil
+	case *BinaryOperator:
+		l, err := b.simplify(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		r, err := b.simplify(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Operator {
+		case "+":
+			return b.normalize(append(slices.Clone(l), r...)), nil
+		case "-":
+			return b.normalize(append(slices.Clone(l), r.scale(big.NewRat(-1, 1))...)), nil
+		case "*":
+			return b.multiply(l, r), nil
+		case "/":
+			inv, err := b.reciprocal(r)
+			if err != nil {
+				return nil, err
+			}
+			return b.multiply(l, inv), nil
+		}
+		return nil, fmt.Errorf("%w: 不支持化简运算符 %s", calculator.ErrDomain, n.Operator)
+	case *NegOperation:
+		u, err := b.simplify(n.Operand)
+		if err != nil {
+			return nil, err
+		}
+		return u.scale(big.NewRat(-1, 1)), nil
+	case *PowOperation:
+		base, err := b.simplify(n.Base)
+		if err != nil {
+			return nil, err
+		}
+		exp, err := b.simplify(n.Exponent)
+		if err != nil {
+			return nil, err
+		}
+		return b.power(base, exp)
+	case *LogOperation:
+		v, err := b.simplify(n.Value)
+		if err != nil {
+			return nil, err
+		}
+		base, err := b.simplify(n.Base)
+		if err != nil {
+			return nil, err
+		}
+		return b.logarithm(v, base), nil
+	case *FunctionCall:
+		return b.call(n)
+	case *DiffOperation:
+		deriv, err := b.derivative(n.Expr, n.Var)
+		if err != nil {
+			return nil, err
+		}
+		if n.At != nil {
+			deriv = substitute(deriv, n.Var, n.At, b.calc)
+		}
+		return b.simplify(deriv)
+	case *SimplifyOperation:
+		return b.simplify(n.Expr)
+	}
+	return nil, fmt.Errorf("%w: 不支持化简 %s", calculator.ErrDomain, Infix(n))
+}
+
+// normalize 合并同类项、应用平方恒等式并按规范顺序排列各项
+func (b builder) normalize(terms []term) sum {
+	for {
+		index := make(map[string]int)
+		var res sum
+		for _, t := range terms {
+			k := b.monomialKey(t.factors)
+			if i, ok := index[k]; ok {
+				res[i].coef = new(big.Rat).Add(res[i].coef, t.coef)
+				continue
+			}
+			index[k] = len(res)
+			res = append(res, term{new(big.Rat).Set(t.coef), t.factors})
+		}
+		res = slices.DeleteFunc(res, func(t term) bool { return t.coef.Sign() == 0 })
+
+		var changed bool
+		if terms, changed = b.pythagorean(res); !changed {
+			slices.SortStableFunc(res, b.compareTerms)
+			return res
+		}
+	}
+}
+
+// pythagorean 将一对 c·sin(u)^2·R + c·cos(u)^2·R 合并为 c·R，c·cosh(u)^2·R - c·sinh(u)^2·R 同理
+//
+// 每次只合并一对，返回是否有改动。
+func (b builder) pythagorean(terms sum) (sum, bool) {
+	index := make(map[string]int, len(terms))
+	for i, t := range terms {
+		index[b.monomialKey(t.factors)] = i
+	}
+	for i, t := range terms {
+		for j, f := range t.factors {
+			if !f.exp.isConst(big.NewRat(2, 1)) {
+				continue
+			}
+			var partner Node
+			sign := ratOne
+			switch base := f.base.(type) {
+			case *SinOperation:
+				partner = b.fn("cos", base.Operand)
+			case *CoshOperation:
+				partner = b.fn("sinh", base.Operand)
+				sign = big.NewRat(-1, 1)
+			default:
+				continue
+			}
+			rest := slices.Delete(slices.Clone(t.factors), j, j+1)
+			other := b.sortFactors(append(slices.Clone(rest), factor{partner, f.exp}))
+			m, ok := index[b.monomialKey(other)]
+			if !ok || terms[m].coef.Cmp(new(big.Rat).Mul(t.coef, sign)) != 0 {
+				continue
+			}
+			res := slices.Clone(terms)
+			res[i] = term{t.coef, rest}
+			return slices.Delete(res, m, m+1), true
+		}
+	}
+	return terms, false
+}
+
+// multiply 返回两个和式之积，常数分配到另一个和式的各项上，其余和式作为整体参与乘积
+func (b builder) multiply(x, y sum) sum {
+	if c, ok := x.constant(); ok {
+		return y.scale(c)
+	}
+	if c, ok := y.constant(); ok {
+		return x.scale(c)
+	}
+	return b.normalize([]term{b.multiplyTerms(b.asTerm(x), b.asTerm(y))})
+}
+
+// asTerm 将和式表示为一项，多于一项时整个和式作为一个因子
+func (b builder) asTerm(s sum) term {
+	if t, ok := s.single(); ok {
+		return t
+	}
+	return term{ratOne, []factor{{b.node(s), constSum(ratOne)}}}
+}
+
+// multiplyTerms 返回两项之积，底数相同的因子合并指数，数字底数的整数次幂折叠到系数中
+func (b builder) multiplyTerms(t, u term) term {
+	coef := new(big.Rat).Mul(t.coef, u.coef)
+	factors := slices.Clone(t.factors)
+	for _, f := range u.factors {
+		k := Infix(f.base)
+		i := slices.IndexFunc(factors, func(g factor) bool { return Infix(g.base) == k })
+		if i < 0 {
+			factors = append(factors, f)
+			continue
+		}
+		factors[i] = factor{factors[i].base, b.normalize(append(slices.Clone(factors[i].exp), f.exp...))}
+	}
+
+	var res []factor
+	for _, f := range factors {
+		if len(f.exp) == 0 {
+			continue
+		}
+		if c, ok := constant(f.base); ok {
+			if e, ok := f.exp.constant(); ok {
+				if p, ok := powRat(c.Rat(), e); ok {
+					coef.Mul(coef, p)
+					continue
+				}
+			}
+		}
+		res = append(res, f)
+	}
+	return term{coef, b.sortFactors(res)}
+}
+
+// reciprocal 返回和式的倒数
+func (b builder) reciprocal(s sum) (sum, error) {
+	if c, ok := s.constant(); ok {
+		if c.Sign() == 0 {
+			return nil, calculator.ErrDivisionByZero
+		}
+		return constSum(new(big.Rat).Inv(c)), nil
+	}
+	t := b.asTerm(s)
+	factors := make([]factor, len(t.factors))
+	for i, f := range t.factors {
+		factors[i] = factor{f.base, f.exp.scale(big.NewRat(-1, 1))}
+	}
+	return sum{{new(big.Rat).Inv(t.coef), factors}}, nil
+}
+
+// power 返回 base^exp
+//
+// 整数次幂分配到项的系数与各因子上，(x^a)^b 只在 b 为整数时合并为 x^(a·b)。
+func (b builder) power(base, exp sum) (sum, error) {
+	if r, ok := exp.constant(); ok {
+		switch {
+		case r.Sign() == 0:
+			return constSum(ratOne), nil
+		case r.Cmp(ratOne) == 0:
+			return base, nil
+		case len(base) == 0 && r.Sign() < 0:
+			return nil, calculator.ErrDivisionByZero
+		case len(base) == 0:
+			return nil, nil
+		}
+		if t, ok := base.single(); ok && r.IsInt() {
+			if coef, ok := powRat(t.coef, r); ok {
+				factors := make([]factor, len(t.factors))
+				for i, f := range t.factors {
+					factors[i] = factor{f.base, f.exp.scale(r)}
+				}
+				return sum{b.multiplyTerms(term{coef: coef}, term{ratOne, factors})}, nil
+			}
+		}
+	} else if e, ok := exp.atomBase(); ok {
+		// E^ln(u) = u
+		ln, isLn := e.(*LnOperation)
+		if x, ok := base.atomBase(); ok && isLn {
+			if _, isE := x.(*EConstant); isE {
+				return b.simplify(ln.Operand)
+			}
+		}
+	}
+	if x, ok := base.atomBase(); ok {
+		return sum{{ratOne, []factor{{x, exp}}}}, nil
+	}
+	return sum{{ratOne, []factor{{b.node(base), exp}}}}, nil
+}
+
+// powRat 计算 x 的整数次幂 e，指数不是整数、绝对值超过 maxFoldedExponent 或 0 的负数次幂时返回 false
+func powRat(x, e *big.Rat) (*big.Rat, bool) {
+	if !e.IsInt() || e.Num().CmpAbs(big.NewInt(maxFoldedExponent)) > 0 || x.Sign() == 0 && e.Sign() < 0 {
+		return nil, false
+	}
+	n := e.Num()
+	abs := new(big.Int).Abs(n)
+	res := new(big.Rat).SetFrac(new(big.Int).Exp(x.Num(), abs, nil), new(big.Int).Exp(x.Denom(), abs, nil))
+	if n.Sign() < 0 {
+		res.Inv(res)
+	}
+	return res, true
+}
+
+// function 返回单参数函数 name 作用于 u 的结果，在能精确计算或满足恒等式时化简
+func (b builder) function(name string, u sum) sum {
+	if c, ok := u.constant(); ok {
+		if r, ok := exactFunction(name, c); ok {
+			return constSum(r)
+		}
+	}
+	t, single := u.single()
+	if single && t.coef.Cmp(ratOne) == 0 && len(t.factors) == 1 {
+		f := t.factors[0]
+		switch base := f.base.(type) {
+		case *EConstant:
+			// ln(E^v) = v
+			if name == "ln" {
+				return f.exp
+			}
+		case *ExpOperation:
+			// ln(exp(v)) = v
+			if name == "ln" && f.exp.isConst(ratOne) {
+				if s, err := b.simplify(base.Operand); err == nil {
+					return s
+				}
+			}
+		case *LnOperation:
+			// exp(ln(v)) = v
+			if name == "exp" && f.exp.isConst(ratOne) {
+				if s, err := b.simplify(base.Operand); err == nil {
+					return s
+				}
+			}
+		}
+	}
+	return atom(b.fn(name, b.node(u)))
+}
+
+// exactFunction 计算单参数函数在常数处能精确表示的值，如 sin(0)、ln(1)、sqrt(9/4)
+func exactFunction(name string, c *big.Rat) (*big.Rat, bool) {
+	switch {
+	case c.Sign() == 0:
+		switch name {
+		case "sqrt", "sin", "tan", "asin", "atan", "sinh", "tanh", "asinh", "atanh":
+			return new(big.Rat), true
+		case "cos", "cosh", "exp":
+			return big.NewRat(1, 1), true
+		}
+	case c.Cmp(ratOne) == 0:
+		switch name {
+		case "ln", "acos", "acosh":
+			return new(big.Rat), true
+		case "sqrt":
+			return big.NewRat(1, 1), true
+		}
+	case name == "sqrt" && c.Sign() > 0:
+		num, den := new(big.Int).Sqrt(c.Num()), new(big.Int).Sqrt(c.Denom())
+		r := new(big.Rat).SetFrac(num, den)
+		if new(big.Rat).Mul(r, r).Cmp(c) == 0 {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// logarithm 返回以 base 为底 v 的对数，log(b, b) = 1、log(b^x, b) = x，常数的整数次幂直接求出
+func (b builder) logarithm(v, base sum) sum {
+	bn := b.node(base)
+	switch {
+	case v.isConst(ratOne):
+		return nil
+	case Infix(b.node(v)) == Infix(bn):
+		return constSum(ratOne)
+	}
+	if t, ok := v.single(); ok && t.coef.Cmp(ratOne) == 0 && len(t.factors) == 1 && Infix(t.factors[0].base) == Infix(bn) {
+		return t.factors[0].exp
+	}
+	x, xok := v.constant()
+	y, yok := base.constant()
+	if xok && yok && x.Sign() > 0 && y.Sign() > 0 && y.Cmp(ratOne) != 0 {
+		for k := int64(1); k <= maxFoldedExponent; k++ {
+			p, _ := powRat(y, big.NewRat(k, 1))
+			switch {
+			case p.Cmp(x) == 0:
+				return constSum(big.NewRat(k, 1))
+			case new(big.Rat).Inv(p).Cmp(x) == 0:
+				return constSum(big.NewRat(-k, 1))
+			}
+		}
+	}
+	return atom(&LogOperation{Value: b.node(v), Base: bn, calc: b.calc})
+}
+
+// call 化简函数调用的参数，参数均为常数且结果为整数或有理数时以精确模式求值
+func (b builder) call(n *FunctionCall) (sum, error) {
+	args := make([]Node, len(n.Args))
+	numeric := true
+	for i, arg := range n.Args {
+		s, err := b.simplify(arg)
+		if err != nil {
+			return nil, err
+		}
+		_, ok := s.constant()
+		numeric = numeric && ok
+		args[i] = b.node(s)
+	}
+	call := &FunctionCall{Name: n.Name, Args: args, calc: b.calc}
+	if numeric {
+		exact := *b.calc
+		calculator.WithExact()(&exact)
+		v, err := transform(call, &exact, nil).Evaluate()
+		if err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case Integer:
+			return constSum(new(big.Rat).SetInt(v.Int)), nil
+		case Rational:
+			return constSum(v.Rat), nil
+		}
+	}
+	return atom(call), nil
+}
+
+// node 将和式转换为表达式树，负系数的项以减号连接
+func (b builder) node(s sum) Node {
+	if len(s) == 0 {
+		return b.num(decimal.Zero)
+	}
+	var res Node
+	for i, t := range s {
+		n := b.termNode(t)
+		switch {
+		case i == 0 && t.coef.Sign() < 0:
+			res = b.neg(n)
+		case i == 0:
+			res = n
+		case t.coef.Sign() < 0:
+			res = b.binary("-", res, n)
+		default:
+			res = b.binary("+", res, n)
+		}
+	}
+	return res
+}
+
+// termNode 将一项的绝对值转换为表达式树，负指数的因子与系数的分母放在除号之后
+func (b builder) termNode(t term) Node {
+	var num, den []Node
+	if p := new(big.Int).Abs(t.coef.Num()); p.Cmp(big.NewInt(1)) != 0 || len(t.factors) == 0 {
+		num = append(num, b.num(decimal.NewFromBigInt(p, 0)))
+	}
+	if q := t.coef.Denom(); q.Cmp(big.NewInt(1)) != 0 {
+		den = append(den, b.num(decimal.NewFromBigInt(q, 0)))
+	}
+	for _, f := range t.factors {
+		if c, ok := f.exp.constant(); ok && c.Sign() < 0 {
+			den = append(den, b.pow(f.base, b.node(f.exp.scale(big.NewRat(-1, 1)))))
+			continue
+		}
+		num = append(num, b.pow(f.base, b.node(f.exp)))
+	}
+	if len(num) == 0 {
+		num = append(num, b.num(one))
+	}
+	res := b.chain(num)
+	if len(den) > 0 {
+		res = b.binary("/", res, b.chain(den))
+	}
+	return res
+}
+
+// chain 返回各因子从左到右的乘积
+func (b builder) chain(factors []Node) Node {
+	res := factors[0]
+	for _, f := range factors[1:] {
+		res = b.binary("*", res, f)
+	}
+	return res
+}
+
+// monomialKey 返回各因子之积的规范字符串，用于识别同类项
+func (b builder) monomialKey(factors []factor) string {
+	keys := make([]string, len(factors))
+	for i, f := range factors {
+		keys[i] = Infix(f.base) + "^(" + Infix(b.node(f.exp)) + ")"
+	}
+	return strings.Join(keys, "*")
+}
+
+// baseRank 因子的排列顺序：常量、变量、其余表达式
+func baseRank(n Node) int {
+	switch n.(type) {
+	case *NumberLiteral, *PIConstant, *EConstant:
+		return 0
+	case *Variable:
+		return 1
+	}
+	return 2
+}
+
+// sortFactors 按底数的种类与字符串排列因子
+func (b builder) sortFactors(factors []factor) []factor {
+	slices.SortStableFunc(factors, func(f, g factor) int {
+		return cmp.Or(cmp.Compare(baseRank(f.base), baseRank(g.base)), strings.Compare(Infix(f.base), Infix(g.base)))
+	})
+	return factors
+}
+
+// degree 返回项中各变量的常数指数之和
+func degree(t term) *big.Rat {
+	d := new(big.Rat)
+	for _, f := range t.factors {
+		if _, ok := f.base.(*Variable); ok {
+			if e, ok := f.exp.constant(); ok {
+				d.Add(d, e)
+			}
+		}
+	}
+	return d
+}
+
+// compareTerms 各项的规范顺序：按变量的次数从高到低，同次的项按因子逐个比较，常数项在最后
+func (b builder) compareTerms(t, u term) int {
+	if tc, uc := len(t.factors) == 0, len(u.factors) == 0; tc != uc {
+		if tc {
+			return 1
+		}
+		return -1
+	}
+	if c := degree(u).Cmp(degree(t)); c != 0 {
+		return c
+	}
+	for i := range min(len(t.factors), len(u.factors)) {
+		f, g := t.factors[i], u.factors[i]
+		if c := cmp.Or(cmp.Compare(baseRank(f.base), baseRank(g.base)), strings.Compare(Infix(f.base), Infix(g.base))); c != 0 {
+			return c
+		}
+		x, xok := f.exp.constant()
+		y, yok := g.exp.constant()
+		if xok && yok {
+			if c := y.Cmp(x); c != 0 {
+				return c
+			}
+		} else if c := strings.Compare(Infix(b.node(f.exp)), Infix(b.node(g.exp))); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(t.factors), len(u.factors))
+}
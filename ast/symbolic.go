@@ -0,0 +1,544 @@
+package ast
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+// Variable 表示符号表达式中的变量，如 diff(x^2, x) 中的 x
+type Variable struct {
+	Name string
+}
+
+func (v *Variable) Evaluate() (Value, error) {
+	return nil, fmt.Errorf("%w: 变量 %s 没有值", calculator.ErrDomain, v.Name)
+}
+
+func (v *Variable) Type() NodeType {
+	return VariableNode
+}
+
+// Expression 表示符号运算的结果，输出为中缀表达式
+type Expression struct {
+	Node
+}
+
+func (e Expression) Format(calc *calculator.Calculator) string {
+	return Infix(e.Node)
+}
+
+// variablePattern 变量名的格式：以字母或下划线开头，由字母、数字和下划线组成
+var variablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reservedNames 解析器中有特殊含义、不能用作变量名的标识符
+var reservedNames = map[string]bool{
+	"PI": true, "E": true, "i": true, "in": true, "xor": true, "diff": true, "simplify": true, "interval": true,
+	"sqrt": true, "sin": true, "cos": true, "tan": true, "asin": true, "acos": true, "atan": true,
+	"log": true, "lg": true, "ln": true, "exp": true,
+	"sinh": true, "cosh": true, "tanh": true, "coth": true, "sech": true, "csch": true,
+	"asinh": true, "acosh": true, "atanh": true,
+}
+
+// isVariableName 判断 name 能否用作变量名
+func isVariableName(name string) bool {
+	_, isFunction := functions[name]
+	return variablePattern.MatchString(name) && !reservedNames[name] && !isFunction
+}
+
+// 中缀输出时的优先级，与解析器一致：加减低于乘除，乘除低于前缀负号，前缀负号低于右结合的乘方
+const (
+	sumLevel    = iota // 加减
+	termLevel          // 乘、除
+	unaryLevel         // 前缀负号
+	powerLevel         // 乘方
+	factorLevel        // 数字、变量、常量与函数调用
+)
+
+// Infix 将符号表达式输出为可以重新解析的中缀表达式，只在必要时添加括号
+//
+// 负数与取反输出为 -x 的形式。
+func Infix(n Node) string {
+	s, _ := infix(n)
+	return s
+}
+
+// wrap 输出 n，优先级低于 level 时加上括号
+func wrap(n Node, level int) string {
+	s, l := infix(n)
+	if l < level {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// infix 输出 n 并返回其优先级
+func infix(n Node) (string, int) {
+	if name, operand, ok := unaryOperand(n); ok {
+		return name + "(" + Infix(operand) + ")", factorLevel
+	}
+	switch n := n.(type) {
+	case *NumberLiteral:
+		if strings.HasPrefix(n.Value, "-") {
+			return n.Value, unaryLevel
+		}
+		return n.Value, factorLevel
+	case *Variable:
+		return n.Name, factorLevel
+	case *PIConstant:
+		return "PI", factorLevel
+	case *EConstant:
+		return "E", factorLevel
+	case *BinaryOperator:
+		switch n.Operator {
+		case "+":
+			return wrap(n.Left, sumLevel) + " + " + wrap(n.Right, sumLevel), sumLevel
+		case "-":
+			return wrap(n.Left, sumLevel) + " - " + wrap(n.Right, termLevel), sumLevel
+		}
+		// 乘法满足结合律，右侧的乘除不必加括号
+		right := powerLevel
+		if b, ok := n.Right.(*BinaryOperator); ok && n.Operator == "*" && (b.Operator == "*" || b.Operator == "/") {
+			right = termLevel
+		}
+		return wrap(n.Left, termLevel) + n.Operator + wrap(n.Right, right), termLevel
+	case *NegOperation:
+		// -(a*b) 与 (-a)*b 相等，乘除不必加括号
+		s, l := infix(n.Operand)
+		if l < termLevel {
+			s, l = "("+s+")", factorLevel
+		}
+		return "-" + s, min(l, unaryLevel)
+	case *PowOperation:
+		// 乘方右结合，指数中的乘方不必加括号
+		return wrap(n.Base, factorLevel) + "^" + wrap(n.Exponent, powerLevel), powerLevel
+	case *LogOperation:
+		if base, ok := n.Base.(*NumberLiteral); ok && base.Value == "10" {
+			return "lg(" + Infix(n.Value) + ")", factorLevel
+		}
+		return "log(" + Infix(n.Value) + ", " + Infix(n.Base) + ")", factorLevel
+	case *FunctionCall:
+		args := make([]string, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = Infix(arg)
+		}
+		return n.Name + "(" + strings.Join(args, ", ") + ")", factorLevel
+	case *DiffOperation:
+		s := "diff(" + Infix(n.Expr) + ", " + n.Var
+		if n.At != nil {
+			s += ", " + Infix(n.At)
+		}
+		return s + ")", factorLevel
+	case *SimplifyOperation:
+		return "simplify(" + Infix(n.Expr) + ")", factorLevel
+	}
+	return fmt.Sprint(n), factorLevel
+}
+
+// unaryOperand 返回单参数函数节点的函数名与操作数
+func unaryOperand(n Node) (string, Node, bool) {
+	switch n := n.(type) {
+	case *SqrtOperation:
+		return "sqrt", n.Operand, true
+	case *SinOperation:
+		return "sin", n.Operand, true
+	case *CosOperation:
+		return "cos", n.Operand, true
+	case *TanOperation:
+		return "tan", n.Operand, true
+	case *AsinOperation:
+		return "asin", n.Operand, true
+	case *AcosOperation:
+		return "acos", n.Operand, true
+	case *AtanOperation:
+		return "atan", n.Operand, true
+	case *LnOperation:
+		return "ln", n.Operand, true
+	case *ExpOperation:
+		return "exp", n.Operand, true
+	case *SinhOperation:
+		return "sinh", n.Operand, true
+	case *CoshOperation:
+		return "cosh", n.Operand, true
+	case *TanhOperation:
+		return "tanh", n.Operand, true
+	case *CothOperation:
+		return "coth", n.Operand, true
+	case *SechOperation:
+		return "sech", n.Operand, true
+	case *CschOperation:
+		return "csch", n.Operand, true
+	case *AsinhOperation:
+		return "asinh", n.Operand, true
+	case *AcoshOperation:
+		return "acosh", n.Operand, true
+	case *AtanhOperation:
+		return "atanh", n.Operand, true
+	}
+	return "", nil, false
+}
+
+// newUnary 按函数名创建单参数函数节点，name 必须是 unaryOperand 返回的函数名之一
+func newUnary(name string, operand Node, calc *calculator.Calculator) Node {
+	switch name {
+	case "sqrt":
+		return &SqrtOperation{Operand: operand, calc: calc}
+	case "sin":
+		return &SinOperation{Operand: operand, calc: calc}
+	case "cos":
+		return &CosOperation{Operand: operand, calc: calc}
+	case "tan":
+		return &TanOperation{Operand: operand, calc: calc}
+	case "asin":
+		return &AsinOperation{Operand: operand, calc: calc}
+	case "acos":
+		return &AcosOperation{Operand: operand, calc: calc}
+	case "atan":
+		return &AtanOperation{Operand: operand, calc: calc}
+	case "ln":
+		return &LnOperation{Operand: operand, calc: calc}
+	case "exp":
+		return &ExpOperation{Operand: operand, calc: calc}
+	case "sinh":
+		return &SinhOperation{Operand: operand, calc: calc}
+	case "cosh":
+		return &CoshOperation{Operand: operand, calc: calc}
+	case "tanh":
+		return &TanhOperation{Operand: operand, calc: calc}
+	case "coth":
+		return &CothOperation{Operand: operand, calc: calc}
+	case "sech":
+		return &SechOperation{Operand: operand, calc: calc}
+	case "csch":
+		return &CschOperation{Operand: operand, calc: calc}
+	case "asinh":
+		return &AsinhOperation{Operand: operand, calc: calc}
+	case "acosh":
+		return &AcoshOperation{Operand: operand, calc: calc}
+	case "atanh":
+		return &AtanhOperation{Operand: operand, calc: calc}
+	}
+	panic("未知的函数: " + name)
+}
+
+// operands 返回符号运算支持的节点的子节点，叶子节点和不支持的节点返回 nil
+func operands(n Node) []Node {
+	if _, operand, ok := unaryOperand(n); ok {
+		return []Node{operand}
+	}
+	switch n := n.(type) {
+	case *BinaryOperator:
+		return []Node{n.Left, n.Right}
+	case *NegOperation:
+		return []Node{n.Operand}
+	case *PowOperation:
+		return []Node{n.Base, n.Exponent}
+	case *LogOperation:
+		return []Node{n.Value, n.Base}
+	case *FunctionCall:
+		return n.Args
+	case *DiffOperation:
+		if n.At != nil {
+			return []Node{n.Expr, n.At}
+		}
+		return []Node{n.Expr}
+	case *SimplifyOperation:
+		return []Node{n.Expr}
+	}
+	return nil
+}
+
+// rebuild 返回与 n 同类、子节点为 args 并使用计算器 calc 的新节点
+func rebuild(n Node, args []Node, calc *calculator.Calculator) Node {
+	if name, _, ok := unaryOperand(n); ok {
+		return newUnary(name, args[0], calc)
+	}
+	switch n := n.(type) {
+	case *BinaryOperator:
+		return &BinaryOperator{Left: args[0], Right: args[1], Operator: n.Operator, calc: calc}
+	case *NegOperation:
+		return &NegOperation{Operand: args[0], calc: calc}
+	case *PowOperation:
+		return &PowOperation{Base: args[0], Exponent: args[1], calc: calc}
+	case *LogOperation:
+		return &LogOperation{Value: args[0], Base: args[1], calc: calc}
+	case *FunctionCall:
+		return &FunctionCall{Name: n.Name, Args: args, calc: calc}
+	case *DiffOperation:
+		d := &DiffOperation{Expr: args[0], Var: n.Var, calc: calc}
+		if len(args) > 1 {
+			d.At = args[1]
+		}
+		return d
+	case *SimplifyOperation:
+		return &SimplifyOperation{Expr: args[0], calc: calc}
+	case *NumberLiteral:
+		return &NumberLiteral{Value: n.Value, calc: calc}
+	case *PIConstant:
+		return &PIConstant{calc: calc}
+	case *EConstant:
+		return &EConstant{calc: calc}
+	}
+	return n
+}
+
+// transform 自底向上以计算器 calc 重建表达式树，叶子节点先经 leaf 替换，leaf 为 nil 时保持不变
+func transform(n Node, calc *calculator.Calculator, leaf func(Node) Node) Node {
+	args := operands(n)
+	if args == nil {
+		if leaf != nil {
+			if r := leaf(n); r != n {
+				return r
+			}
+		}
+		return rebuild(n, nil, calc)
+	}
+	res := make([]Node, len(args))
+	for i, arg := range args {
+		res[i] = transform(arg, calc, leaf)
+	}
+	return rebuild(n, res, calc)
+}
+
+// substitute 将表达式中的变量 name 替换为 value
+func substitute(n Node, name string, value Node, calc *calculator.Calculator) Node {
+	return transform(n, calc, func(leaf Node) Node {
+		if v, ok := leaf.(*Variable); ok && v.Name == name {
+			return value
+		}
+		return leaf
+	})
+}
+
+// dependsOn 判断表达式是否含有变量 name
+func dependsOn(n Node, name string) bool {
+	if v, ok := n.(*Variable); ok {
+		return v.Name == name
+	}
+	for _, arg := range operands(n) {
+		if dependsOn(arg, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSymbolic 判断节点本身能否参与符号运算并按中缀形式输出
+func isSymbolic(n Node) bool {
+	switch n.(type) {
+	case *NumberLiteral, *Variable, *PIConstant, *EConstant:
+		return true
+	}
+	return operands(n) != nil
+}
+
+// builder 构造符号表达式节点，构造时折叠数字常量并化简 0、1 等平凡情形
+type builder struct {
+	calc *calculator.Calculator
+}
+
+// symbolic 将表达式中不能参与符号运算的子树（如 0xFF、5!）求值为数字常量
+func (b builder) symbolic(n Node) (Node, error) {
+	if !isSymbolic(n) {
+		v, err := n.Evaluate()
+		if err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case Integer:
+			return b.num(decimal.NewFromBigInt(v.Int, 0)), nil
+		case Real:
+			return b.num(v.Decimal), nil
+		case Rational:
+			return b.div(b.num(decimal.NewFromBigInt(v.Num(), 0)), b.num(decimal.NewFromBigInt(v.Denom(), 0))), nil
+		}
+		return nil, fmt.Errorf("%w: 符号运算不支持 %s", calculator.ErrDomain, v.Format(b.calc))
+	}
+	args := operands(n)
+	res := make([]Node, len(args))
+	for i, arg := range args {
+		r, err := b.symbolic(arg)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = r
+	}
+	if _, ok := n.(*NegOperation); ok {
+		// 负号经 neg 构造，-2 折叠为数字常量
+		return b.neg(res[0]), nil
+	}
+	return rebuild(n, res, b.calc), nil
+}
+
+// num 返回数字常量节点
+func (b builder) num(d decimal.Decimal) Node {
+	return &NumberLiteral{Value: d.String(), calc: b.calc}
+}
+
+// constant 返回数字常量节点的值
+func constant(n Node) (decimal.Decimal, bool) {
+	if l, ok := n.(*NumberLiteral); ok {
+		d, err := decimal.NewFromString(l.Value)
+		return d, err == nil
+	}
+	return decimal.Zero, false
+}
+
+// isConstant 判断 n 是否为值等于 v 的数字常量
+func isConstant(n Node, v int64) bool {
+	d, ok := constant(n)
+	return ok && d.Equal(decimal.NewFromInt(v))
+}
+
+// negated 若 n 为负数常量或 -x 的形式，返回其相反数
+func (b builder) negated(n Node) (Node, bool) {
+	if d, ok := constant(n); ok && d.IsNegative() {
+		return b.num(d.Neg()), true
+	}
+	if op, ok := n.(*NegOperation); ok {
+		return op.Operand, true
+	}
+	return nil, false
+}
+
+// binary 返回二元运算节点
+func (b builder) binary(op string, l, r Node) Node {
+	return &BinaryOperator{Left: l, Right: r, Operator: op, calc: b.calc}
+}
+
+// add 返回 l + r
+func (b builder) add(l, r Node) Node {
+	x, lok := constant(l)
+	y, rok := constant(r)
+	switch {
+	case lok && rok:
+		return b.num(x.Add(y))
+	case lok && x.IsZero():
+		return r
+	case rok && y.IsZero():
+		return l
+	}
+	if n, ok := b.negated(r); ok {
+		return b.sub(l, n)
+	}
+	if n, ok := b.negated(l); ok {
+		return b.sub(r, n)
+	}
+	return b.binary("+", l, r)
+}
+
+// sub 返回 l - r
+func (b builder) sub(l, r Node) Node {
+	x, lok := constant(l)
+	y, rok := constant(r)
+	switch {
+	case lok && rok:
+		return b.num(x.Sub(y))
+	case rok && y.IsZero():
+		return l
+	case lok && x.IsZero():
+		return b.neg(r)
+	}
+	if n, ok := b.negated(r); ok {
+		return b.add(l, n)
+	}
+	return b.binary("-", l, r)
+}
+
+// neg 返回 -n
+func (b builder) neg(n Node) Node {
+	if d, ok := constant(n); ok {
+		return b.num(d.Neg())
+	}
+	if m, ok := b.negated(n); ok {
+		return m
+	}
+	return &NegOperation{Operand: n, calc: b.calc}
+}
+
+// mul 返回 l * r，数字因子移到最前并合并，负号提到乘积之外
+func (b builder) mul(l, r Node) Node {
+	x, lok := constant(l)
+	y, rok := constant(r)
+	switch {
+	case lok && rok:
+		return b.num(x.Mul(y))
+	case lok && x.IsZero(), rok && y.IsZero():
+		return b.num(decimal.Zero)
+	case lok && x.Equal(one):
+		return r
+	case rok && y.Equal(one):
+		return l
+	}
+	if n, ok := b.negated(l); ok {
+		return b.neg(b.mul(n, r))
+	}
+	if n, ok := b.negated(r); ok {
+		return b.neg(b.mul(l, n))
+	}
+	if rok {
+		return b.mul(r, l)
+	}
+	if op, ok := r.(*BinaryOperator); ok && lok && op.Operator == "*" {
+		if z, ok := constant(op.Left); ok {
+			return b.mul(b.num(x.Mul(z)), op.Right)
+		}
+	}
+	return b.binary("*", l, r)
+}
+
+// div 返回 l / r，数字常量只在能整除时折叠
+func (b builder) div(l, r Node) Node {
+	x, lok := constant(l)
+	y, rok := constant(r)
+	switch {
+	case rok && y.Equal(one):
+		return l
+	case lok && x.IsZero():
+		return b.num(decimal.Zero)
+	case lok && rok && !y.IsZero() && x.Mod(y).IsZero():
+		return b.num(x.Div(y))
+	}
+	if n, ok := b.negated(l); ok {
+		return b.neg(b.div(n, r))
+	}
+	if n, ok := b.negated(r); ok {
+		return b.neg(b.div(l, n))
+	}
+	return b.binary("/", l, r)
+}
+
+// maxFoldedExponent 构造乘方时折叠数字常量允许的最大指数
+const maxFoldedExponent = 64
+
+// pow 返回 base^exponent，整数常量的较小非负整数次幂直接折叠
+func (b builder) pow(base, exponent Node) Node {
+	x, bok := constant(base)
+	y, eok := constant(exponent)
+	switch {
+	case eok && y.IsZero():
+		return b.num(one)
+	case eok && y.Equal(one):
+		return base
+	case bok && x.Equal(one):
+		return base
+	case bok && eok && x.IsInteger() && y.IsInteger() && !y.IsNegative() && y.IntPart() <= maxFoldedExponent:
+		return b.num(decimal.NewFromBigInt(new(big.Int).Exp(x.BigInt(), y.BigInt(), nil), 0))
+	}
+	return &PowOperation{Base: base, Exponent: exponent, calc: b.calc}
+}
+
+// fn 返回单参数函数节点
+func (b builder) fn(name string, operand Node) Node {
+	return newUnary(name, operand, b.calc)
+}
+
+// 构造符号表达式时常用的数字常量
+var (
+	one = decimal.NewFromInt(1)
+	two = decimal.NewFromInt(2)
+)
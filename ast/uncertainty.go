@@ -0,0 +1,248 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+// Interval 表示区间运算模式下的测量值，输出为 中点 ± 半径
+type Interval struct {
+	calculator.Interval
+}
+
+func (x Interval) Format(calc *calculator.Calculator) string {
+	return calc.FormatInterval(x.Interval)
+}
+
+// Measurement 表示高斯误差传播模式下的测量值，输出为 值 ± 标准不确定度
+type Measurement struct {
+	calculator.Uncertain
+}
+
+func (m Measurement) Format(calc *calculator.Calculator) string {
+	return calc.FormatUncertain(m.Uncertain)
+}
+
+// isMeasured 判断 v 是否为带不确定度的测量值
+func isMeasured(v Value) bool {
+	switch v.(type) {
+	case Interval, Measurement:
+		return true
+	}
+	return false
+}
+
+// intervalResult 将区间计算结果包装为 Value
+func intervalResult(x calculator.Interval, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return Interval{x}, nil
+}
+
+// uncertainResult 将误差传播的计算结果包装为 Value
+func uncertainResult(u calculator.Uncertain, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return Measurement{u}, nil
+}
+
+// toInterval 将区间或实数转换为区间，实数视为只包含一个点的区间
+func toInterval(v Value) (calculator.Interval, error) {
+	switch v := v.(type) {
+	case Interval:
+		return v.Interval, nil
+	case Real:
+		return calculator.PointInterval(v.Decimal), nil
+	}
+	return calculator.Interval{}, measuredOperandError(v)
+}
+
+// toUncertain 将测量值或实数转换为测量值，实数的不确定度为零
+func toUncertain(v Value) (calculator.Uncertain, error) {
+	switch v := v.(type) {
+	case Measurement:
+		return v.Uncertain, nil
+	case Real:
+		return calculator.Uncertain{Value: v.Decimal}, nil
+	}
+	return calculator.Uncertain{}, measuredOperandError(v)
+}
+
+// measuredOperandError 返回不能与测量值一起运算的操作数的错误
+func measuredOperandError(v Value) error {
+	if _, ok := v.(Complex); ok {
+		return fmt.Errorf("%w: 带不确定度的测量值不能与复数运算", calculator.ErrDomain)
+	}
+	return fmt.Errorf("%w: 带不确定度的测量值只能与实数运算", calculator.ErrDomain)
+}
+
+// measuredBinary 按计算器的不确定度模式计算两个操作数的运算，整数和有理数操作数应已转换为实数
+func measuredBinary(calc *calculator.Calculator, a, b Value,
+	ifn func(calculator.Interval, calculator.Interval) (calculator.Interval, error),
+	ufn func(calculator.Uncertain, calculator.Uncertain) (calculator.Uncertain, error)) (Value, error) {
+	if calc.Uncertainty() == calculator.GaussianPropagation {
+		x, err := toUncertain(a)
+		if err != nil {
+			return nil, err
+		}
+		y, err := toUncertain(b)
+		if err != nil {
+			return nil, err
+		}
+		return uncertainResult(ufn(x, y))
+	}
+	x, err := toInterval(a)
+	if err != nil {
+		return nil, err
+	}
+	y, err := toInterval(b)
+	if err != nil {
+		return nil, err
+	}
+	return intervalResult(ifn(x, y))
+}
+
+// measuredUnary 按计算器的不确定度模式计算单个测量值的函数
+func measuredUnary(calc *calculator.Calculator, v Value,
+	ifn func(calculator.Interval) (calculator.Interval, error),
+	ufn func(calculator.Uncertain) (calculator.Uncertain, error)) (Value, error) {
+	if calc.Uncertainty() == calculator.GaussianPropagation {
+		x, err := toUncertain(v)
+		if err != nil {
+			return nil, err
+		}
+		return uncertainResult(ufn(x))
+	}
+	x, err := toInterval(v)
+	if err != nil {
+		return nil, err
+	}
+	return intervalResult(ifn(x))
+}
+
+// measuredArithmetic 执行带不确定度的四则运算，整数和有理数操作数应已转换为实数
+func measuredArithmetic(calc *calculator.Calculator, op string, left, right Value) (Value, error) {
+	switch op {
+	case "+":
+		return measuredBinary(calc, left, right, calc.IAdd, calc.UAdd)
+	case "-":
+		return measuredBinary(calc, left, right, calc.ISubtract, calc.USubtract)
+	case "*":
+		return measuredBinary(calc, left, right, calc.IMultiply, calc.UMultiply)
+	case "/":
+		return measuredBinary(calc, left, right, calc.IDivide, calc.UDivide)
+	}
+	return nil, fmt.Errorf("未知的运算符: %s", op)
+}
+
+// applyMeasured 对操作数求值，测量值按不确定度模式调用 ifn 或 ufn，其余按 applyUnary 的规则计算
+func applyMeasured(calc *calculator.Calculator, operand Node,
+	real func(string) (decimal.Decimal, error), cplx func(calculator.Complex) (calculator.Complex, error),
+	ifn func(calculator.Interval) (calculator.Interval, error),
+	ufn func(calculator.Uncertain) (calculator.Uncertain, error)) (Value, error) {
+	v, err := operand.Evaluate()
+	if err != nil {
+		return nil, err
+	}
+	if isMeasured(v) {
+		return measuredUnary(calc, v, ifn, ufn)
+	}
+	return unaryValue(calc, v, real, cplx)
+}
+
+// PlusMinusLiteral 表示测量值 v±u，如 3.00±0.05
+type PlusMinusLiteral struct {
+	Value       Node
+	Uncertainty Node
+	calc        *calculator.Calculator
+}
+
+func (p *PlusMinusLiteral) Evaluate() (Value, error) {
+	v, err := evaluateReal(p.calc, p.Value, "±")
+	if err != nil {
+		return nil, err
+	}
+	u, err := evaluateReal(p.calc, p.Uncertainty, "±")
+	if err != nil {
+		return nil, err
+	}
+	if p.calc.Uncertainty() == calculator.GaussianPropagation {
+		return uncertainResult(calculator.NewUncertain(v, u))
+	}
+	return intervalResult(calculator.PlusMinus(v, u))
+}
+
+func (p *PlusMinusLiteral) Type() NodeType {
+	return PlusMinusNode
+}
+
+// IntervalLiteral 表示区间 interval(lo, hi)，高斯误差传播模式下视为 中点 ± 半径
+type IntervalLiteral struct {
+	Lo   Node
+	Hi   Node
+	calc *calculator.Calculator
+}
+
+func (i *IntervalLiteral) Evaluate() (Value, error) {
+	lo, err := evaluateReal(i.calc, i.Lo, "区间")
+	if err != nil {
+		return nil, err
+	}
+	hi, err := evaluateReal(i.calc, i.Hi, "区间")
+	if err != nil {
+		return nil, err
+	}
+	x, err := calculator.NewInterval(lo, hi)
+	if err != nil {
+		return nil, err
+	}
+	if i.calc.Uncertainty() == calculator.GaussianPropagation {
+		half := decimal.New(5, -1)
+		return uncertainResult(calculator.NewUncertain(lo.Add(hi).Mul(half), hi.Sub(lo).Mul(half)))
+	}
+	return Interval{x}, nil
+}
+
+func (i *IntervalLiteral) Type() NodeType {
+	return IntervalNode
+}
+
+// evaluateReal 对节点求值并要求结果为实数，name 用于错误信息
+func evaluateReal(calc *calculator.Calculator, n Node, name string) (decimal.Decimal, error) {
+	v, err := n.Evaluate()
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return toReal(toDecimal(calc, v), name)
+}
+
+// parsePlusMinus 解析 ± 之后的不确定度，± 已被读取
+func (p *Parser) parsePlusMinus(value Node) Node {
+	if p.pos >= len(p.tokens) {
+		panic(syntaxError("±后需要不确定度"))
+	}
+	return &PlusMinusLiteral{Value: value, Uncertainty: p.parseFactor(), calc: p.calc}
+}
+
+// parseInterval 解析 interval(lo, hi)，函数名已被读取
+func (p *Parser) parseInterval() Node {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos] != "(" {
+		panic(syntaxError("interval后需要括号"))
+	}
+	p.pos++
+	lo := p.parseExpression()
+	if p.pos >= len(p.tokens) || p.tokens[p.pos] != "," {
+		panic(syntaxError("区间的上下界需要用逗号分隔"))
+	}
+	p.pos++
+	hi := p.parseExpression()
+	if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+		panic(syntaxError("interval缺少右括号"))
+	}
+	p.pos++
+	return &IntervalLiteral{Lo: lo, Hi: hi, calc: p.calc}
+}
@@ -0,0 +1,266 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+// Quantity 表示带单位的物理量，Value 为以 Unit 为单位的数值
+//
+// 无量纲的结果（如 km / m）直接使用 Real 表示。
+type Quantity struct {
+	Value decimal.Decimal
+	Unit  calculator.Unit
+}
+
+func (q Quantity) Format(calc *calculator.Calculator) string {
+	return calc.Format(q.Value) + " " + q.Unit.Name
+}
+
+// quantityValue 将以 unit 为单位的数值包装为 Value，无量纲时退化为实数
+func quantityValue(value decimal.Decimal, unit calculator.Unit) Value {
+	if unit.Dim.IsZero() {
+		return Real{value}
+	}
+	return Quantity{Value: value, Unit: unit}
+}
+
+// quantityResult 将带单位的计算结果包装为 Value
+func quantityResult(value decimal.Decimal, unit calculator.Unit, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return quantityValue(value, unit), nil
+}
+
+// toQuantity 将实数视为无量纲的量，复数不能带单位
+func toQuantity(v Value) (Quantity, error) {
+	switch v := v.(type) {
+	case Quantity:
+		return v, nil
+	case Real:
+		return Quantity{Value: v.Decimal, Unit: calculator.SIUnit(calculator.Dimension{})}, nil
+	case Complex:
+		return Quantity{}, fmt.Errorf("%w: 复数不能带单位", calculator.ErrUnitMismatch)
+	case Interval, Measurement:
+		return Quantity{}, fmt.Errorf("%w: 带不确定度的测量值不能带单位", calculator.ErrUnitMismatch)
+	}
+	return Quantity{}, fmt.Errorf("%w: 带单位的量只能与实数运算", calculator.ErrUnitMismatch)
+}
+
+// toSI 将量换算为国际单位制单位
+func toSI(calc *calculator.Calculator, q Quantity) Quantity {
+	unit := calculator.SIUnit(q.Unit.Dim)
+	value, _ := calc.ConvertUnit(q.Value, q.Unit, unit)
+	return Quantity{Value: value, Unit: unit}
+}
+
+// quantityArithmetic 执行带单位的四则运算，整数和有理数操作数应已转换为实数
+//
+// 加减要求量纲相同，单位相同时保留该单位，否则换算为国际单位制；零可以与任意单位的量相加减，如 0 - 5 m。
+// 数与量相乘、量除以数时保留量的单位，两个量相乘除时换算为国际单位制并合并量纲。
+func quantityArithmetic(calc *calculator.Calculator, op string, left, right Value) (Value, error) {
+	a, err := toQuantity(left)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toQuantity(right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "+", "-":
+		if a.Unit.Dim.IsZero() && a.Value.IsZero() {
+			a.Unit = b.Unit
+		} else if b.Unit.Dim.IsZero() && b.Value.IsZero() {
+			b.Unit = a.Unit
+		}
+		if a.Unit.Dim != b.Unit.Dim {
+			return nil, fmt.Errorf("%w: %s 与 %s 不能相加减", calculator.ErrUnitMismatch, a.Unit.Name, b.Unit.Name)
+		}
+		if a.Unit.Name != b.Unit.Name {
+			a, b = toSI(calc, a), toSI(calc, b)
+		}
+		f := calc.Add
+		if op == "-" {
+			f = calc.Subtract
+		}
+		res, err := f(a.Value.String(), b.Value.String())
+		return quantityResult(res, a.Unit, err)
+
+	case "*", "/":
+		f := calc.Multiply
+		if op == "/" {
+			f = calc.Divide
+		}
+		var unit calculator.Unit
+		switch {
+		case b.Unit.Dim.IsZero():
+			unit = a.Unit
+		case a.Unit.Dim.IsZero() && op == "*":
+			unit = b.Unit
+		default:
+			a, b = toSI(calc, a), toSI(calc, b)
+			dim := a.Unit.Dim.Mul(b.Unit.Dim)
+			if op == "/" {
+				dim = a.Unit.Dim.Div(b.Unit.Dim)
+			}
+			unit = calculator.SIUnit(dim)
+		}
+		res, err := f(a.Value.String(), b.Value.String())
+		return quantityResult(res, unit, err)
+	}
+	return nil, fmt.Errorf("未知的运算符: %s", op)
+}
+
+// maxUnitPower 乘方时保留原单位的最大指数
+const maxUnitPower = 100
+
+// quantityPower 计算带单位的量的乘方，整数次幂保留原单位，如 (5 km)^2 = 25 km^2
+//
+// 其他指数要求结果的量纲仍为整数次，如 (4 m^2)^0.5 = 2 m，结果换算为国际单位制。
+func quantityPower(calc *calculator.Calculator, q Quantity, exponent Value) (Value, error) {
+	e, ok := toDecimal(calc, exponent).(Real)
+	if !ok {
+		return nil, fmt.Errorf("%w: 指数必须为无单位的实数", calculator.ErrUnitMismatch)
+	}
+	if e.IsInteger() && e.Abs().LessThanOrEqual(decimal.NewFromInt(maxUnitPower)) {
+		res, err := calc.Power(q.Value.String(), e.String())
+		return quantityResult(res, q.Unit.Pow(int(e.IntPart())), err)
+	}
+	dim, ok := q.Unit.Dim.Pow(e.Decimal)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s 的 %s 次幂没有对应的单位", calculator.ErrUnitMismatch, q.Unit.Name, e)
+	}
+	si := toSI(calc, q)
+	res, err := calc.Power(si.Value.String(), e.String())
+	return quantityResult(res, calculator.SIUnit(dim), err)
+}
+
+// quantitySqrt 计算带单位的量的平方根，要求各基本量的指数均为偶数，结果换算为国际单位制
+func quantitySqrt(calc *calculator.Calculator, q Quantity) (Value, error) {
+	dim, ok := q.Unit.Dim.Pow(decimal.New(5, -1))
+	if !ok {
+		return nil, fmt.Errorf("%w: %s 的平方根没有对应的单位", calculator.ErrUnitMismatch, q.Unit.Name)
+	}
+	si := toSI(calc, q)
+	res, err := calc.Sqrt(si.Value.String())
+	return quantityResult(res, calculator.SIUnit(dim), err)
+}
+
+// UnitLiteral 表示单位常量，如 km、m/s^2，求值结果为 1 个该单位
+type UnitLiteral struct {
+	Unit calculator.Unit
+}
+
+func (u *UnitLiteral) Evaluate() (Value, error) {
+	return quantityValue(decimal.NewFromInt(1), u.Unit), nil
+}
+
+func (u *UnitLiteral) Type() NodeType {
+	return UnitNode
+}
+
+// ConvertOperation 表示单位换算，如 5 km + 300 m in mi
+type ConvertOperation struct {
+	Operand Node
+	Unit    calculator.Unit
+	calc    *calculator.Calculator
+}
+
+func (c *ConvertOperation) Evaluate() (Value, error) {
+	v, err := c.Operand.Evaluate()
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := v.(Money); ok {
+		return nil, fmt.Errorf("%w: %s 金额不能换算为单位 %s", calculator.ErrUnitMismatch, m.Currency, c.Unit.Name)
+	}
+	q, err := toQuantity(toDecimal(c.calc, v))
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.calc.ConvertUnit(q.Value, q.Unit, c.Unit)
+	if err != nil {
+		return nil, err
+	}
+	return Quantity{Value: res, Unit: c.Unit}, nil
+}
+
+func (c *ConvertOperation) Type() NodeType {
+	return ConvertNode
+}
+
+// isUnitToken 判断下标为 i 的标记是否为单位名称
+//
+// 与函数同名的单位（如 min）后面紧跟括号时按函数处理。
+func (p *Parser) isUnitToken(i int) bool {
+	if i >= len(p.tokens) {
+		return false
+	}
+	if _, ok := functions[p.tokens[i]]; ok && i+1 < len(p.tokens) && p.tokens[i+1] == "(" {
+		return false
+	}
+	_, ok := calculator.LookupUnit(p.tokens[i])
+	return ok
+}
+
+// parseUnit 解析由单位名称、整数次幂和 * / 组成的单位，如 kg*m^2/s^2
+func (p *Parser) parseUnit() calculator.Unit {
+	unit := p.parseUnitPower()
+	for p.pos < len(p.tokens) && (p.tokens[p.pos] == "*" || p.tokens[p.pos] == "/") && p.isUnitToken(p.pos+1) {
+		op := p.tokens[p.pos]
+		p.pos++
+		next := p.parseUnitPower()
+		if op == "*" {
+			unit = unit.Mul(next)
+		} else {
+			unit = unit.Div(next)
+		}
+	}
+	return unit
+}
+
+// parseUnitPower 解析单个单位及其可选的整数次幂，如 s^2、m^-1
+func (p *Parser) parseUnitPower() calculator.Unit {
+	unit, _ := calculator.LookupUnit(p.tokens[p.pos])
+	p.pos++
+	if p.pos >= len(p.tokens) || p.tokens[p.pos] != "^" {
+		return unit
+	}
+	p.pos++
+	sign := ""
+	if p.pos < len(p.tokens) && p.tokens[p.pos] == "-" {
+		sign = "-"
+		p.pos++
+	}
+	if p.pos >= len(p.tokens) {
+		panic(syntaxError("单位的指数不完整"))
+	}
+	n, err := strconv.Atoi(sign + p.tokens[p.pos])
+	if err != nil {
+		panic(syntaxError("单位的指数必须为整数: " + p.tokens[p.pos]))
+	}
+	p.pos++
+	return unit.Pow(n)
+}
+
+// parseConversion 解析 in 之后的目标单位或货币，in 已被读取
+func (p *Parser) parseConversion(operand Node) Node {
+	if p.isCurrencyToken(p.pos) {
+		code := p.tokens[p.pos]
+		p.pos++
+		return &ExchangeOperation{Operand: operand, Currency: code, calc: p.calc}
+	}
+	if !p.isUnitToken(p.pos) {
+		if p.pos < len(p.tokens) && calculator.IsCurrencyCode(p.tokens[p.pos]) {
+			panic(syntaxError("汇率表中没有货币" + p.tokens[p.pos]))
+		}
+		panic(syntaxError("in后需要单位"))
+	}
+	return &ConvertOperation{Operand: operand, Unit: p.parseUnit(), calc: p.calc}
+}
@@ -0,0 +1,347 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/shopspring/decimal"
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+// Value 表示表达式的求值结果
+type Value interface {
+	// Format 按计算器的精度与舍入设置格式化结果
+	Format(calc *calculator.Calculator) string
+}
+
+// Real 表示实数结果
+type Real struct {
+	decimal.Decimal
+}
+
+func (r Real) Format(calc *calculator.Calculator) string {
+	// 指定输出进制时，整数值的实数按整数输出
+	if calc.Base() != calculator.Base10 && r.IsInteger() {
+		return calc.FormatInt(r.BigInt())
+	}
+	return calc.Format(r.Decimal)
+}
+
+// Complex 表示虚部不为零的复数结果
+type Complex struct {
+	calculator.Complex
+}
+
+func (z Complex) Format(calc *calculator.Calculator) string {
+	return calc.FormatComplex(z.Complex)
+}
+
+// Rational 表示精确模式下的有理数结果
+type Rational struct {
+	*big.Rat
+}
+
+func (r Rational) Format(calc *calculator.Calculator) string {
+	if r.IsInt() {
+		return calc.FormatInt(r.Num())
+	}
+	return calc.FormatRational(r.Rat)
+}
+
+// ratResult 将有理数计算结果包装为 Value
+func ratResult(r *big.Rat, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return Rational{r}, nil
+}
+
+// Integer 表示阶乘、组合数等整数运算的精确结果，输出时保留全部数字，按计算器的输出进制格式化
+type Integer struct {
+	*big.Int
+}
+
+func (n Integer) Format(calc *calculator.Calculator) string {
+	return calc.FormatInt(n.Int)
+}
+
+// integerResult 将整数计算结果包装为 Value
+func integerResult(n *big.Int, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return Integer{n}, nil
+}
+
+// Factorization 表示 factor(n) 的质因数分解结果，输出为 2^3 * 3 * 7 的形式
+//
+// 参与其他运算时按分解前的整数计算。
+type Factorization struct {
+	Factors []calculator.Factor
+}
+
+func (f Factorization) Format(calc *calculator.Calculator) string {
+	return calculator.FormatFactors(f.Factors)
+}
+
+// Int 返回各质因数之积，即分解前的整数
+func (f Factorization) Int() *big.Int {
+	res := big.NewInt(1)
+	for _, factor := range f.Factors {
+		res.Mul(res, new(big.Int).Exp(factor.Prime, big.NewInt(int64(factor.Exponent)), nil))
+	}
+	return res
+}
+
+// factorsResult 将质因数分解结果包装为 Value
+func factorsResult(factors []calculator.Factor, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return Factorization{factors}, nil
+}
+
+// toRational 将整数或有理数结果转换为有理数
+func toRational(v Value) (*big.Rat, bool) {
+	switch v := v.(type) {
+	case Integer:
+		return new(big.Rat).SetInt(v.Int), true
+	case Factorization:
+		return new(big.Rat).SetInt(v.Int()), true
+	case Rational:
+		return v.Rat, true
+	}
+	return nil, false
+}
+
+// toDecimal 将整数精确地、有理数按当前精度转换为实数，其余结果原样返回
+func toDecimal(calc *calculator.Calculator, v Value) Value {
+	switch v := v.(type) {
+	case Integer:
+		return Real{decimal.NewFromBigInt(v.Int, 0)}
+	case Factorization:
+		return Real{decimal.NewFromBigInt(v.Int(), 0)}
+	case Rational:
+		return Real{calc.RatDecimal(v.Rat)}
+	}
+	return v
+}
+
+// realResult 将实数计算结果包装为 Value
+func realResult(d decimal.Decimal, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return Real{d}, nil
+}
+
+// complexResult 将复数计算结果包装为 Value，虚部为零时退化为实数
+func complexResult(z calculator.Complex, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	if z.IsReal() {
+		return Real{z.Re}, nil
+	}
+	return Complex{z}, nil
+}
+
+// toComplex 将实数或复数结果转换为复数
+func toComplex(v Value) (calculator.Complex, error) {
+	switch v := v.(type) {
+	case Real:
+		return calculator.NewComplex(v.Decimal, decimal.Zero), nil
+	case Complex:
+		return v.Complex, nil
+	case Quantity:
+		return calculator.Complex{}, fmt.Errorf("%w: 带单位 %s 的量不能参与该运算", calculator.ErrUnitMismatch, v.Unit.Name)
+	case Money:
+		return calculator.Complex{}, fmt.Errorf("%w: %s 金额不能参与该运算", calculator.ErrUnitMismatch, v.Currency)
+	case Interval, Measurement:
+		return calculator.Complex{}, fmt.Errorf("%w: 带不确定度的测量值只支持 + - * / ^、sqrt、sin、cos、tan、asin、acos、atan、ln 与 exp", calculator.ErrDomain)
+	case Matrix:
+		return calculator.Complex{}, fmt.Errorf("%w: 矩阵不能参与该运算", calculator.ErrDimensionMismatch)
+	case Expression:
+		return calculator.Complex{}, fmt.Errorf("%w: 符号表达式 %s 不能参与数值运算", calculator.ErrDomain, Infix(v.Node))
+	}
+	return calculator.Complex{}, fmt.Errorf("%w: 运算的参数必须为实数或复数", calculator.ErrDomain)
+}
+
+// toReal 要求 v 为实数，name 用于错误信息
+func toReal(v Value, name string) (decimal.Decimal, error) {
+	if r, ok := v.(Real); ok {
+		return r.Decimal, nil
+	}
+	if q, ok := v.(Quantity); ok {
+		return decimal.Zero, fmt.Errorf("%w: %s的参数不能带单位 %s", calculator.ErrUnitMismatch, name, q.Unit.Name)
+	}
+	if m, ok := v.(Money); ok {
+		return decimal.Zero, fmt.Errorf("%w: %s的参数不能是 %s 金额", calculator.ErrUnitMismatch, name, m.Currency)
+	}
+	if isMeasured(v) {
+		return decimal.Zero, fmt.Errorf("%w: %s的参数不能带不确定度", calculator.ErrDomain, name)
+	}
+	if _, ok := v.(Matrix); ok {
+		return decimal.Zero, fmt.Errorf("%w: %s的参数不能是矩阵", calculator.ErrDimensionMismatch, name)
+	}
+	return decimal.Zero, fmt.Errorf("%w: %s的参数必须为实数", calculator.ErrDomain, name)
+}
+
+// arithmetic 执行四则运算
+//
+// 两个整数的加、减、乘保持为整数；精确模式下整数与有理数之间精确计算。
+// 其余情况下整数和有理数先转换为实数，任一操作数为复数时按复数计算；任一操作数带单位时按 quantityArithmetic 计算，
+// 任一操作数为金额时按 moneyArithmetic 计算，任一操作数带不确定度时按 measuredArithmetic 计算，
+// 任一操作数为矩阵时按 matrixArithmetic 计算，.* 对其余操作数与 * 相同。
+func arithmetic(calc *calculator.Calculator, op string, left, right Value) (Value, error) {
+	_, lx := left.(Matrix)
+	_, rx := right.(Matrix)
+	if lx || rx {
+		return matrixArithmetic(calc, op, toDecimal(calc, left), toDecimal(calc, right))
+	}
+	// 逐元素乘法只对矩阵有区别，其余操作数按普通乘法计算
+	if op == ".*" {
+		op = "*"
+	}
+	_, lm := left.(Money)
+	_, rm := right.(Money)
+	if lm || rm {
+		return moneyArithmetic(calc, op, toDecimal(calc, left), toDecimal(calc, right))
+	}
+	_, lq := left.(Quantity)
+	_, rq := right.(Quantity)
+	if lq || rq {
+		return quantityArithmetic(calc, op, toDecimal(calc, left), toDecimal(calc, right))
+	}
+	if isMeasured(left) || isMeasured(right) {
+		return measuredArithmetic(calc, op, toDecimal(calc, left), toDecimal(calc, right))
+	}
+	if a, ok := left.(Integer); ok {
+		if b, ok := right.(Integer); ok && op != "/" {
+			switch op {
+			case "+":
+				return Integer{new(big.Int).Add(a.Int, b.Int)}, nil
+			case "-":
+				return Integer{new(big.Int).Sub(a.Int, b.Int)}, nil
+			case "*":
+				return Integer{new(big.Int).Mul(a.Int, b.Int)}, nil
+			}
+			return nil, fmt.Errorf("未知的运算符: %s", op)
+		}
+	}
+	a, aok := toRational(left)
+	b, bok := toRational(right)
+	if calc.Exact() && aok && bok {
+		switch op {
+		case "+":
+			return ratResult(calc.RatAdd(a, b))
+		case "-":
+			return ratResult(calc.RatSubtract(a, b))
+		case "*":
+			return ratResult(calc.RatMultiply(a, b))
+		case "/":
+			return ratResult(calc.RatDivide(a, b))
+		}
+		return nil, fmt.Errorf("未知的运算符: %s", op)
+	}
+	left, right = toDecimal(calc, left), toDecimal(calc, right)
+
+	l, lok := left.(Real)
+	r, rok := right.(Real)
+	if lok && rok {
+		switch op {
+		case "+":
+			return realResult(calc.Add(l.String(), r.String()))
+		case "-":
+			return realResult(calc.Subtract(l.String(), r.String()))
+		case "*":
+			return realResult(calc.Multiply(l.String(), r.String()))
+		case "/":
+			return realResult(calc.Divide(l.String(), r.String()))
+		}
+		return nil, fmt.Errorf("未知的运算符: %s", op)
+	}
+
+	z, err := toComplex(left)
+	if err != nil {
+		return nil, err
+	}
+	w, err := toComplex(right)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "+":
+		return complexResult(calc.CAdd(z, w))
+	case "-":
+		return complexResult(calc.CSubtract(z, w))
+	case "*":
+		return complexResult(calc.CMultiply(z, w))
+	case "/":
+		return complexResult(calc.CDivide(z, w))
+	}
+	return nil, fmt.Errorf("未知的运算符: %s", op)
+}
+
+// applyUnary 对操作数求值后调用实数函数 real，有理数操作数先转换为实数
+//
+// 操作数为复数，或实数函数报告定义域错误（如 sqrt(-4)、ln(-1)、asin(2)）时改用复数函数 cplx。
+func applyUnary(calc *calculator.Calculator, operand Node, real func(string) (decimal.Decimal, error), cplx func(calculator.Complex) (calculator.Complex, error)) (Value, error) {
+	v, err := operand.Evaluate()
+	if err != nil {
+		return nil, err
+	}
+	return unaryValue(calc, v, real, cplx)
+}
+
+// unaryValue 对已求值的操作数调用实数函数 real，规则与 applyUnary 相同
+func unaryValue(calc *calculator.Calculator, v Value, real func(string) (decimal.Decimal, error), cplx func(calculator.Complex) (calculator.Complex, error)) (Value, error) {
+	v = toDecimal(calc, v)
+	if r, ok := v.(Real); ok {
+		res, err := real(r.String())
+		if !errors.Is(err, calculator.ErrDomain) {
+			return realResult(res, err)
+		}
+	}
+	z, err := toComplex(v)
+	if err != nil {
+		return nil, err
+	}
+	return complexResult(cplx(z))
+}
+
+// applyBinary 对两个操作数求值后调用实数函数 real，规则与 applyUnary 相同
+func applyBinary(calc *calculator.Calculator, left, right Node, real func(string, string) (decimal.Decimal, error), cplx func(calculator.Complex, calculator.Complex) (calculator.Complex, error)) (Value, error) {
+	a, err := left.Evaluate()
+	if err != nil {
+		return nil, err
+	}
+	b, err := right.Evaluate()
+	if err != nil {
+		return nil, err
+	}
+	return binaryValue(calc, a, b, real, cplx)
+}
+
+// binaryValue 对已求值的两个操作数调用实数函数 real，规则与 applyBinary 相同
+func binaryValue(calc *calculator.Calculator, a, b Value, real func(string, string) (decimal.Decimal, error), cplx func(calculator.Complex, calculator.Complex) (calculator.Complex, error)) (Value, error) {
+	a, b = toDecimal(calc, a), toDecimal(calc, b)
+	ra, aok := a.(Real)
+	rb, bok := b.(Real)
+	if aok && bok {
+		res, err := real(ra.String(), rb.String())
+		if !errors.Is(err, calculator.ErrDomain) {
+			return realResult(res, err)
+		}
+	}
+	z, err := toComplex(a)
+	if err != nil {
+		return nil, err
+	}
+	w, err := toComplex(b)
+	if err != nil {
+		return nil, err
+	}
+	return complexResult(cplx(z, w))
+}
@@ -0,0 +1,152 @@
+package calculator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxAmortizationPeriods 还款计划允许的最大期数
+const maxAmortizationPeriods = 10000
+
+// centPlaces 还款计划中金额保留的小数位数
+const centPlaces = 2
+
+// PaymentFrequency 定义还款计划每年的还款次数
+type PaymentFrequency int
+
+const (
+	Monthly    PaymentFrequency = 12 // 每月（默认）
+	Annual     PaymentFrequency = 1  // 每年
+	Semiannual PaymentFrequency = 2  // 每半年
+	Quarterly  PaymentFrequency = 4  // 每季度
+	Biweekly   PaymentFrequency = 26 // 每两周
+	Weekly     PaymentFrequency = 52 // 每周
+)
+
+// paymentFrequencyNames 还款频率与其名称的对应关系
+var paymentFrequencyNames = map[PaymentFrequency]string{
+	Monthly:    "monthly",
+	Annual:     "annual",
+	Semiannual: "semiannual",
+	Quarterly:  "quarterly",
+	Biweekly:   "biweekly",
+	Weekly:     "weekly",
+}
+
+func (f PaymentFrequency) String() string {
+	if name, ok := paymentFrequencyNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("PaymentFrequency(%d)", int(f))
+}
+
+// ParsePaymentFrequency 根据名称解析还款频率，名称不区分大小写
+func ParsePaymentFrequency(name string) (PaymentFrequency, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for f, n := range paymentFrequencyNames {
+		if n == name {
+			return f, nil
+		}
+	}
+	return Monthly, fmt.Errorf("未知的还款频率: %s", name)
+}
+
+// AmortizationRow 表示还款计划中的一期，金额均保留到分
+type AmortizationRow struct {
+	Period    int
+	Payment   decimal.Decimal // 本期还款额
+	Interest  decimal.Decimal // 其中的利息
+	Principal decimal.Decimal // 其中的本金
+	Balance   decimal.Decimal // 本期还款后的剩余本金
+}
+
+// Amortization 表示等额本息贷款的完整还款计划
+type Amortization struct {
+	Payment       decimal.Decimal // 每期还款额，最后一期按剩余本金调整
+	TotalPayment  decimal.Decimal
+	TotalInterest decimal.Decimal
+	Schedule      []AmortizationRow
+}
+
+// Amortize 计算等额本息贷款的还款计划
+//
+// principal 为贷款本金，最多保留到分，annualRate 为名义年利率（0.05 表示 5%），years 为贷款年限，
+// 每期利率为 annualRate / frequency。每期还款额与利息都以银行家舍入保留到分，
+// 最后一期的还款额等于剩余本金加利息，使剩余本金恰好为零。
+func (c *Calculator) Amortize(principal, annualRate, years string, frequency PaymentFrequency) (*Amortization, error) {
+	p, err := ParseNumber(principal)
+	if err != nil {
+		return nil, err
+	}
+	if !p.IsPositive() {
+		return nil, domainError("贷款本金必须为正数")
+	}
+	if !p.Round(centPlaces).Equal(p) {
+		// 不足一分的本金无法在还款计划中偿还，会使最后一期的剩余本金不为零
+		return nil, domainError("贷款本金最多保留到分")
+	}
+	rate, err := ParseNumber(annualRate)
+	if err != nil {
+		return nil, err
+	}
+	if rate.IsNegative() {
+		return nil, domainError("年利率不能为负数")
+	}
+	term, err := ParseNumber(years)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := paymentFrequencyNames[frequency]; !ok {
+		return nil, domainError(fmt.Sprintf("不支持的还款频率: %v", frequency))
+	}
+	ppy := decimal.NewFromInt(int64(frequency))
+	n := term.Mul(ppy)
+	if !n.IsPositive() || !n.IsInteger() {
+		return nil, domainError(fmt.Sprintf("贷款年限按%v还款时必须对应正整数期", frequency))
+	}
+	if n.GreaterThan(decimal.NewFromInt(maxAmortizationPeriods)) {
+		return nil, domainError(fmt.Sprintf("还款期数不能超过%d", maxAmortizationPeriods))
+	}
+	periods := int(n.IntPart())
+
+	// 每期利息 = 剩余本金 · 年利率 / 频率，保留足够位数后再舍入到分
+	wp := guardDigits + intDigits(p) + centPlaces
+	if rate.Exponent() < 0 {
+		wp -= rate.Exponent()
+	}
+	interestOn := func(balance decimal.Decimal) decimal.Decimal {
+		return balance.Mul(rate).DivRound(ppy, wp+guardDigits).RoundBank(centPlaces)
+	}
+
+	var payment decimal.Decimal
+	if rate.IsZero() {
+		payment = p.DivRound(n, wp).RoundBank(centPlaces)
+	} else {
+		r := rate.DivRound(ppy, wp+guardDigits)
+		t := tvm{rate: r, nper: n, pv: p}
+		extra, err := t.extraPlaces()
+		if err != nil {
+			return nil, err
+		}
+		g, a, _ := t.factors(r, wp+extra)
+		payment = p.Mul(g).DivRound(a, wp).RoundBank(centPlaces)
+	}
+
+	res := &Amortization{Payment: payment, Schedule: make([]AmortizationRow, periods)}
+	balance := p
+	for i := range res.Schedule {
+		row := AmortizationRow{Period: i + 1, Payment: payment, Interest: interestOn(balance)}
+		if i == periods-1 {
+			row.Payment = balance.Add(row.Interest)
+		}
+		row.Principal = row.Payment.Sub(row.Interest)
+		balance = balance.Sub(row.Principal)
+		row.Balance = balance
+		res.Schedule[i] = row
+		res.TotalPayment = res.TotalPayment.Add(row.Payment)
+		res.TotalInterest = res.TotalInterest.Add(row.Interest)
+	}
+	return res, nil
+}
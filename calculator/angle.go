@@ -0,0 +1,164 @@
+package calculator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// AngleUnit 定义三角函数的输入与反三角函数的结果使用的角度单位
+type AngleUnit int
+
+const (
+	Radians  AngleUnit = iota // 弧度（默认）
+	Degrees                   // 角度，一圈为 360°
+	Gradians                  // 百分度，一圈为 400grad
+)
+
+// angleUnitNames 角度单位与其名称的对应关系
+var angleUnitNames = map[AngleUnit]string{
+	Radians:  "rad",
+	Degrees:  "deg",
+	Gradians: "grad",
+}
+
+func (u AngleUnit) String() string {
+	if name, ok := angleUnitNames[u]; ok {
+		return name
+	}
+	return fmt.Sprintf("AngleUnit(%d)", int(u))
+}
+
+// ParseAngleUnit 根据名称解析角度单位，名称不区分大小写
+func ParseAngleUnit(name string) (AngleUnit, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for unit, n := range angleUnitNames {
+		if n == name {
+			return unit, nil
+		}
+	}
+	return Radians, fmt.Errorf("未知的角度单位: %s", name)
+}
+
+// turn 返回该单位下一整圈的大小，弧度的一圈 2π 无法精确表示，返回零
+func (u AngleUnit) turn() decimal.Decimal {
+	switch u {
+	case Degrees:
+		return decimal.NewFromInt(360)
+	case Gradians:
+		return decimal.NewFromInt(400)
+	}
+	return decimal.Zero
+}
+
+// WithAngleUnit 设置三角函数使用的角度单位，默认为 Radians
+func WithAngleUnit(unit AngleUnit) Option {
+	return func(c *Calculator) {
+		c.angleUnit = unit
+	}
+}
+
+// AngleUnit 返回三角函数使用的角度单位
+func (c *Calculator) AngleUnit() AngleUnit {
+	return c.angleUnit
+}
+
+// toRadians 将当前单位下的角度 v 转换为弧度，保留 prec 位小数
+//
+// 角度制与百分度制下先对整圈精确取模，大角度也不会损失精度。
+func (c *Calculator) toRadians(v decimal.Decimal, prec int32) decimal.Decimal {
+	if c.angleUnit == Radians {
+		return v
+	}
+	t := c.angleUnit.turn()
+	return v.Mod(t).Mul(piDec(prec+guardDigits)).Mul(two).DivRound(t, prec)
+}
+
+// fromRadians 将弧度 r 转换为当前单位下的角度，保留 prec 位小数
+func (c *Calculator) fromRadians(r decimal.Decimal, prec int32) decimal.Decimal {
+	if c.angleUnit == Radians {
+		return r.Round(prec)
+	}
+	wp := prec + guardDigits
+	return r.Mul(c.angleUnit.turn()).DivRound(piDec(wp).Mul(two), prec)
+}
+
+// ctoRadians 将复数角度的实部和虚部分别转换为弧度
+func (c *Calculator) ctoRadians(z Complex, prec int32) Complex {
+	if c.angleUnit == Radians {
+		return z
+	}
+	t := c.angleUnit.turn()
+	k := piDec(prec + guardDigits + 3).Mul(two)
+	return Complex{Re: z.Re.Mul(k).DivRound(t, prec), Im: z.Im.Mul(k).DivRound(t, prec)}
+}
+
+// cfromRadians 将以弧度表示的复数角度转换为当前单位
+func (c *Calculator) cfromRadians(z Complex, prec int32) Complex {
+	return Complex{Re: c.fromRadians(z.Re, prec), Im: c.fromRadians(z.Im, prec)}
+}
+
+// onHalfTurn 报告角度制或百分度制下 v - offset 是否恰为半圈的整数倍
+//
+// 用于精确识别 sin、cos、tan 的零点和无定义点，弧度制下 π 无法精确表示，总是返回 false。
+func (c *Calculator) onHalfTurn(v, offset decimal.Decimal) bool {
+	if c.angleUnit == Radians {
+		return false
+	}
+	return v.Sub(offset).Mod(c.angleUnit.turn().Div(two)).IsZero()
+}
+
+// quarterTurn 返回当前单位下四分之一圈的大小，弧度制下返回零
+func (c *Calculator) quarterTurn() decimal.Decimal {
+	return c.angleUnit.turn().Div(decimal.NewFromInt(4))
+}
+
+// convertTurn 将一圈大小为 from 的单位下的角度 v 转换为当前单位，from 为零表示弧度
+func (c *Calculator) convertTurn(v, from decimal.Decimal) decimal.Decimal {
+	to := c.angleUnit.turn()
+	if from.Equal(to) {
+		return c.round(v)
+	}
+	if !from.IsZero() && !to.IsZero() {
+		// 非弧度单位之间按整圈之比精确换算
+		return c.div(v.Mul(to), from)
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		if from.IsZero() {
+			return c.fromRadians(v, prec)
+		}
+		wp := prec + guardDigits + intDigits(v)
+		return v.Mul(piDec(wp)).Mul(two).DivRound(from, prec)
+	})
+}
+
+// ConvertAngle 将以 from 为单位的角度转换为当前单位
+func (c *Calculator) ConvertAngle(value string, from AngleUnit) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.convertTurn(v, from.turn()), nil
+}
+
+// sixty 每度的角分数、每角分的角秒数
+var sixty = decimal.NewFromInt(60)
+
+// DMS 将度、分、秒表示的角度转换为当前单位，例如 30°15'10"
+func (c *Calculator) DMS(degrees, minutes, seconds string) (decimal.Decimal, error) {
+	d, err := ParseNumber(degrees)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	m, s, err := parsePair(minutes, seconds)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if m.IsNegative() || s.IsNegative() || m.GreaterThanOrEqual(sixty) || s.GreaterThanOrEqual(sixty) {
+		return decimal.Zero, domainError("角分和角秒必须在 [0,60) 范围内")
+	}
+	// 先换算为角秒，一圈为 1296000″
+	arcsec := d.Mul(decimal.NewFromInt(3600)).Add(m.Mul(sixty)).Add(s)
+	return c.convertTurn(arcsec, decimal.NewFromInt(1296000)), nil
+}
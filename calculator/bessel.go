@@ -0,0 +1,196 @@
+package calculator
+
+import (
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// besselAsymptotic 报告 |x| 是否足够大，可以在 prec 位小数下使用汉克尔渐近展开
+//
+// 渐近级数的项在 m ≈ 2|x| 附近最小，约为 e^(-2|x|)，|x| 超过该阈值时截断误差小于 10^(-prec)。
+func besselAsymptotic(x decimal.Decimal, prec int32) bool {
+	return x.Abs().GreaterThan(decimal.NewFromInt(int64(prec)*6/5 + 20))
+}
+
+// seriesPrecision 返回交错级数需要的工作精度
+//
+// 级数的最大项约为 e^|x|，相消会损失约 |x|/ln10 位有效数字。
+func seriesPrecision(x decimal.Decimal, prec int32) int32 {
+	return prec + guardDigits + int32(x.Abs().InexactFloat64()/math.Ln10) + 2
+}
+
+// besselJSeries 使用级数 J_n(x) = Σ (-1)^k·(x/2)^(2k+n) / (k!·(k+n)!) 计算 n 为 0 或 1 时的贝塞尔函数
+func besselJSeries(n int64, x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := seriesPrecision(x, prec)
+	h := x.Mul(oneHalf)
+	q := h.Mul(h).Neg() // -(x/2)²
+	term := one
+	if n == 1 {
+		term = h
+	}
+	sum := term
+	for k := int64(1); ; k++ {
+		term = term.Mul(q).DivRound(decimal.NewFromInt(k*(k+n)), wp)
+		if term.IsZero() {
+			break
+		}
+		sum = sum.Add(term)
+	}
+	return sum.Round(prec)
+}
+
+// besselYSeries 计算 x > 0 时的 Y_0(x) 或 Y_1(x)
+//
+// Y_0(x) = (2/π)·(ln(x/2) + γ)·J_0(x) + (2/π)·Σ_(k≥1) (-1)^(k+1)·H_k·(x²/4)^k / (k!)²，
+// Y_1(x) = (2/π)·(ln(x/2) + γ)·J_1(x) - 2/(πx) - (1/π)·Σ_(k≥0) (-1)^k·(H_k + H_(k+1))·(x/2)^(2k+1) / (k!·(k+1)!)，
+// 其中 H_k 为调和数。
+func besselYSeries(n int64, x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := seriesPrecision(x, prec) + leadingZeros(x)
+	h := x.Mul(oneHalf)
+	q := h.Mul(h).Neg()
+	pi := piDec(wp)
+
+	// 级数中 (x/2)^(2k+n) / (k!·(k+n)!) 部分，hk 为当前的调和数 H_k
+	term := one
+	if n == 1 {
+		term = h
+	}
+	hk := decimal.Zero
+	sum := decimal.Zero
+	if n == 1 {
+		sum = term // H_0 + H_1 = 1
+	}
+	for k := int64(1); ; k++ {
+		term = term.Mul(q).DivRound(decimal.NewFromInt(k*(k+n)), wp)
+		hk = hk.Add(one.DivRound(decimal.NewFromInt(k), wp))
+		weight := hk
+		if n == 1 {
+			weight = hk.Add(hk).Add(one.DivRound(decimal.NewFromInt(k+1), wp))
+		}
+		t := term.Mul(weight).Round(wp)
+		if t.IsZero() {
+			break
+		}
+		sum = sum.Add(t)
+	}
+
+	j := besselJSeries(n, x, wp)
+	lead := lnDec(h, wp).Add(eulerDec(wp)).Mul(j).Mul(two)
+	var res decimal.Decimal
+	if n == 0 {
+		// 循环中的项带有 (-1)^k，而 Y_0 的级数为 (-1)^(k+1)
+		res = lead.Sub(sum.Mul(two))
+	} else {
+		res = lead.Sub(two.DivRound(x, wp)).Sub(sum)
+	}
+	return res.DivRound(pi, prec)
+}
+
+// besselHankel 使用汉克尔渐近展开计算 x > 0 时的 J_n(x) 与 Y_n(x)
+//
+// J_n(x) = √(2/(πx))·(P·cos χ - Q·sin χ)，Y_n(x) = √(2/(πx))·(P·sin χ + Q·cos χ)，χ = x - (n/2 + 1/4)·π，
+// P = c_0 - c_2 + c_4 - …，Q = c_1 - c_3 + …，c_m = c_(m-1)·(4n² - (2m-1)²) / (8m·x)。
+func besselHankel(n int64, x decimal.Decimal, prec int32) (j, y decimal.Decimal) {
+	wp := prec + guardDigits
+	mu := decimal.NewFromInt(4 * n * n)
+	eightX := x.Mul(decimal.NewFromInt(8))
+	p, q := one, decimal.Zero
+	c := one
+	prev := decimal.Zero
+	for m := int64(1); ; m++ {
+		odd := decimal.NewFromInt((2*m - 1) * (2*m - 1))
+		c = c.Mul(mu.Sub(odd)).DivRound(eightX.Mul(decimal.NewFromInt(m)), wp)
+		// 渐近级数发散前停止
+		if c.IsZero() || (m > 1 && c.Abs().GreaterThan(prev)) {
+			break
+		}
+		prev = c.Abs()
+		switch m % 4 {
+		case 0:
+			p = p.Add(c)
+		case 1:
+			q = q.Add(c)
+		case 2:
+			p = p.Sub(c)
+		case 3:
+			q = q.Sub(c)
+		}
+	}
+
+	pw := wp + intDigits(x)
+	quarter := decimal.NewFromInt(2*n+1).DivRound(decimal.NewFromInt(4), 2)
+	chi := x.Sub(quarter.Mul(piDec(pw))).Round(wp)
+	sin, cos := sinDec(chi, wp), cosDec(chi, wp)
+	scale := sqrtDec(two.DivRound(piDec(wp+intDigits(x)).Mul(x), wp+intDigits(x)), wp)
+	j = p.Mul(cos).Sub(q.Mul(sin)).Mul(scale).Round(prec)
+	y = p.Mul(sin).Add(q.Mul(cos)).Mul(scale).Round(prec)
+	return j, y
+}
+
+// besselJDec 计算 J_0(x) 或 J_1(x)，保留 prec 位小数
+func besselJDec(n int64, x decimal.Decimal, prec int32) decimal.Decimal {
+	if !besselAsymptotic(x, prec) {
+		return besselJSeries(n, x, prec)
+	}
+	// J_0 为偶函数，J_1 为奇函数
+	j, _ := besselHankel(n, x.Abs(), prec)
+	if n == 1 && x.IsNegative() {
+		j = j.Neg()
+	}
+	return j
+}
+
+// besselYDec 计算 x > 0 时的 Y_0(x) 或 Y_1(x)，保留 prec 位小数
+func besselYDec(n int64, x decimal.Decimal, prec int32) decimal.Decimal {
+	if !besselAsymptotic(x, prec) {
+		return besselYSeries(n, x, prec)
+	}
+	_, y := besselHankel(n, x, prec)
+	return y
+}
+
+// besselJ 计算第一类贝塞尔函数 J_n(x)
+func (c *Calculator) besselJ(n int64, value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return besselJDec(n, v, prec)
+	}), nil
+}
+
+// besselY 计算第二类贝塞尔函数 Y_n(x)，要求 x > 0
+func (c *Calculator) besselY(n int64, value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if !v.IsPositive() {
+		return decimal.Zero, domainError("第二类贝塞尔函数的参数必须大于0")
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return besselYDec(n, v, prec)
+	}), nil
+}
+
+// BesselJ0 计算零阶第一类贝塞尔函数 J_0(x)
+func (c *Calculator) BesselJ0(value string) (decimal.Decimal, error) {
+	return c.besselJ(0, value)
+}
+
+// BesselJ1 计算一阶第一类贝塞尔函数 J_1(x)
+func (c *Calculator) BesselJ1(value string) (decimal.Decimal, error) {
+	return c.besselJ(1, value)
+}
+
+// BesselY0 计算零阶第二类贝塞尔函数 Y_0(x)
+func (c *Calculator) BesselY0(value string) (decimal.Decimal, error) {
+	return c.besselY(0, value)
+}
+
+// BesselY1 计算一阶第二类贝塞尔函数 Y_1(x)
+func (c *Calculator) BesselY1(value string) (decimal.Decimal, error) {
+	return c.besselY(1, value)
+}
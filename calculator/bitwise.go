@@ -0,0 +1,205 @@
+package calculator
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// NumberBase 定义整数结果输出时使用的进制
+type NumberBase int
+
+const (
+	Base10 NumberBase = iota // 十进制（默认）
+	Base16                   // 十六进制，如 0xff
+	Base2                    // 二进制，如 0b1010
+	Base8                    // 八进制，如 0o17
+)
+
+// numberBaseNames 进制与其名称的对应关系
+var numberBaseNames = map[NumberBase]string{
+	Base10: "dec",
+	Base16: "hex",
+	Base2:  "bin",
+	Base8:  "oct",
+}
+
+func (b NumberBase) String() string {
+	if name, ok := numberBaseNames[b]; ok {
+		return name
+	}
+	return fmt.Sprintf("NumberBase(%d)", int(b))
+}
+
+// ParseNumberBase 根据名称解析进制，名称不区分大小写
+func ParseNumberBase(name string) (NumberBase, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for base, n := range numberBaseNames {
+		if n == name {
+			return base, nil
+		}
+	}
+	return Base10, fmt.Errorf("未知的进制: %s", name)
+}
+
+// radix 返回进制的基数
+func (b NumberBase) radix() int {
+	switch b {
+	case Base16:
+		return 16
+	case Base2:
+		return 2
+	case Base8:
+		return 8
+	}
+	return 10
+}
+
+// basePrefixes 整数常量的进制前缀
+var basePrefixes = map[string]NumberBase{"0x": Base16, "0b": Base2, "0o": Base8}
+
+// prefix 返回进制的前缀，十进制没有前缀
+func (b NumberBase) prefix() string {
+	for p, base := range basePrefixes {
+		if base == b {
+			return p
+		}
+	}
+	return ""
+}
+
+// HasBasePrefix 判断 s 是否以 0x、0b、0o 前缀开头，前缀不区分大小写
+func HasBasePrefix(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	_, ok := basePrefixes[strings.ToLower(s[:2])]
+	return ok
+}
+
+// ParseBaseInteger 解析带进制前缀的整数常量，如 0xFF、0b1010、0o17，数字之间可以用 _ 分隔
+func ParseBaseInteger(s string) (*big.Int, error) {
+	if !HasBasePrefix(s) {
+		return nil, fmt.Errorf("整数缺少进制前缀: %s", s)
+	}
+	base := basePrefixes[strings.ToLower(s[:2])]
+	digits := strings.ReplaceAll(s[2:], "_", "")
+	n, ok := new(big.Int).SetString(digits, base.radix())
+	if !ok || digits == "" || digits[0] == '+' || digits[0] == '-' {
+		return nil, fmt.Errorf("无效的整数: %s", s)
+	}
+	return n, nil
+}
+
+// WithBase 设置整数结果输出时使用的进制，默认为 Base10
+func WithBase(base NumberBase) Option {
+	return func(c *Calculator) {
+		c.base = base
+	}
+}
+
+// Base 返回整数结果输出时使用的进制
+func (c *Calculator) Base() NumberBase {
+	return c.base
+}
+
+// FormatInt 按输出进制格式化整数，如十六进制的 255 为 0xff、-255 为 -0xff
+func (c *Calculator) FormatInt(n *big.Int) string {
+	if c.base == Base10 {
+		return n.String()
+	}
+	digits := new(big.Int).Abs(n).Text(c.base.radix())
+	if n.Sign() < 0 {
+		return "-" + c.base.prefix() + digits
+	}
+	return c.base.prefix() + digits
+}
+
+// maxShiftBits 左移结果允许的最大二进制位数，与 maxResultDigits 位十进制数相当
+const maxShiftBits = maxResultDigits * 332 / 100
+
+// bitOperands 将两个操作数解析为整数，name 用于错误信息
+func bitOperands(a, b, name string) (*big.Int, *big.Int, error) {
+	av, err := parseInteger(a, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	bv, err := parseInteger(b, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return av, bv, nil
+}
+
+// BitAnd 计算按位与 a & b，负数按无限长的二进制补码处理
+func (c *Calculator) BitAnd(a, b string) (*big.Int, error) {
+	av, bv, err := bitOperands(a, b, "&")
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).And(av, bv), nil
+}
+
+// BitOr 计算按位或 a | b，负数按无限长的二进制补码处理
+func (c *Calculator) BitOr(a, b string) (*big.Int, error) {
+	av, bv, err := bitOperands(a, b, "|")
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Or(av, bv), nil
+}
+
+// BitXor 计算按位异或 a xor b，负数按无限长的二进制补码处理
+func (c *Calculator) BitXor(a, b string) (*big.Int, error) {
+	av, bv, err := bitOperands(a, b, "xor")
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Xor(av, bv), nil
+}
+
+// BitNot 计算按位取反 ~a，即 -a-1
+func (c *Calculator) BitNot(a string) (*big.Int, error) {
+	av, err := parseInteger(a, "~")
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Not(av), nil
+}
+
+// ShiftLeft 计算左移 a << n，即 a·2^n，n 不能为负数
+func (c *Calculator) ShiftLeft(a, n string) (*big.Int, error) {
+	av, nv, err := bitOperands(a, n, "<<")
+	if err != nil {
+		return nil, err
+	}
+	if nv.Sign() < 0 {
+		return nil, domainError("移位的位数不能为负数")
+	}
+	if av.Sign() == 0 {
+		return av, nil
+	}
+	if !nv.IsInt64() || int64(av.BitLen())+nv.Int64() > maxShiftBits {
+		return nil, ErrOverflow
+	}
+	return new(big.Int).Lsh(av, uint(nv.Int64())), nil
+}
+
+// ShiftRight 计算算术右移 a >> n，即 a/2^n 向下取整，n 不能为负数
+func (c *Calculator) ShiftRight(a, n string) (*big.Int, error) {
+	av, nv, err := bitOperands(a, n, ">>")
+	if err != nil {
+		return nil, err
+	}
+	if nv.Sign() < 0 {
+		return nil, domainError("移位的位数不能为负数")
+	}
+	if !nv.IsInt64() || nv.Int64() > int64(av.BitLen()) {
+		// 移出全部有效位后，非负数为 0，负数为 -1
+		if av.Sign() < 0 {
+			return big.NewInt(-1), nil
+		}
+		return new(big.Int), nil
+	}
+	return new(big.Int).Rsh(av, uint(nv.Int64())), nil
+}
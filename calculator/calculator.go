@@ -1,192 +1,364 @@
 package calculator
 
 import (
-	"math"
-
 	"github.com/shopspring/decimal"
 )
 
 // Calculator 提供基本的数学计算功能
+//
+// 除法和开方等运算所需的精度都保存在实例内部，不会读写 decimal.DivisionPrecision
+// 等包级全局变量，实例创建后也不再修改，因此可以在多个 goroutine 中并发使用。
 type Calculator struct {
-	precision int32 // 计算精度
+	precision     int32           // 计算精度
+	precisionMode PrecisionMode   // 精度模式：小数位数或有效数字
+	rounding      RoundingMode    // 舍入方式
+	exact         bool            // 精确模式：四则运算与整数次幂使用有理数
+	angleUnit     AngleUnit       // 三角函数使用的角度单位
+	rates         *Rates          // 货币换算使用的汇率表
+	base          NumberBase      // 整数结果输出时使用的进制
+	uncertainty   UncertaintyMode // 测量值的运算方式：区间运算或高斯误差传播
+}
+
+// Option 用于在创建计算器时调整默认配置
+type Option func(*Calculator)
+
+// WithRounding 设置计算结果的舍入方式，默认为 RoundHalfUp
+func WithRounding(mode RoundingMode) Option {
+	return func(c *Calculator) {
+		c.rounding = mode
+	}
+}
+
+// WithPrecisionMode 设置精度的含义，默认为 DecimalPlaces
+//
+// 有效数字模式下精度至少为 1 位，精度为 0 时按 1 位有效数字计算。
+func WithPrecisionMode(mode PrecisionMode) Option {
+	return func(c *Calculator) {
+		c.precisionMode = mode
+	}
+}
+
+// WithExact 开启精确模式，四则运算与整数次幂以有理数精确计算，不做舍入
+func WithExact() Option {
+	return func(c *Calculator) {
+		c.exact = true
+	}
+}
+
+// WithWorkingPrecision 将精度提高到近似计算使用的工作精度，用于计算只作为中间结果的参数
+func WithWorkingPrecision() Option {
+	return func(c *Calculator) {
+		c.precision = c.workPrecision()
+	}
 }
 
 // NewCalculator 创建一个新的计算器实例，指定计算精度
-func NewCalculator(precision int32) *Calculator {
+func NewCalculator(precision int32, opts ...Option) *Calculator {
 	if precision < 0 {
 		precision = 10 // 默认精度为10位小数
 	}
-	return &Calculator{precision: precision}
+	c := &Calculator{precision: precision, rounding: RoundHalfUp}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.precisionMode == SignificantDigits && c.precision < 1 {
+		c.precision = 1
+	}
+	return c
+}
+
+// workPrecision 返回超越函数等近似计算使用的工作精度
+func (c *Calculator) workPrecision() int32 {
+	return c.precision + guardDigits
+}
+
+// round 按当前精度和舍入方式舍入精确值
+func (c *Calculator) round(d decimal.Decimal) decimal.Decimal {
+	return roundDec(d, c.places(d), c.rounding)
+}
+
+// roundApprox 舍入以工作精度近似计算得到的结果
+//
+// 近似值在最后几位保护位上存在误差，先舍去这部分误差，
+// 使 log(8,2)、sin(0) 这类精确结果在向上、向下舍入时也不会偏离一个单位。
+func (c *Calculator) roundApprox(d decimal.Decimal) decimal.Decimal {
+	return c.round(d.Round(c.places(d) + guardDigits - 2))
+}
+
+// div 在当前精度下执行除法，r 不能为零
+func (c *Calculator) div(l, r decimal.Decimal) decimal.Decimal {
+	prec := c.precision
+	if c.precisionMode == SignificantDigits && !l.IsZero() {
+		// 先粗略求商以确定其量级
+		est := l.DivRound(r, c.precision-magnitude(l)+magnitude(r)+guardDigits)
+		prec = c.places(est)
+	}
+	return divRound(l, r, prec, c.rounding)
+}
+
+// sqrt 在当前精度下计算平方根，v 必须为正数
+func (c *Calculator) sqrt(v decimal.Decimal) decimal.Decimal {
+	prec := c.precision
+	if c.precisionMode == SignificantDigits {
+		// 先粗略求平方根以确定其量级
+		est := sqrtDec(v, c.precision-magnitude(v)/2+guardDigits)
+		prec = c.places(est)
+	}
+	return sqrtRound(v, prec, c.rounding)
 }
 
 // Add 执行加法运算
-func (c *Calculator) Add(left, right string) string {
-	l, _ := decimal.NewFromString(left)
-	r, _ := decimal.NewFromString(right)
-	return l.Add(r).Round(c.precision).String()
+func (c *Calculator) Add(left, right string) (decimal.Decimal, error) {
+	l, r, err := parsePair(left, right)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.round(l.Add(r)), nil
 }
 
 // Subtract 执行减法运算
-func (c *Calculator) Subtract(left, right string) string {
-	l, _ := decimal.NewFromString(left)
-	r, _ := decimal.NewFromString(right)
-	return l.Sub(r).Round(c.precision).String()
+func (c *Calculator) Subtract(left, right string) (decimal.Decimal, error) {
+	l, r, err := parsePair(left, right)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.round(l.Sub(r)), nil
 }
 
 // Multiply 执行乘法运算
-func (c *Calculator) Multiply(left, right string) string {
-	l, _ := decimal.NewFromString(left)
-	r, _ := decimal.NewFromString(right)
-	return l.Mul(r).Round(c.precision).String()
+func (c *Calculator) Multiply(left, right string) (decimal.Decimal, error) {
+	l, r, err := parsePair(left, right)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.round(l.Mul(r)), nil
 }
 
 // Divide 执行除法运算
-func (c *Calculator) Divide(left, right string) string {
-	l, _ := decimal.NewFromString(left)
-	r, _ := decimal.NewFromString(right)
+func (c *Calculator) Divide(left, right string) (decimal.Decimal, error) {
+	l, r, err := parsePair(left, right)
+	if err != nil {
+		return decimal.Zero, err
+	}
 	if r.IsZero() {
-		panic("除数不能为零")
+		return decimal.Zero, ErrDivisionByZero
 	}
-	decimal.DivisionPrecision = int(c.precision)
-	return l.Div(r).Round(c.precision).String()
+	return c.div(l, r), nil
 }
 
 // Power 执行乘方运算
-func (c *Calculator) Power(base, exponent string) string {
-	b, _ := decimal.NewFromString(base)
-	e, _ := decimal.NewFromString(exponent)
-	res, _ := b.PowWithPrecision(e, c.precision)
-	return res.Round(c.precision).String()
+func (c *Calculator) Power(base, exponent string) (decimal.Decimal, error) {
+	b, e, err := parsePair(base, exponent)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if b.IsZero() && e.IsNegative() {
+		return decimal.Zero, ErrDivisionByZero
+	}
+	if b.IsNegative() && !e.IsInteger() {
+		return decimal.Zero, domainError("负数的非整数次幂无实数解")
+	}
+	if res, ok := powExact(b, e); ok {
+		if e.IsNegative() {
+			return c.div(one, res), nil
+		}
+		return c.round(res), nil
+	}
+	ok := true
+	res := c.approx(func(prec int32) decimal.Decimal {
+		var r decimal.Decimal
+		r, ok = powDec(b, e, prec)
+		return r
+	})
+	if !ok {
+		return decimal.Zero, ErrOverflow
+	}
+	return res, nil
+}
+
+// Exp 执行以e为底的指数运算
+func (c *Calculator) Exp(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	ok := true
+	res := c.approx(func(prec int32) decimal.Decimal {
+		var r decimal.Decimal
+		r, ok = expDec(v, prec)
+		return r
+	})
+	if !ok {
+		return decimal.Zero, ErrOverflow
+	}
+	return res, nil
 }
 
 // Sqrt 执行开方运算
-func (c *Calculator) Sqrt(value string) string {
-	v, _ := decimal.NewFromString(value)
+func (c *Calculator) Sqrt(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
 	if v.IsNegative() {
-		panic("不能对负数进行开方")
+		return decimal.Zero, domainError("不能对负数进行开方")
 	}
 	if v.IsZero() {
-		return "0"
-	}
-
-	// 使用牛顿迭代法计算平方根
-	z := v.Div(decimal.NewFromInt(2))
-	decimal.DivisionPrecision = int(c.precision)
-
-	// 迭代计算，直到达到指定精度
-	tolerance := decimal.NewFromFloat(math.Pow(10, -float64(c.precision)))
-	for i := 0; i < int(c.precision)*2; i++ {
-		prev := z
-		z = z.Add(v.Div(z)).Div(decimal.NewFromInt(2))
-
-		if z.Sub(prev).Abs().LessThan(tolerance) {
-			break
-		}
+		return decimal.Zero, nil
 	}
 
-	return z.Round(c.precision).String()
+	return c.sqrt(v), nil
 }
 
 // Sin 执行正弦运算
-func (c *Calculator) Sin(value string) string {
-	v, _ := decimal.NewFromString(value)
-	floatVal := v.InexactFloat64()
-	sinVal := math.Sin(floatVal)
-	return decimal.NewFromFloat(sinVal).Round(c.precision).String()
+func (c *Calculator) Sin(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if c.onHalfTurn(v, decimal.Zero) {
+		return decimal.Zero, nil
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return sinDec(c.toRadians(v, prec+guardDigits), prec)
+	}), nil
 }
 
 // Cos 执行余弦运算
-func (c *Calculator) Cos(value string) string {
-	v, _ := decimal.NewFromString(value)
-	floatVal := v.InexactFloat64()
-	cosVal := math.Cos(floatVal)
-	return decimal.NewFromFloat(cosVal).Round(c.precision).String()
+func (c *Calculator) Cos(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if c.onHalfTurn(v, c.quarterTurn()) {
+		return decimal.Zero, nil
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return cosDec(c.toRadians(v, prec+guardDigits), prec)
+	}), nil
 }
 
 // Tan 执行正切运算
-func (c *Calculator) Tan(value string) string {
-	v, _ := decimal.NewFromString(value)
-	// 由于 decimal 包不直接支持三角函数，我们需要先转换为 float64
-	floatVal := v.InexactFloat64()
-	tanVal := math.Tan(floatVal)
-	return decimal.NewFromFloat(tanVal).Round(c.precision).String()
+func (c *Calculator) Tan(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if c.onHalfTurn(v, c.quarterTurn()) {
+		return decimal.Zero, domainError("正切函数在 π/2 + kπ 处无定义")
+	}
+	if c.onHalfTurn(v, decimal.Zero) {
+		return decimal.Zero, nil
+	}
+	ok := true
+	res := c.approx(func(prec int32) decimal.Decimal {
+		var r decimal.Decimal
+		r, ok = tanDec(c.toRadians(v, prec+guardDigits), prec)
+		return r
+	})
+	if !ok {
+		return decimal.Zero, domainError("正切函数在 π/2 + kπ 处无定义")
+	}
+	return res, nil
 }
 
 // Asin 执行反正弦运算
-func (c *Calculator) Asin(value string) string {
-	v, _ := decimal.NewFromString(value)
-	floatVal := v.InexactFloat64()
-	if floatVal < -1 || floatVal > 1 {
-		panic("反正弦函数的输入必须在 [-1,1] 范围内")
+func (c *Calculator) Asin(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if v.Abs().GreaterThan(one) {
+		return decimal.Zero, domainError("反正弦函数的输入必须在 [-1,1] 范围内")
 	}
-	asinVal := math.Asin(floatVal)
-	return decimal.NewFromFloat(asinVal).Round(c.precision).String()
+	return c.approx(func(prec int32) decimal.Decimal {
+		return c.fromRadians(asinDec(v, prec+guardDigits), prec)
+	}), nil
 }
 
 // Acos 执行反余弦运算
-func (c *Calculator) Acos(value string) string {
-	v, _ := decimal.NewFromString(value)
-	floatVal := v.InexactFloat64()
-	if floatVal < -1 || floatVal > 1 {
-		panic("反余弦函数的输入必须在 [-1,1] 范围内")
+func (c *Calculator) Acos(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if v.Abs().GreaterThan(one) {
+		return decimal.Zero, domainError("反余弦函数的输入必须在 [-1,1] 范围内")
 	}
-	acosVal := math.Acos(floatVal)
-	return decimal.NewFromFloat(acosVal).Round(c.precision).String()
+	return c.approx(func(prec int32) decimal.Decimal {
+		return c.fromRadians(acosDec(v, prec+guardDigits), prec)
+	}), nil
 }
 
 // Atan 执行反正切运算
-func (c *Calculator) Atan(value string) string {
-	v, _ := decimal.NewFromString(value)
-	floatVal := v.InexactFloat64()
-	atanVal := math.Atan(floatVal)
-	return decimal.NewFromFloat(atanVal).Round(c.precision).String()
+func (c *Calculator) Atan(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return c.fromRadians(atanDec(v, prec+guardDigits), prec)
+	}), nil
 }
 
 // PI 返回π常量
-func (c *Calculator) PI() string {
-	pi, err := decimal.NewFromString("3.141592653589793238462643383279502884197169399375105820974944592307816406286")
-	if err != nil {
-		return "3.141592653589793"
-	}
-	return pi.Round(c.precision).String()
+func (c *Calculator) PI() decimal.Decimal {
+	return c.approx(piDec)
 }
 
 // E 返回自然对数e常量
-func (c *Calculator) E() string {
-	e, err := decimal.NewFromString("2.718281828459045235360287471352662497757247093699959574966967627724076630353")
-	if err != nil {
-		return "2.718281828459045"
+func (c *Calculator) E() decimal.Decimal {
+	// 内置常量比使用的位数至少多一位，以保证舍入正确
+	e, err := decimal.NewFromString("2.7182818284590452353602874713526624977572470936999595749669676277240766303535475945713821785251664274")
+	if err != nil || c.places(e) >= -e.Exponent() {
+		// 超出内置常量的位数时按 exp(1) 计算
+		return c.approx(func(prec int32) decimal.Decimal {
+			e, _ := expDec(one, prec)
+			return e
+		})
 	}
-	return e.Round(c.precision).String()
+	return c.round(e)
 }
 
 // Log 执行对数运算，支持自定义底数
-func (c *Calculator) Log(value, base string) string {
-    v, _ := decimal.NewFromString(value)
-    b, _ := decimal.NewFromString(base)
-    
-    if v.IsZero() || v.IsNegative() {
-        panic("对数的输入值必须为正数")
-    }
-    if b.IsZero() || b.IsNegative() || b.Equal(decimal.NewFromInt(1)) {
-        panic("对数的底数必须为正数且不等于1")
-    }
-
-    // 使用换底公式：log_b(x) = ln(x) / ln(b)
-    vFloat := v.InexactFloat64()
-    bFloat := b.InexactFloat64()
-    
-    logVal := math.Log(vFloat) / math.Log(bFloat)
-    return decimal.NewFromFloat(logVal).Round(c.precision).String()
+func (c *Calculator) Log(value, base string) (decimal.Decimal, error) {
+	v, b, err := parsePair(value, base)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if v.IsZero() || v.IsNegative() {
+		return decimal.Zero, domainError("对数的输入值必须为正数")
+	}
+	if b.IsZero() || b.IsNegative() || b.Equal(one) {
+		return decimal.Zero, domainError("对数的底数必须为正数且不等于1")
+	}
+
+	return c.approx(func(prec int32) decimal.Decimal {
+		return logDec(v, b, prec)
+	}), nil
 }
 
 // Ln 执行自然对数运算（以e为底）
-func (c *Calculator) Ln(value string) string {
-    v, _ := decimal.NewFromString(value)
-    if v.IsZero() || v.IsNegative() {
-        panic("自然对数的输入必须为正数")
-    }
-    floatVal := v.InexactFloat64()
-    logVal := math.Log(floatVal)
-    return decimal.NewFromFloat(logVal).Round(c.precision).String()
+func (c *Calculator) Ln(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if v.IsZero() || v.IsNegative() {
+		return decimal.Zero, domainError("自然对数的输入必须为正数")
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return lnDec(v, prec)
+	}), nil
+}
+
+// Format 按当前精度将计算结果格式化为字符串
+//
+// 小数位数模式下舍入后省略末尾的零，有效数字模式下固定保留 precision 位有效数字。
+func (c *Calculator) Format(value decimal.Decimal) string {
+	r := c.round(value)
+	if c.precisionMode == DecimalPlaces {
+		return r.String()
+	}
+	return r.StringFixed(c.places(r))
 }
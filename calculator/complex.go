@@ -0,0 +1,418 @@
+package calculator
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// Complex 表示复数 Re + Im·i
+type Complex struct {
+	Re decimal.Decimal // 实部
+	Im decimal.Decimal // 虚部
+}
+
+// NewComplex 创建复数 re + im·i
+func NewComplex(re, im decimal.Decimal) Complex {
+	return Complex{Re: re, Im: im}
+}
+
+// IsReal 判断复数的虚部是否为零
+func (z Complex) IsReal() bool {
+	return z.Im.IsZero()
+}
+
+// IsZero 判断复数是否为零
+func (z Complex) IsZero() bool {
+	return z.Re.IsZero() && z.Im.IsZero()
+}
+
+// imagUnit 虚数单位 i
+var imagUnit = Complex{Re: decimal.Zero, Im: one}
+
+// cadd 计算 z + w，结果精确
+func cadd(z, w Complex) Complex {
+	return Complex{Re: z.Re.Add(w.Re), Im: z.Im.Add(w.Im)}
+}
+
+// csub 计算 z - w，结果精确
+func csub(z, w Complex) Complex {
+	return Complex{Re: z.Re.Sub(w.Re), Im: z.Im.Sub(w.Im)}
+}
+
+// cmul 计算 z · w，结果精确
+func cmul(z, w Complex) Complex {
+	return Complex{
+		Re: z.Re.Mul(w.Re).Sub(z.Im.Mul(w.Im)),
+		Im: z.Re.Mul(w.Im).Add(z.Im.Mul(w.Re)),
+	}
+}
+
+// cround 将复数的实部和虚部都舍入到 prec 位小数
+func cround(z Complex, prec int32) Complex {
+	return Complex{Re: z.Re.Round(prec), Im: z.Im.Round(prec)}
+}
+
+// cnorm 计算 |z|²，结果精确
+func cnorm(z Complex) decimal.Decimal {
+	return z.Re.Mul(z.Re).Add(z.Im.Mul(z.Im))
+}
+
+// cdiv 计算 z / w，保留 prec 位小数，w 不能为零
+func cdiv(z, w Complex, prec int32) Complex {
+	n := cnorm(w)
+	num := cmul(z, Complex{Re: w.Re, Im: w.Im.Neg()})
+	return Complex{Re: num.Re.DivRound(n, prec), Im: num.Im.DivRound(n, prec)}
+}
+
+// cabs 计算 |z|，保留 prec 位小数
+func cabs(z Complex, prec int32) decimal.Decimal {
+	if z.Im.IsZero() {
+		return z.Re.Abs()
+	}
+	if z.Re.IsZero() {
+		return z.Im.Abs()
+	}
+	return sqrtDec(cnorm(z), prec)
+}
+
+// csqrt 计算 z 的主平方根，保留 prec 位小数
+func csqrt(z Complex, prec int32) Complex {
+	if z.IsZero() {
+		return z
+	}
+	wp := prec + guardDigits
+	r := cabs(z, wp)
+	// 按实部的符号选择不会产生相消的公式
+	if !z.Re.IsNegative() {
+		t := sqrtDec(r.Add(z.Re).DivRound(two, wp), wp)
+		return cround(Complex{Re: t, Im: z.Im.DivRound(t.Mul(two), wp)}, prec)
+	}
+	t := sqrtDec(r.Sub(z.Re).DivRound(two, wp), wp)
+	im := t
+	if z.Im.IsNegative() {
+		im = t.Neg()
+	}
+	return cround(Complex{Re: z.Im.Abs().DivRound(t.Mul(two), wp), Im: im}, prec)
+}
+
+// cexp 计算 e^z，保留 prec 位小数；ok 为 false 表示结果溢出
+func cexp(z Complex, prec int32) (res Complex, ok bool) {
+	wp := prec + guardDigits
+	m, ok := expDec(z.Re, wp)
+	if !ok {
+		return Complex{}, false
+	}
+	if z.Im.IsZero() {
+		return Complex{Re: m.Round(prec), Im: decimal.Zero}, true
+	}
+	// e^a 越大，cos b、sin b 需要的小数位越多
+	wp += intDigits(m)
+	return cround(Complex{Re: m.Mul(cosDec(z.Im, wp)), Im: m.Mul(sinDec(z.Im, wp))}, prec), true
+}
+
+// cln 计算 z 的主值自然对数 ln|z| + i·arg(z)，保留 prec 位小数，z 不能为零
+func cln(z Complex, prec int32) Complex {
+	wp := prec + guardDigits
+	re := lnDec(cnorm(z), wp).DivRound(two, prec)
+	return Complex{Re: re, Im: atan2Dec(z.Im, z.Re, prec)}
+}
+
+// cpow 计算 z^w 的主值，保留 prec 位小数；ok 为 false 表示结果溢出
+func cpow(z, w Complex, prec int32) (res Complex, ok bool) {
+	if w.IsZero() {
+		return Complex{Re: one, Im: decimal.Zero}, true
+	}
+	if z.IsZero() {
+		return z, true
+	}
+
+	// 指数为不大的整数时用快速幂精确计算
+	if w.IsReal() && w.Re.IsInteger() && w.Re.Abs().LessThanOrEqual(decimal.NewFromInt(64)) {
+		n := w.Re.Abs().IntPart()
+		res = Complex{Re: one, Im: decimal.Zero}
+		base := z
+		for ; n > 0; n >>= 1 {
+			if n&1 == 1 {
+				res = cmul(res, base)
+			}
+			base = cmul(base, base)
+		}
+		if w.Re.IsNegative() {
+			return cdiv(Complex{Re: one, Im: decimal.Zero}, res, prec), true
+		}
+		return cround(res, prec), true
+	}
+
+	// z^w = e^(w·ln z)，e^(w·ln z) 的模越大，对数需要的小数位越多
+	wp := prec + guardDigits
+	est := cmul(w, cln(z, guardDigits))
+	if digits := est.Re.DivRound(ln10Dec(guardDigits), 0); digits.IsPositive() {
+		if digits.GreaterThan(decimal.NewFromInt(maxApproxDigits)) {
+			return Complex{}, false
+		}
+		wp += int32(digits.IntPart())
+	}
+	wp += intDigits(cabs(w, guardDigits))
+	return cexp(cmul(w, cln(z, wp)), prec)
+}
+
+// csin 计算 sin(z) = sin a·cosh b + i·cos a·sinh b，保留 prec 位小数
+func csin(z Complex, prec int32) (res Complex, ok bool) {
+	wp := prec + guardDigits
+	ch, ok := coshDec(z.Im, wp)
+	if !ok {
+		return Complex{}, false
+	}
+	sh, _ := sinhDec(z.Im, wp)
+	wp += intDigits(ch)
+	return cround(Complex{Re: sinDec(z.Re, wp).Mul(ch), Im: cosDec(z.Re, wp).Mul(sh)}, prec), true
+}
+
+// ccos 计算 cos(z) = cos a·cosh b - i·sin a·sinh b，保留 prec 位小数
+func ccos(z Complex, prec int32) (res Complex, ok bool) {
+	wp := prec + guardDigits
+	ch, ok := coshDec(z.Im, wp)
+	if !ok {
+		return Complex{}, false
+	}
+	sh, _ := sinhDec(z.Im, wp)
+	wp += intDigits(ch)
+	return cround(Complex{Re: cosDec(z.Re, wp).Mul(ch), Im: sinDec(z.Re, wp).Mul(sh).Neg()}, prec), true
+}
+
+// casin 计算 arcsin(z) = -i·ln(iz + sqrt(1 - z²))，保留 prec 位小数
+func casin(z Complex, prec int32) Complex {
+	wp := prec + guardDigits
+	root := csqrt(csub(Complex{Re: one, Im: decimal.Zero}, cmul(z, z)), wp)
+	l := cln(cadd(cmul(imagUnit, z), root), wp)
+	return cround(Complex{Re: l.Im, Im: l.Re.Neg()}, prec)
+}
+
+// catan 计算 arctan(z) = i/2·(ln(1 - iz) - ln(1 + iz))，保留 prec 位小数，z 不能为 ±i
+func catan(z Complex, prec int32) Complex {
+	wp := prec + guardDigits
+	iz := cmul(imagUnit, z)
+	l := csub(cln(csub(Complex{Re: one, Im: decimal.Zero}, iz), wp), cln(cadd(Complex{Re: one, Im: decimal.Zero}, iz), wp))
+	// i/2·(x + yi) = -y/2 + x/2·i
+	return cround(Complex{Re: l.Im.Neg().DivRound(two, wp), Im: l.Re.DivRound(two, wp)}, prec)
+}
+
+// approxComplex 以足够的工作精度调用 f 近似计算复数结果，再分别舍入实部和虚部
+//
+// 与 approx 相同，有效数字模式下按实部和虚部中量级较小者提高工作精度后重新计算。
+func (c *Calculator) approxComplex(f func(prec int32) Complex) Complex {
+	wp := c.workPrecision()
+	res := f(wp)
+	if c.precisionMode == SignificantDigits {
+		for (res.Re.IsZero() || res.Im.IsZero()) && wp < maxSignificantPlaces {
+			wp *= 2
+			res = f(wp)
+		}
+		need := wp
+		for _, d := range []decimal.Decimal{res.Re, res.Im} {
+			if !d.IsZero() {
+				need = max(need, c.places(d)+guardDigits)
+			}
+		}
+		if need > wp {
+			res = f(need)
+		}
+	}
+	return Complex{Re: c.roundApprox(res.Re), Im: c.roundApprox(res.Im)}
+}
+
+// CAdd 执行复数加法运算
+func (c *Calculator) CAdd(z, w Complex) (Complex, error) {
+	res := cadd(z, w)
+	return Complex{Re: c.round(res.Re), Im: c.round(res.Im)}, nil
+}
+
+// CSubtract 执行复数减法运算
+func (c *Calculator) CSubtract(z, w Complex) (Complex, error) {
+	res := csub(z, w)
+	return Complex{Re: c.round(res.Re), Im: c.round(res.Im)}, nil
+}
+
+// CMultiply 执行复数乘法运算
+func (c *Calculator) CMultiply(z, w Complex) (Complex, error) {
+	res := cmul(z, w)
+	return Complex{Re: c.round(res.Re), Im: c.round(res.Im)}, nil
+}
+
+// CDivide 执行复数除法运算
+func (c *Calculator) CDivide(z, w Complex) (Complex, error) {
+	if w.IsZero() {
+		return Complex{}, ErrDivisionByZero
+	}
+	n := cnorm(w)
+	num := cmul(z, Complex{Re: w.Re, Im: w.Im.Neg()})
+	return Complex{Re: c.div(num.Re, n), Im: c.div(num.Im, n)}, nil
+}
+
+// CPower 执行复数乘方运算，返回主值
+func (c *Calculator) CPower(z, w Complex) (Complex, error) {
+	if z.IsZero() && (w.Re.IsNegative() || w.Re.IsZero() && !w.Im.IsZero()) {
+		return Complex{}, ErrDivisionByZero
+	}
+	if _, ok := cpow(z, w, c.workPrecision()); !ok {
+		return Complex{}, ErrOverflow
+	}
+	return c.approxComplex(func(prec int32) Complex {
+		res, _ := cpow(z, w, prec)
+		return res
+	}), nil
+}
+
+// CSqrt 执行复数开方运算，返回实部非负的主平方根
+func (c *Calculator) CSqrt(z Complex) (Complex, error) {
+	if z.IsReal() && z.Re.IsNegative() {
+		// 负实数的平方根为纯虚数，可以精确舍入
+		return Complex{Re: decimal.Zero, Im: c.sqrt(z.Re.Neg())}, nil
+	}
+	return c.approxComplex(func(prec int32) Complex {
+		return csqrt(z, prec)
+	}), nil
+}
+
+// CExp 执行复数的以e为底的指数运算
+func (c *Calculator) CExp(z Complex) (Complex, error) {
+	if _, ok := cexp(z, c.workPrecision()); !ok {
+		return Complex{}, ErrOverflow
+	}
+	return c.approxComplex(func(prec int32) Complex {
+		res, _ := cexp(z, prec)
+		return res
+	}), nil
+}
+
+// CLn 执行复数的自然对数运算，返回虚部在 (-π, π] 内的主值
+func (c *Calculator) CLn(z Complex) (Complex, error) {
+	if z.IsZero() {
+		return Complex{}, domainError("自然对数的输入不能为零")
+	}
+	return c.approxComplex(func(prec int32) Complex {
+		return cln(z, prec)
+	}), nil
+}
+
+// CLog 执行复数的对数运算，支持复数底数
+func (c *Calculator) CLog(z, base Complex) (Complex, error) {
+	if z.IsZero() {
+		return Complex{}, domainError("对数的输入值不能为零")
+	}
+	if base.IsZero() || base.IsReal() && base.Re.Equal(one) {
+		return Complex{}, domainError("对数的底数不能为零或1")
+	}
+	return c.approxComplex(func(prec int32) Complex {
+		return cdiv(cln(z, prec+guardDigits), cln(base, prec+guardDigits), prec)
+	}), nil
+}
+
+// CSin 执行复数的正弦运算
+func (c *Calculator) CSin(z Complex) (Complex, error) {
+	if _, ok := csin(c.ctoRadians(z, c.workPrecision()+guardDigits), c.workPrecision()); !ok {
+		return Complex{}, ErrOverflow
+	}
+	return c.approxComplex(func(prec int32) Complex {
+		res, _ := csin(c.ctoRadians(z, prec+guardDigits), prec)
+		return res
+	}), nil
+}
+
+// CCos 执行复数的余弦运算
+func (c *Calculator) CCos(z Complex) (Complex, error) {
+	if _, ok := ccos(c.ctoRadians(z, c.workPrecision()+guardDigits), c.workPrecision()); !ok {
+		return Complex{}, ErrOverflow
+	}
+	return c.approxComplex(func(prec int32) Complex {
+		res, _ := ccos(c.ctoRadians(z, prec+guardDigits), prec)
+		return res
+	}), nil
+}
+
+// CTan 执行复数的正切运算
+func (c *Calculator) CTan(z Complex) (Complex, error) {
+	wp := c.workPrecision() + guardDigits
+	r := c.ctoRadians(z, wp+guardDigits)
+	if _, ok := csin(r, wp); !ok {
+		return Complex{}, ErrOverflow
+	}
+	if co, _ := ccos(r, wp); cround(co, c.workPrecision()).IsZero() {
+		return Complex{}, domainError("正切函数在 π/2 + kπ 处无定义")
+	}
+	return c.approxComplex(func(prec int32) Complex {
+		wp := prec + guardDigits
+		r := c.ctoRadians(z, wp+guardDigits)
+		s, _ := csin(r, wp)
+		co, _ := ccos(r, wp)
+		return cdiv(s, co, prec)
+	}), nil
+}
+
+// CAsin 执行复数的反正弦运算
+func (c *Calculator) CAsin(z Complex) (Complex, error) {
+	return c.approxComplex(func(prec int32) Complex {
+		return c.cfromRadians(casin(z, prec+guardDigits), prec)
+	}), nil
+}
+
+// CAcos 执行复数的反余弦运算，arccos(z) = π/2 - arcsin(z)
+func (c *Calculator) CAcos(z Complex) (Complex, error) {
+	return c.approxComplex(func(prec int32) Complex {
+		wp := prec + guardDigits
+		s := casin(z, wp)
+		halfPi := piDec(wp).DivRound(two, wp)
+		return c.cfromRadians(Complex{Re: halfPi.Sub(s.Re), Im: s.Im.Neg()}, prec)
+	}), nil
+}
+
+// CAtan 执行复数的反正切运算
+func (c *Calculator) CAtan(z Complex) (Complex, error) {
+	if z.Re.IsZero() && z.Im.Abs().Equal(one) {
+		return Complex{}, domainError("反正切函数在 ±i 处无定义")
+	}
+	return c.approxComplex(func(prec int32) Complex {
+		return c.cfromRadians(catan(z, prec+guardDigits), prec)
+	}), nil
+}
+
+// Abs 计算复数的模
+func (c *Calculator) Abs(z Complex) (decimal.Decimal, error) {
+	if z.IsReal() {
+		return c.round(z.Re.Abs()), nil
+	}
+	return c.sqrt(cnorm(z)), nil
+}
+
+// Arg 计算复数的辐角，结果在 (-π, π] 范围内并按当前角度单位表示
+func (c *Calculator) Arg(z Complex) (decimal.Decimal, error) {
+	if z.IsZero() {
+		return decimal.Zero, domainError("零的辐角无定义")
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return c.fromRadians(atan2Dec(z.Im, z.Re, prec+guardDigits), prec)
+	}), nil
+}
+
+// Conj 计算共轭复数
+func (c *Calculator) Conj(z Complex) (Complex, error) {
+	return Complex{Re: c.round(z.Re), Im: c.round(z.Im.Neg())}, nil
+}
+
+// Polar 根据模 r 和辐角 theta 构造复数 r·(cos θ + i·sin θ)
+func (c *Calculator) Polar(r, theta decimal.Decimal) (Complex, error) {
+	return c.approxComplex(func(prec int32) Complex {
+		wp := prec + intDigits(r)
+		t := c.toRadians(theta, wp+guardDigits)
+		return Complex{Re: r.Mul(cosDec(t, wp)), Im: r.Mul(sinDec(t, wp))}
+	}), nil
+}
+
+// FormatComplex 按当前精度将复数格式化为 a + bi 的形式
+func (c *Calculator) FormatComplex(z Complex) string {
+	re := c.Format(z.Re)
+	im := c.Format(z.Im.Abs())
+	if c.round(z.Im).IsNegative() {
+		return re + " - " + im + "i"
+	}
+	return re + " + " + im + "i"
+}
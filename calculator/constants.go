@@ -0,0 +1,134 @@
+package calculator
+
+import (
+	"math"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// constCache 缓存某个数学常量已计算过的最高精度值
+type constCache struct {
+	sync.Mutex
+	prec    int32
+	value   decimal.Decimal
+	compute func(prec int32) decimal.Decimal
+}
+
+// get 返回保留 prec 位小数的常量值，必要时以更高精度重新计算
+func (c *constCache) get(prec int32) decimal.Decimal {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.prec < prec {
+		c.value = c.compute(prec + guardDigits)
+		c.prec = prec
+	}
+	return c.value.Round(prec)
+}
+
+var (
+	// π = 16·arccot(5) - 4·arccot(239)（Machin 公式）
+	piConst = &constCache{compute: func(prec int32) decimal.Decimal {
+		a := arccotInt(5, prec).Mul(decimal.NewFromInt(16))
+		b := arccotInt(239, prec).Mul(decimal.NewFromInt(4))
+		return a.Sub(b)
+	}}
+
+	// ln2 = 2·atanh(1/3)
+	ln2Const = &constCache{compute: func(prec int32) decimal.Decimal {
+		return atanhInv(3, prec).Mul(two)
+	}}
+
+	// ln10 = 3·ln2 + ln(5/4) = 3·ln2 + 2·atanh(1/9)
+	ln10Const = &constCache{compute: func(prec int32) decimal.Decimal {
+		return ln2Const.get(prec).Mul(decimal.NewFromInt(3)).Add(atanhInv(9, prec).Mul(two))
+	}}
+
+	// 欧拉-马歇罗尼常数 γ（Brent-McMillan 算法）
+	eulerConst = &constCache{compute: eulerGamma}
+)
+
+// piDec 返回保留 prec 位小数的π
+func piDec(prec int32) decimal.Decimal {
+	return piConst.get(prec)
+}
+
+// ln2Dec 返回保留 prec 位小数的 ln2
+func ln2Dec(prec int32) decimal.Decimal {
+	return ln2Const.get(prec)
+}
+
+// ln10Dec 返回保留 prec 位小数的 ln10
+func ln10Dec(prec int32) decimal.Decimal {
+	return ln10Const.get(prec)
+}
+
+// eulerDec 返回保留 prec 位小数的欧拉-马歇罗尼常数 γ
+func eulerDec(prec int32) decimal.Decimal {
+	return eulerConst.get(prec)
+}
+
+// eulerGamma 使用 Brent-McMillan 算法计算 γ = U/V
+//
+// A_0 = -ln n，B_0 = 1，B_k = B_(k-1)·n²/k²，A_k = (A_(k-1)·n²/k + B_k)/k，
+// U = ΣA_k，V = ΣB_k，误差约为 e^(-4n)。
+func eulerGamma(prec int32) decimal.Decimal {
+	n := int64(float64(prec)*math.Ln10/4) + 2
+	// B_k 最大约为 e^(2n)，需要额外的位数
+	wp := prec + guardDigits + int32(float64(2*n)/math.Ln10) + 1
+	nd := decimal.NewFromInt(n)
+	n2 := decimal.NewFromInt(n * n)
+
+	a := lnDec(nd, wp).Neg()
+	b := one
+	u, v := a, b
+	for k := int64(1); ; k++ {
+		kd := decimal.NewFromInt(k)
+		b = b.Mul(n2).DivRound(decimal.NewFromInt(k*k), wp)
+		a = a.Mul(n2).DivRound(kd, wp).Add(b).DivRound(kd, wp)
+		if k > n && a.IsZero() && b.IsZero() {
+			break
+		}
+		u = u.Add(a)
+		v = v.Add(b)
+	}
+	return u.DivRound(v, prec)
+}
+
+// arccotInt 使用级数 arccot(n) = Σ (-1)^k / ((2k+1)·n^(2k+1)) 计算反余切
+func arccotInt(n int64, prec int32) decimal.Decimal {
+	nd := decimal.NewFromInt(n)
+	n2 := decimal.NewFromInt(n * n)
+	power := one.DivRound(nd, prec) // 1/n^(2k+1)
+	sum := power
+	for k := int64(1); ; k++ {
+		power = power.DivRound(n2, prec)
+		if power.IsZero() {
+			break
+		}
+		term := power.DivRound(decimal.NewFromInt(2*k+1), prec)
+		if k%2 == 1 {
+			sum = sum.Sub(term)
+		} else {
+			sum = sum.Add(term)
+		}
+	}
+	return sum
+}
+
+// atanhInv 使用级数 atanh(1/n) = Σ 1 / ((2k+1)·n^(2k+1)) 计算反双曲正切
+func atanhInv(n int64, prec int32) decimal.Decimal {
+	nd := decimal.NewFromInt(n)
+	n2 := decimal.NewFromInt(n * n)
+	power := one.DivRound(nd, prec)
+	sum := power
+	for k := int64(1); ; k++ {
+		power = power.DivRound(n2, prec)
+		if power.IsZero() {
+			break
+		}
+		sum = sum.Add(power.DivRound(decimal.NewFromInt(2*k+1), prec))
+	}
+	return sum
+}
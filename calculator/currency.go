@@ -0,0 +1,309 @@
+package calculator
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// currencyCodePattern 匹配 ISO 4217 货币代码，如 USD、CNY
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// defaultMinorUnits 未列出的货币保留的小数位数
+const defaultMinorUnits = 2
+
+// minorUnits 最小货币单位不是百分之一的货币及其小数位数
+var minorUnits = map[string]int32{
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0, "UGX": 0, "PYG": 0, "XAF": 0, "XOF": 0,
+	"KWD": 3, "BHD": 3, "OMR": 3, "JOD": 3, "TND": 3, "LYD": 3, "IQD": 3,
+}
+
+// IsCurrencyCode 判断 code 是否符合 ISO 4217 货币代码的格式，不检查汇率表中是否有该货币
+func IsCurrencyCode(code string) bool {
+	return currencyCodePattern.MatchString(code)
+}
+
+// MinorUnits 返回货币金额保留的小数位数，如 JPY 为 0、USD 为 2、KWD 为 3
+func MinorUnits(code string) int32 {
+	if n, ok := minorUnits[code]; ok {
+		return n
+	}
+	return defaultMinorUnits
+}
+
+// Rates 表示某一时刻的汇率表，创建后不再修改，可以在多个 goroutine 中并发使用
+//
+// 汇率以基准货币表示：1 个基准货币可以兑换 rate 个该货币，基准货币自身的汇率为 1。
+type Rates struct {
+	Base  string // 基准货币
+	AsOf  string // 汇率的日期
+	rates map[string]decimal.Decimal
+}
+
+// NewRates 创建汇率表，rates 中缺少基准货币时自动补充为 1
+func NewRates(base, asOf string, rates map[string]decimal.Decimal) (*Rates, error) {
+	if !currencyCodePattern.MatchString(base) {
+		return nil, fmt.Errorf("无效的货币代码: %q", base)
+	}
+	res := &Rates{Base: base, AsOf: asOf, rates: map[string]decimal.Decimal{base: one}}
+	for code, rate := range rates {
+		if !currencyCodePattern.MatchString(code) {
+			return nil, fmt.Errorf("无效的货币代码: %q", code)
+		}
+		if !rate.IsPositive() {
+			return nil, fmt.Errorf("%s的汇率必须为正数", code)
+		}
+		if code == base && !rate.Equal(one) {
+			return nil, fmt.Errorf("基准货币%s的汇率必须为1", base)
+		}
+		res.rates[code] = rate
+	}
+	return res, nil
+}
+
+// Rate 返回 1 个基准货币可以兑换的 code 货币数量
+func (r *Rates) Rate(code string) (decimal.Decimal, bool) {
+	if r == nil {
+		return decimal.Zero, false
+	}
+	rate, ok := r.rates[code]
+	return rate, ok
+}
+
+// Currencies 返回汇率表中的全部货币代码，按字母顺序排列
+func (r *Rates) Currencies() []string {
+	if r == nil {
+		return nil
+	}
+	codes := make([]string, 0, len(r.rates))
+	for code := range r.rates {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Update 返回合并了新汇率的汇率表，原汇率表保持不变
+//
+// rates 以 base 表示，base 必须已在汇率表中，rates 中 base 自身的汇率只能为 1；汇率表为空（nil）时 base 成为新的基准货币。
+// 汇率表的基准货币的汇率始终为 1，rates 中给出的基准货币汇率只用于报价，不会改变汇率表，
+// 如以 EUR 报价的 USD 1.087 与汇率表中 EUR 0.92 的乘积 1.00004 不会导致错误。
+func (r *Rates) Update(base, asOf string, rates map[string]decimal.Decimal) (*Rates, error) {
+	if r == nil {
+		return NewRates(base, asOf, rates)
+	}
+	factor, ok := r.rates[base]
+	if !ok {
+		return nil, fmt.Errorf("汇率表中没有货币%s", base)
+	}
+	if rate, ok := rates[base]; ok && !rate.Equal(one) {
+		return nil, fmt.Errorf("报价货币%s的汇率必须为1", base)
+	}
+	merged := make(map[string]decimal.Decimal, len(r.rates)+len(rates))
+	for code, rate := range r.rates {
+		merged[code] = rate
+	}
+	for code, rate := range rates {
+		if code == r.Base || code == base {
+			continue
+		}
+		merged[code] = rate.Mul(factor)
+	}
+	return NewRates(r.Base, asOf, merged)
+}
+
+// inferBase 返回汇率为 1 的货币中字母顺序最小的一个，作为未指定基准货币时的基准
+func inferBase(rates map[string]decimal.Decimal) (string, error) {
+	var base string
+	for code, rate := range rates {
+		if rate.Equal(one) && (base == "" || code < base) {
+			base = code
+		}
+	}
+	if base == "" {
+		return "", errors.New("汇率表中缺少汇率为1的基准货币")
+	}
+	return base, nil
+}
+
+// ParseRatesJSON 解析 JSON 格式的汇率表
+//
+// 格式为 {"base": "USD", "as_of": "2024-01-31", "rates": {"EUR": 0.92, "CNY": "7.18"}}，
+// 汇率可以写作数字或字符串；省略 base 时以汇率为 1 的货币为基准。
+func ParseRatesJSON(data []byte) (*Rates, error) {
+	var file struct {
+		Base  string                 `json:"base"`
+		AsOf  string                 `json:"as_of"`
+		Rates map[string]json.Number `json:"rates"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("无法解析汇率表: %w", err)
+	}
+	rates := make(map[string]decimal.Decimal, len(file.Rates))
+	for code, value := range file.Rates {
+		rate, err := ParseNumber(value.String())
+		if err != nil {
+			return nil, fmt.Errorf("%s的汇率无效: %w", code, err)
+		}
+		rates[code] = rate
+	}
+	base := file.Base
+	if base == "" {
+		var err error
+		if base, err = inferBase(rates); err != nil {
+			return nil, err
+		}
+	}
+	return NewRates(base, file.AsOf, rates)
+}
+
+// ParseRatesCSV 解析 CSV 格式的汇率表
+//
+// 每行依次为货币代码、汇率和可选的日期，如 EUR,0.92,2024-01-31；第一行可以是表头。
+// 以汇率为 1 的货币为基准，日期取各行中最晚的一个。
+func ParseRatesCSV(r io.Reader) (*Rates, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("无法解析汇率表: %w", err)
+	}
+	rates := map[string]decimal.Decimal{}
+	asOf := ""
+	for i, record := range records {
+		if len(record) < 2 {
+			return nil, fmt.Errorf("汇率表第%d行至少需要货币代码和汇率两列", i+1)
+		}
+		rate, err := ParseNumber(strings.TrimSpace(record[1]))
+		if err != nil {
+			if i == 0 {
+				continue // 表头
+			}
+			return nil, fmt.Errorf("汇率表第%d行的汇率无效: %w", i+1, err)
+		}
+		rates[strings.TrimSpace(record[0])] = rate
+		if len(record) > 2 {
+			if date := strings.TrimSpace(record[2]); date > asOf {
+				asOf = date
+			}
+		}
+	}
+	base, err := inferBase(rates)
+	if err != nil {
+		return nil, err
+	}
+	return NewRates(base, asOf, rates)
+}
+
+// maxRatesFileSize 汇率表文件的最大字节数
+const maxRatesFileSize = 1 << 20
+
+// LoadRates 根据扩展名读取 JSON 或 CSV 格式的汇率表文件，文件中没有日期时使用文件的修改日期
+//
+// 读取前先检查扩展名，文件不能超过 maxRatesFileSize 字节。
+func LoadRates(path string) (*Rates, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".json" && ext != ".csv" {
+		return nil, fmt.Errorf("不支持的汇率表格式: %s", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() || info.Size() > maxRatesFileSize {
+		return nil, fmt.Errorf("汇率表文件必须是不超过 %d 字节的普通文件: %s", maxRatesFileSize, path)
+	}
+	data, err := io.ReadAll(io.LimitReader(f, maxRatesFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxRatesFileSize {
+		return nil, fmt.Errorf("汇率表文件必须是不超过 %d 字节的普通文件: %s", maxRatesFileSize, path)
+	}
+
+	var rates *Rates
+	if ext == ".json" {
+		rates, err = ParseRatesJSON(data)
+	} else {
+		rates, err = ParseRatesCSV(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rates.AsOf == "" {
+		rates.AsOf = info.ModTime().UTC().Format("2006-01-02")
+	}
+	return rates, nil
+}
+
+// WithRates 设置货币换算使用的汇率表，默认不支持货币
+func WithRates(rates *Rates) Option {
+	return func(c *Calculator) {
+		c.rates = rates
+	}
+}
+
+// Rates 返回计算器使用的汇率表，没有设置时返回 nil
+func (c *Calculator) Rates() *Rates {
+	return c.rates
+}
+
+// IsCurrency 判断 code 是否为汇率表中的货币
+func (c *Calculator) IsCurrency(code string) bool {
+	_, ok := c.rates.Rate(code)
+	return ok
+}
+
+// RoundMoney 按当前舍入方式将 code 货币的金额舍入到最小货币单位
+func (c *Calculator) RoundMoney(amount decimal.Decimal, code string) decimal.Decimal {
+	return roundDec(amount, MinorUnits(code), c.rounding)
+}
+
+// FormatMoney 将 code 货币的金额格式化为保留最小货币单位的字符串，如 123.45 USD
+func (c *Calculator) FormatMoney(amount decimal.Decimal, code string) string {
+	return c.RoundMoney(amount, code).StringFixed(MinorUnits(code)) + " " + code
+}
+
+// DivideMoney 将金额除以 x，结果按工作精度舍入，格式化时再舍入到最小货币单位
+func (c *Calculator) DivideMoney(amount, x decimal.Decimal) (decimal.Decimal, error) {
+	if x.IsZero() {
+		return decimal.Zero, ErrDivisionByZero
+	}
+	return divRound(amount, x, c.workPrecision(), c.rounding), nil
+}
+
+// ConvertCurrency 将 from 货币的金额换算为 to 货币，结果按工作精度舍入
+//
+// 换算结果可能继续参与运算，因此不舍入到 to 的最小货币单位，以免多次舍入；格式化时由 FormatMoney 舍入。
+func (c *Calculator) ConvertCurrency(amount decimal.Decimal, from, to string) (decimal.Decimal, error) {
+	rf, ok := c.rates.Rate(from)
+	if !ok {
+		return decimal.Zero, fmt.Errorf("%w: 汇率表中没有货币%s", ErrUnitMismatch, from)
+	}
+	rt, ok := c.rates.Rate(to)
+	if !ok {
+		return decimal.Zero, fmt.Errorf("%w: 汇率表中没有货币%s", ErrUnitMismatch, to)
+	}
+	if from == to {
+		return amount, nil
+	}
+	return divRound(amount.Mul(rt), rf, c.workPrecision(), c.rounding), nil
+}
@@ -0,0 +1,263 @@
+package calculator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxEigenIterations 求每个特征值时允许的最大 QR 迭代次数
+const maxEigenIterations = 500
+
+// maxEigenDim 计算特征值的方阵允许的最大阶数，每次 QR 迭代的开销随阶数的立方增长
+const maxEigenDim = 20
+
+// Eigen 以带位移的 QR 迭代计算方阵的实特征值与对应的单位特征向量
+//
+// 迭代在工作精度下进行，结果按当前精度舍入。特征值按从大到小排列，
+// vectors 的第 i 列是第 i 个特征值的特征向量，其绝对值最大的分量为正。
+// 矩阵有复特征值时返回 ErrDomain，迭代不收敛时返回 ErrNotConverged。
+// 矩阵不可对角化（如 [[1, 1], [0, 1]]）时按当前精度舍入后的特征向量线性相关，
+// 此时仍返回特征值，vectors 为 nil，err 为 ErrDefective。
+func (c *Calculator) Eigen(m Matrix) (values []decimal.Decimal, vectors Matrix, err error) {
+	if err := m.square("特征值"); err != nil {
+		return nil, nil, err
+	}
+	n := m.Rows()
+	if n > maxEigenDim {
+		return nil, nil, fmt.Errorf("%w: 特征值只支持不超过 %d 阶的方阵", ErrDimensionMismatch, maxEigenDim)
+	}
+	prec := c.workPrecision()
+	scale := one
+	for _, x := range m.elements() {
+		scale = decimal.Max(scale, x.Abs())
+	}
+	tol := scale.Shift(-(c.precision + guardDigits/2))
+
+	a := mapMatrix(n, n, func(i, j int) decimal.Decimal { return m[i][j] })
+	q := mapMatrix(n, n, func(i, j int) decimal.Decimal {
+		if i == j {
+			return one
+		}
+		return decimal.Zero
+	})
+	for k := n - 1; k > 0; k-- {
+		iter := 0
+		for !negligible(a[k][:k], tol) {
+			if iter++; iter > maxEigenIterations {
+				return nil, nil, ErrNotConverged
+			}
+			shift, pair := wilkinsonShift(a, k, prec)
+			if pair && negligible(a[k][:k-1], tol) && (k == 1 || negligible(a[k-1][:k-1], tol)) {
+				return nil, nil, domainError("矩阵有复特征值")
+			}
+			if iter%10 == 0 {
+				// 长时间不收敛时改用例外位移打破循环
+				shift = shift.Add(a[k][k-1].Abs().Mul(decimal.New(75, -2)))
+			}
+			qrStep(a, q, k, shift, prec)
+		}
+	}
+
+	type eigenpair struct {
+		value  decimal.Decimal
+		vector []decimal.Decimal
+	}
+	pairs := make([]eigenpair, n)
+	for i := range pairs {
+		pairs[i] = eigenpair{a[i][i], eigenvector(a, q, i, tol, prec)}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].value.GreaterThan(pairs[j].value)
+	})
+	values = make([]decimal.Decimal, n)
+	for i, p := range pairs {
+		values[i] = c.roundApprox(p.value)
+	}
+	vectors = mapMatrix(n, n, func(i, j int) decimal.Decimal {
+		return c.roundApprox(pairs[j].vector[i])
+	})
+	if rank, _ := c.Rank(vectors); rank < n {
+		return values, nil, ErrDefective
+	}
+	return values, vectors, nil
+}
+
+// negligible 判断 xs 中各元素的绝对值是否都不超过 tol
+func negligible(xs []decimal.Decimal, tol decimal.Decimal) bool {
+	for _, x := range xs {
+		if x.Abs().GreaterThan(tol) {
+			return false
+		}
+	}
+	return true
+}
+
+// wilkinsonShift 返回左上角 (k+1)×(k+1) 子矩阵右下角 2×2 块中更接近 a[k][k] 的特征值
+//
+// 该 2×2 块有一对复特征值时返回 a[k][k]，并将 pair 置为 true。
+func wilkinsonShift(a Matrix, k int, prec int32) (shift decimal.Decimal, pair bool) {
+	p, b, r, d := a[k-1][k-1], a[k-1][k], a[k][k-1], a[k][k]
+	delta := p.Sub(d).Mul(oneHalf)
+	disc := delta.Mul(delta).Add(b.Mul(r)).Round(prec)
+	if disc.IsNegative() {
+		return d, true
+	}
+	root := sqrtDec(disc, prec)
+	if delta.IsNegative() {
+		root = root.Neg()
+	}
+	den := delta.Add(root)
+	if den.IsZero() {
+		return d, false
+	}
+	return d.Sub(b.Mul(r).DivRound(den, prec)), false
+}
+
+// qrStep 对左上角 (k+1)×(k+1) 子矩阵做一次位移为 shift 的 QR 迭代
+//
+// 以 Householder 变换求 a - shift·I 的 QR 分解中的正交矩阵 Q，再将 a 就地替换为相似矩阵 Qᵀ·a·Q，
+// 并把 Q 累乘到 q 上。
+func qrStep(a, q Matrix, k int, shift decimal.Decimal, prec int32) {
+	size := k + 1
+	r := mapMatrix(size, size, func(i, j int) decimal.Decimal {
+		if i == j {
+			return a[i][j].Sub(shift)
+		}
+		return a[i][j]
+	})
+	h := mapMatrix(size, size, func(i, j int) decimal.Decimal {
+		if i == j {
+			return one
+		}
+		return decimal.Zero
+	})
+	for j := 0; j < k; j++ {
+		v := make([]decimal.Decimal, size-j)
+		sum := decimal.Zero
+		for i := range v {
+			v[i] = r[j+i][j]
+			sum = sum.Add(v[i].Mul(v[i]))
+		}
+		norm := sqrtDec(sum.Round(prec), prec)
+		if norm.IsZero() {
+			continue
+		}
+		if v[0].IsNegative() {
+			v[0] = v[0].Sub(norm)
+		} else {
+			v[0] = v[0].Add(norm)
+		}
+		vv := decimal.Zero
+		for _, x := range v {
+			vv = vv.Add(x.Mul(x))
+		}
+		// H = I - 2·v·vᵀ/(vᵀ·v)，左乘 r 并右乘 h
+		for col := range r[0] {
+			s := decimal.Zero
+			for i, x := range v {
+				s = s.Add(x.Mul(r[j+i][col]))
+			}
+			f := s.Mul(two).DivRound(vv, prec)
+			for i, x := range v {
+				r[j+i][col] = r[j+i][col].Sub(f.Mul(x)).Round(prec)
+			}
+		}
+		reflectColumns(h, v, j, vv, prec)
+	}
+
+	// a ← Qᵀ·a·Q，Q 只作用于前 size 行和前 size 列
+	for col := range a[0] {
+		res := make([]decimal.Decimal, size)
+		for i := range res {
+			s := decimal.Zero
+			for l := 0; l < size; l++ {
+				s = s.Add(h[l][i].Mul(a[l][col]))
+			}
+			res[i] = s.Round(prec)
+		}
+		for i, x := range res {
+			a[i][col] = x
+		}
+	}
+	multiplyColumns(a, h, prec)
+	multiplyColumns(q, h, prec)
+}
+
+// reflectColumns 将 Householder 变换 I - 2·v·vᵀ/vv 作用于 m 从第 j 列开始的各列，即 m ← m·H
+func reflectColumns(m Matrix, v []decimal.Decimal, j int, vv decimal.Decimal, prec int32) {
+	for _, row := range m {
+		s := decimal.Zero
+		for i, x := range v {
+			s = s.Add(row[j+i].Mul(x))
+		}
+		f := s.Mul(two).DivRound(vv, prec)
+		for i, x := range v {
+			row[j+i] = row[j+i].Sub(f.Mul(x)).Round(prec)
+		}
+	}
+}
+
+// multiplyColumns 将 m 的前 len(h) 列就地替换为其与 h 之积
+func multiplyColumns(m, h Matrix, prec int32) {
+	size := len(h)
+	for _, row := range m {
+		res := make([]decimal.Decimal, size)
+		for j := range res {
+			s := decimal.Zero
+			for l := 0; l < size; l++ {
+				s = s.Add(row[l].Mul(h[l][j]))
+			}
+			res[j] = s.Round(prec)
+		}
+		copy(row, res)
+	}
+}
+
+// eigenvector 由上三角矩阵 t 回代求第 i 个特征值对应的特征向量，再用 q 变换回原矩阵的坐标
+//
+// 结果为单位向量，绝对值最大的分量为正。
+func eigenvector(t, q Matrix, i int, tol decimal.Decimal, prec int32) []decimal.Decimal {
+	lambda := t[i][i]
+	y := make([]decimal.Decimal, len(t))
+	y[i] = one
+	for j := i - 1; j >= 0; j-- {
+		s := decimal.Zero
+		for k := j + 1; k <= i; k++ {
+			s = s.Add(t[j][k].Mul(y[k]))
+		}
+		den := t[j][j].Sub(lambda)
+		if den.Abs().LessThanOrEqual(tol) {
+			if s.Abs().LessThanOrEqual(tol) {
+				continue
+			}
+			// 重特征值处分母为零，以容差代替以得到近似的特征向量
+			den = tol
+		}
+		y[j] = s.Neg().DivRound(den, prec)
+	}
+
+	x := make([]decimal.Decimal, len(q))
+	sum := decimal.Zero
+	largest := 0
+	for r := range x {
+		s := decimal.Zero
+		for k := 0; k <= i; k++ {
+			s = s.Add(q[r][k].Mul(y[k]))
+		}
+		x[r] = s.Round(prec)
+		sum = sum.Add(x[r].Mul(x[r]))
+		if x[r].Abs().GreaterThan(x[largest].Abs()) {
+			largest = r
+		}
+	}
+	norm := sqrtDec(sum.Round(prec), prec)
+	if x[largest].IsNegative() {
+		norm = norm.Neg()
+	}
+	for r := range x {
+		x[r] = x[r].DivRound(norm, prec)
+	}
+	return x
+}
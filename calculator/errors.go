@@ -0,0 +1,52 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// 计算过程中可能返回的错误类型，可以通过 errors.Is 判断
+var (
+	ErrDivisionByZero    = errors.New("除数不能为零")
+	ErrDomain            = errors.New("输入超出函数定义域")
+	ErrInvalidNumber     = errors.New("无效的数字")
+	ErrOverflow          = errors.New("计算结果溢出")
+	ErrNotConverged      = errors.New("迭代求解未收敛")
+	ErrUnitMismatch      = errors.New("单位不兼容")
+	ErrDimensionMismatch = errors.New("矩阵维数不匹配")
+	ErrSingular          = errors.New("矩阵奇异，不可逆")
+	ErrUnderdetermined   = errors.New("方程组欠定，有无穷多解")
+	ErrInconsistent      = errors.New("方程组矛盾，无解")
+	ErrDefective         = errors.New("矩阵不可对角化，特征向量线性相关")
+)
+
+// domainError 返回带有具体说明的定义域错误
+func domainError(msg string) error {
+	return fmt.Errorf("%w: %s", ErrDomain, msg)
+}
+
+// ParseNumber 将字符串解析为十进制数，支持科学计数法如 1e-30
+//
+// 格式不合法时返回 ErrInvalidNumber；指数的绝对值超过 maxResultDigits 时返回 ErrOverflow，
+// 以免 1e1000000000 这样的常量在运算中展开为过多的位数。
+func ParseNumber(value string) (decimal.Decimal, error) {
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("%w: %q", ErrInvalidNumber, value)
+	}
+	if e := d.Exponent(); e > maxResultDigits || e < -maxResultDigits {
+		return decimal.Zero, fmt.Errorf("%w: %q 的指数过大", ErrOverflow, value)
+	}
+	return d, nil
+}
+
+// parsePair 解析二元运算的两个操作数
+func parsePair(left, right string) (l, r decimal.Decimal, err error) {
+	if l, err = ParseNumber(left); err != nil {
+		return
+	}
+	r, err = ParseNumber(right)
+	return
+}
@@ -0,0 +1,162 @@
+package calculator
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// maxResultDigits 阶乘、移位等精确整数运算结果允许的最大位数
+const maxResultDigits = 100000
+
+// maxApproxDigits 指数、乘方等近似计算结果整数部分允许的最大位数
+//
+// 近似计算需要按结果的位数提高工作精度，耗时随位数迅速增长，因此上限远小于 maxResultDigits。
+const maxApproxDigits = 10000
+
+// expHalvings 计算 exp 时参数折半的次数，折半后泰勒级数收敛更快
+const expHalvings = 8
+
+// expSmall 计算 |x| ≤ ln10 时的 exp(x)，保留 prec 位小数
+func expSmall(x decimal.Decimal, prec int32) decimal.Decimal {
+	// exp(x) = exp(x / 2^m)^(2^m)，每次平方误差翻倍，因此多保留几位
+	wp := prec + 3
+	r := x.DivRound(decimal.NewFromInt(1<<expHalvings), wp)
+
+	term := one
+	sum := one
+	for k := int64(1); ; k++ {
+		term = term.Mul(r).DivRound(decimal.NewFromInt(k), wp)
+		if term.IsZero() {
+			break
+		}
+		sum = sum.Add(term)
+	}
+	for i := 0; i < expHalvings; i++ {
+		sum = sum.Mul(sum).Round(wp)
+	}
+	return sum.Round(prec)
+}
+
+// expDec 计算 exp(x)，保留 prec 位小数；ok 为 false 表示结果溢出
+func expDec(x decimal.Decimal, prec int32) (res decimal.Decimal, ok bool) {
+	if x.IsZero() {
+		return one, true
+	}
+
+	// x = k·ln10 + r，exp(x) = 10^k · exp(r)
+	k := x.DivRound(ln10Dec(20), 0)
+	if k.GreaterThan(decimal.NewFromInt(maxApproxDigits)) {
+		return decimal.Zero, false
+	}
+	// exp(r) < 10，因此 k < -(prec+1) 时结果舍入后必为 0
+	if k.LessThan(decimal.NewFromInt(int64(-prec - 1))) {
+		return decimal.Zero, true
+	}
+
+	shift := int32(k.IntPart())
+	wp := prec + guardDigits
+	if shift > 0 {
+		wp += shift
+	}
+	ln10 := ln10Dec(wp + intDigits(k))
+	r := x.Sub(k.Mul(ln10)).Round(wp)
+	return expSmall(r, wp).Shift(shift).Round(prec), true
+}
+
+// lnDec 计算 ln(x)，要求 x > 0，保留 prec 位小数
+func lnDec(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+
+	// x = a·10^e，a ∈ [1, 10)
+	e := int32(x.NumDigits()) + x.Exponent() - 1
+	a := x.Shift(-e)
+
+	// a = 2^j·b，b ∈ [0.75, 1.5)
+	half := decimal.New(5, -1)
+	limit := decimal.New(15, -1)
+	j := int64(0)
+	for a.GreaterThan(limit) {
+		a = a.Mul(half)
+		j++
+	}
+
+	// ln(b) = 2·atanh(z)，z = (b-1)/(b+1)，|z| ≤ 0.2
+	z := a.Sub(one).DivRound(a.Add(one), wp)
+	z2 := z.Mul(z).Round(wp)
+	power := z
+	sum := z
+	for k := int64(1); ; k++ {
+		power = power.Mul(z2).Round(wp)
+		term := power.DivRound(decimal.NewFromInt(2*k+1), wp)
+		if term.IsZero() {
+			break
+		}
+		sum = sum.Add(term)
+	}
+	res := sum.Mul(two)
+
+	if j != 0 {
+		res = res.Add(ln2Dec(wp).Mul(decimal.NewFromInt(j)))
+	}
+	if e != 0 {
+		ed := decimal.NewFromInt32(e)
+		res = res.Add(ln10Dec(wp + intDigits(ed)).Mul(ed))
+	}
+	return res.Round(prec)
+}
+
+// logDec 使用换底公式 log_b(x) = ln(x) / ln(b) 计算对数，要求 x > 0、b > 0 且 b ≠ 1
+func logDec(x, b decimal.Decimal, prec int32) decimal.Decimal {
+	// ln(b) 越接近 0、商越大，需要的工作精度越高
+	lb := lnDec(b, guardDigits)
+	lx := lnDec(x, guardDigits)
+	wp := prec + guardDigits + leadingZeros(lb) + intDigits(lx)
+
+	lb = lnDec(b, wp+leadingZeros(lb))
+	lx = lnDec(x, wp)
+	return lx.DivRound(lb, prec)
+}
+
+// maxExactPowDigits 精确计算整数次幂时结果允许的最大位数
+const maxExactPowDigits = 20000
+
+// powExact 在指数为整数且结果规模不大时精确计算 b^|e|，ok 为 false 表示需要近似计算
+func powExact(b, e decimal.Decimal) (res decimal.Decimal, ok bool) {
+	if !e.IsInteger() || intDigits(e) >= 10 || int64(b.NumDigits())*e.Abs().IntPart() > maxExactPowDigits {
+		return decimal.Zero, false
+	}
+	if b.IsZero() {
+		if e.IsZero() {
+			return one, true
+		}
+		return decimal.Zero, true
+	}
+	res, _ = b.PowBigInt(e.Abs().BigInt())
+	return res, true
+}
+
+// powDec 计算 b^e，保留 prec 位小数；b < 0 时 e 必须为整数，ok 为 false 表示结果溢出
+func powDec(b, e decimal.Decimal, prec int32) (res decimal.Decimal, ok bool) {
+	if e.IsZero() {
+		return one, true
+	}
+	if b.IsZero() {
+		return decimal.Zero, true
+	}
+
+	wp := prec + guardDigits
+	// b^e = exp(e·ln|b|)，先以低精度估计结果的量级
+	y := e.Mul(lnDec(b.Abs(), guardDigits))
+	digits := y.DivRound(ln10Dec(guardDigits), 0)
+	if digits.GreaterThan(decimal.NewFromInt(maxApproxDigits)) {
+		return decimal.Zero, false
+	}
+	if digits.IsPositive() {
+		wp += int32(digits.IntPart())
+	}
+	y = e.Mul(lnDec(b.Abs(), wp+intDigits(e)))
+	res, ok = expDec(y, prec)
+	if b.IsNegative() && !e.Mod(two).IsZero() {
+		res = res.Neg()
+	}
+	return res, ok
+}
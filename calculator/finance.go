@@ -0,0 +1,326 @@
+package calculator
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultRateGuess 求解利率时的默认初值，与电子表格一致
+var defaultRateGuess = decimal.New(1, -1)
+
+// newton 使用牛顿迭代从 x0 开始求解 f(x) = 0，f 返回函数值、导数值以及 x 是否有效
+//
+// 迭代步长小于 10^(-prec) 时停止；导数为零、x 无效或超过最大迭代次数时返回 ErrNotConverged。
+func newton(name string, f func(x decimal.Decimal) (fx, dfx decimal.Decimal, ok bool), x0 decimal.Decimal, prec int32) (decimal.Decimal, error) {
+	eps := decimal.New(1, -prec)
+	x := x0
+	for i := 0; i < maxSolverIterations; i++ {
+		fx, dfx, ok := f(x)
+		if !ok || dfx.IsZero() {
+			break
+		}
+		step := fx.DivRound(dfx, prec)
+		x = x.Sub(step)
+		if step.Abs().LessThan(eps) {
+			return x, nil
+		}
+	}
+	return decimal.Zero, fmt.Errorf("%w: %s在%d次迭代内没有找到解", ErrNotConverged, name, maxSolverIterations)
+}
+
+// maxIntDigits 返回各数绝对值整数部分的最大位数
+func maxIntDigits(values ...decimal.Decimal) int32 {
+	var res int32
+	for _, v := range values {
+		if d := intDigits(v.Abs()); d > res {
+			res = d
+		}
+	}
+	return res
+}
+
+// parseRate 解析利率，要求大于 -1
+func parseRate(value, name string) (decimal.Decimal, error) {
+	r, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if r.LessThanOrEqual(one.Neg()) {
+		return decimal.Zero, domainError(fmt.Sprintf("%s的利率必须大于-1", name))
+	}
+	return r, nil
+}
+
+// parsePaymentType 解析付款时间，0 表示期末付款，1 表示期初付款
+func parsePaymentType(value, name string) (decimal.Decimal, error) {
+	t, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if !t.IsZero() && !t.Equal(one) {
+		return decimal.Zero, domainError(fmt.Sprintf("%s的付款时间参数必须为0或1", name))
+	}
+	return t, nil
+}
+
+// tvm 描述货币时间价值公式 pv·(1+r)^n + pmt·(1+r·type)·((1+r)^n - 1)/r + fv = 0 中的已知量
+type tvm struct {
+	rate, nper, pmt, pv, fv, typ decimal.Decimal
+}
+
+// factors 返回 g = (1+r)^n 与年金系数 a = (1+r·type)·(g-1)/r（r = 0 时 a = n），保留 prec 位小数
+func (t tvm) factors(r decimal.Decimal, prec int32) (g, a decimal.Decimal, ok bool) {
+	if r.IsZero() {
+		return one, t.nper, true
+	}
+	// a 中除以 r，r 越小 g 需要的小数位越多
+	gp := prec + leadingZeros(r)
+	if g, ok = powDec(one.Add(r), t.nper, gp); !ok {
+		return decimal.Zero, decimal.Zero, false
+	}
+	a = one.Add(r.Mul(t.typ)).Mul(g.Sub(one)).DivRound(r, prec)
+	return g.Round(prec), a, true
+}
+
+// extraPlaces 返回计算 t 时在目标精度之外需要增加的小数位数，金额越大、(1+r)^n 越大需要的位数越多
+func (t tvm) extraPlaces() (int32, error) {
+	g, _, ok := t.factors(t.rate, guardDigits)
+	if !ok {
+		return 0, ErrOverflow
+	}
+	return guardDigits + maxIntDigits(t.pmt, t.pv, t.fv) + intDigits(g), nil
+}
+
+// parseTVM 解析货币时间价值函数的参数，值为空字符串的参数是待求的未知量
+func parseTVM(name, rate, nper, pmt, pv, fv, typ string) (t tvm, err error) {
+	fields := []struct {
+		value string
+		dst   *decimal.Decimal
+	}{{nper, &t.nper}, {pmt, &t.pmt}, {pv, &t.pv}, {fv, &t.fv}}
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		if *field.dst, err = ParseNumber(field.value); err != nil {
+			return t, err
+		}
+	}
+	if rate != "" {
+		if t.rate, err = parseRate(rate, name); err != nil {
+			return t, err
+		}
+	}
+	t.typ, err = parsePaymentType(typ, name)
+	return t, err
+}
+
+// NPV 计算净现值 Σ cf_i / (1+rate)^i，i 从 1 开始，与电子表格的 NPV 一致
+func (c *Calculator) NPV(rate string, cashFlows ...string) (decimal.Decimal, error) {
+	r, err := ParseNumber(rate)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	cfs, err := parseValues(cashFlows, "npv", 1)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	x := one.Add(r)
+	if x.IsZero() {
+		return decimal.Zero, ErrDivisionByZero
+	}
+	extra := maxIntDigits(cfs...)
+	return c.approx(func(prec int32) decimal.Decimal {
+		wp := prec + guardDigits + extra
+		// 秦九韶算法：npv = (…((cf_n/x + cf_(n-1))/x + …) + cf_1)/x
+		sum := decimal.Zero
+		for i := len(cfs) - 1; i >= 0; i-- {
+			sum = sum.Add(cfs[i]).DivRound(x, wp)
+		}
+		return sum.Round(prec)
+	}), nil
+}
+
+// IRR 计算内部收益率，即使 Σ cf_i / (1+r)^i = 0（i 从 0 开始）的 r
+//
+// 现金流中至少要有一个正数和一个负数，从 r = 0.1 开始用牛顿迭代求解。
+func (c *Calculator) IRR(cashFlows ...string) (decimal.Decimal, error) {
+	cfs, err := parseValues(cashFlows, "irr", 2)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	var pos, neg bool
+	for _, cf := range cfs {
+		pos = pos || cf.IsPositive()
+		neg = neg || cf.IsNegative()
+	}
+	if !pos || !neg {
+		return decimal.Zero, domainError("irr的现金流中至少需要一个正数和一个负数")
+	}
+
+	extra := maxIntDigits(cfs...)
+	var solveErr error
+	res := c.approx(func(prec int32) decimal.Decimal {
+		// 迭代到 prec+guardDigits 位，函数值需要更多位数才能使步长降到该精度以下
+		wp := prec + 2*guardDigits + extra
+		// 令 u = 1/(1+r)，f = Σ cf_i·u^i，df/dr = df/du · (-u²)
+		f := func(r decimal.Decimal) (fx, dfx decimal.Decimal, ok bool) {
+			x := one.Add(r)
+			if !x.IsPositive() {
+				return decimal.Zero, decimal.Zero, false
+			}
+			u := one.DivRound(x, wp)
+			fx, du := decimal.Zero, decimal.Zero
+			for i := len(cfs) - 1; i >= 0; i-- {
+				du = du.Mul(u).Add(fx).Round(wp)
+				fx = fx.Mul(u).Add(cfs[i]).Round(wp)
+			}
+			return fx, du.Mul(u).Mul(u).Neg().Round(wp), true
+		}
+		var r decimal.Decimal
+		r, solveErr = newton("irr", f, defaultRateGuess, prec+guardDigits)
+		return r.Round(prec)
+	})
+	if solveErr != nil {
+		return decimal.Zero, solveErr
+	}
+	return res, nil
+}
+
+// PMT 计算每期付款额，与电子表格的 PMT(rate, nper, pv, fv, type) 一致
+//
+// 现金流出为负数：借入 pv > 0 时每期还款为负数。
+func (c *Calculator) PMT(rate, nper, pv, fv, typ string) (decimal.Decimal, error) {
+	t, err := parseTVM("pmt", rate, nper, "", pv, fv, typ)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if t.nper.IsZero() {
+		return decimal.Zero, domainError("pmt的期数不能为0")
+	}
+	extra, err := t.extraPlaces()
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		g, a, _ := t.factors(t.rate, prec+extra)
+		// pmt = -(fv + pv·g) / a
+		return t.fv.Add(t.pv.Mul(g)).Neg().DivRound(a, prec)
+	}), nil
+}
+
+// FV 计算终值，与电子表格的 FV(rate, nper, pmt, pv, type) 一致
+func (c *Calculator) FV(rate, nper, pmt, pv, typ string) (decimal.Decimal, error) {
+	t, err := parseTVM("fv", rate, nper, pmt, pv, "", typ)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	extra, err := t.extraPlaces()
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		g, a, _ := t.factors(t.rate, prec+extra)
+		// fv = -(pv·g + pmt·a)
+		return t.pv.Mul(g).Add(t.pmt.Mul(a)).Neg().Round(prec)
+	}), nil
+}
+
+// PV 计算现值，与电子表格的 PV(rate, nper, pmt, fv, type) 一致
+func (c *Calculator) PV(rate, nper, pmt, fv, typ string) (decimal.Decimal, error) {
+	t, err := parseTVM("pv", rate, nper, pmt, "", fv, typ)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	extra, err := t.extraPlaces()
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		g, a, _ := t.factors(t.rate, prec+extra)
+		// pv = -(fv + pmt·a) / g
+		return t.fv.Add(t.pmt.Mul(a)).Neg().DivRound(g, prec)
+	}), nil
+}
+
+// Rate 计算每期利率，与电子表格的 RATE(nper, pmt, pv, fv, type, guess) 一致
+//
+// 从 guess 开始用牛顿迭代求解 pv·g + pmt·a + fv = 0，g、a 见 tvm.factors。
+func (c *Calculator) Rate(nper, pmt, pv, fv, typ, guess string) (decimal.Decimal, error) {
+	t, err := parseTVM("rate", "", nper, pmt, pv, fv, typ)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if t.nper.IsZero() {
+		return decimal.Zero, domainError("rate的期数不能为0")
+	}
+	x0, err := parseRate(guess, "rate")
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	extra := maxIntDigits(t.pmt, t.pv, t.fv) + intDigits(t.nper)
+	n := t.nper
+	var solveErr error
+	res := c.approx(func(prec int32) decimal.Decimal {
+		wp := prec + 2*guardDigits + extra
+		f := func(r decimal.Decimal) (fx, dfx decimal.Decimal, ok bool) {
+			if r.LessThanOrEqual(one.Neg()) {
+				return decimal.Zero, decimal.Zero, false
+			}
+			// 导数中除以 r²，r 越小需要的小数位越多
+			wp := wp + leadingZeros(r)
+			g, a, ok := t.factors(r, wp)
+			if !ok {
+				return decimal.Zero, decimal.Zero, false
+			}
+			fx = t.pv.Mul(g).Add(t.pmt.Mul(a)).Add(t.fv)
+			if r.IsZero() {
+				// r → 0 时 dg/dr = n，da/dr = n(n-1)/2 + type·n
+				da := n.Mul(n.Sub(one)).Mul(oneHalf).Add(t.typ.Mul(n))
+				return fx, t.pv.Mul(n).Add(t.pmt.Mul(da)), true
+			}
+			// dg/dr = n·g/(1+r)，da/dr = type·(g-1)/r + (1+r·type)·(r·dg/dr - (g-1))/r²
+			dg := n.Mul(g).DivRound(one.Add(r), wp)
+			gm := g.Sub(one)
+			da := t.typ.Mul(gm).DivRound(r, wp).Add(
+				one.Add(r.Mul(t.typ)).Mul(r.Mul(dg).Sub(gm)).DivRound(r.Mul(r), wp))
+			return fx, t.pv.Mul(dg).Add(t.pmt.Mul(da)), true
+		}
+		var r decimal.Decimal
+		r, solveErr = newton("rate", f, x0, prec+guardDigits)
+		return r.Round(prec)
+	})
+	if solveErr != nil {
+		return decimal.Zero, solveErr
+	}
+	return res, nil
+}
+
+// NPER 计算期数，与电子表格的 NPER(rate, pmt, pv, fv, type) 一致
+//
+// n = ln((pmt·(1+r·type) - fv·r) / (pmt·(1+r·type) + pv·r)) / ln(1+r)，r = 0 时 n = -(pv+fv)/pmt。
+func (c *Calculator) NPER(rate, pmt, pv, fv, typ string) (decimal.Decimal, error) {
+	t, err := parseTVM("nper", rate, "", pmt, pv, fv, typ)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if t.rate.IsZero() {
+		if t.pmt.IsZero() {
+			return decimal.Zero, ErrDivisionByZero
+		}
+		return c.div(t.pv.Add(t.fv).Neg(), t.pmt), nil
+	}
+	k := t.pmt.Mul(one.Add(t.rate.Mul(t.typ)))
+	num := k.Sub(t.fv.Mul(t.rate))
+	den := k.Add(t.pv.Mul(t.rate))
+	if den.IsZero() || num.Sign()*den.Sign() <= 0 {
+		return decimal.Zero, domainError("nper在给定的参数下无解")
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		wp := prec + guardDigits
+		l := lnDec(num.Abs(), wp+guardDigits).Sub(lnDec(den.Abs(), wp+guardDigits))
+		lr := lnDec(one.Add(t.rate), wp+leadingZeros(t.rate)+intDigits(l))
+		return l.DivRound(lr, prec)
+	}), nil
+}
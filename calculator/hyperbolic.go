@@ -0,0 +1,336 @@
+package calculator
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// sinhDec 计算 sinh(x)，保留 prec 位小数；ok 为 false 表示结果溢出
+func sinhDec(x decimal.Decimal, prec int32) (res decimal.Decimal, ok bool) {
+	wp := prec + guardDigits
+	if x.Abs().LessThan(one) {
+		// |x| < 1 时 (e^x - e^-x)/2 存在相消，改用泰勒级数
+		x2 := x.Mul(x).Round(wp)
+		term := x
+		sum := x
+		for k := int64(1); ; k++ {
+			term = term.Mul(x2).DivRound(decimal.NewFromInt((2*k)*(2*k+1)), wp)
+			if term.IsZero() {
+				break
+			}
+			sum = sum.Add(term)
+		}
+		return sum.Round(prec), true
+	}
+
+	ex, ok := expDec(x, wp)
+	if !ok {
+		return decimal.Zero, false
+	}
+	emx, _ := expDec(x.Neg(), wp)
+	return ex.Sub(emx).DivRound(two, prec), true
+}
+
+// coshDec 计算 cosh(x)，保留 prec 位小数；ok 为 false 表示结果溢出
+func coshDec(x decimal.Decimal, prec int32) (res decimal.Decimal, ok bool) {
+	wp := prec + guardDigits
+	ex, ok := expDec(x.Abs(), wp)
+	if !ok {
+		return decimal.Zero, false
+	}
+	emx, _ := expDec(x.Abs().Neg(), wp)
+	return ex.Add(emx).DivRound(two, prec), true
+}
+
+// tanhDec 计算 tanh(x)，保留 prec 位小数
+func tanhDec(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	if x.Abs().LessThan(one) {
+		s, _ := sinhDec(x, wp)
+		c, _ := coshDec(x, wp)
+		return s.DivRound(c, prec)
+	}
+
+	// tanh|x| = 1 - 2/(e^(2|x|) + 1)，e^(2|x|) 溢出时结果与 1 的差远小于任何精度
+	res := one
+	if e2, ok := expDec(x.Abs().Mul(two), wp); ok {
+		res = one.Sub(two.DivRound(e2.Add(one), wp))
+	}
+	if x.IsNegative() {
+		res = res.Neg()
+	}
+	return res.Round(prec)
+}
+
+// asinhDec 计算 asinh(x) = ln(|x| + sqrt(x² + 1))，保留 prec 位小数
+func asinhDec(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	a := x.Abs()
+	res := lnDec(a.Add(sqrtDec(a.Mul(a).Add(one), wp)), wp)
+	if x.IsNegative() {
+		res = res.Neg()
+	}
+	return res.Round(prec)
+}
+
+// acoshDec 计算 acosh(x) = ln(x + sqrt(x² - 1))，保留 prec 位小数，x 必须大于等于 1
+func acoshDec(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	return lnDec(x.Add(sqrtDec(x.Mul(x).Sub(one), wp)), wp).Round(prec)
+}
+
+// atanhDec 计算 atanh(x) = ln((1 + x)/(1 - x))/2，保留 prec 位小数，|x| 必须小于 1
+func atanhDec(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	q := one.Add(x).DivRound(one.Sub(x), wp)
+	return lnDec(q, wp).DivRound(two, prec)
+}
+
+// Sinh 执行双曲正弦运算
+func (c *Calculator) Sinh(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	ok := true
+	res := c.approx(func(prec int32) decimal.Decimal {
+		var r decimal.Decimal
+		r, ok = sinhDec(v, prec)
+		return r
+	})
+	if !ok {
+		return decimal.Zero, ErrOverflow
+	}
+	return res, nil
+}
+
+// Cosh 执行双曲余弦运算
+func (c *Calculator) Cosh(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	ok := true
+	res := c.approx(func(prec int32) decimal.Decimal {
+		var r decimal.Decimal
+		r, ok = coshDec(v, prec)
+		return r
+	})
+	if !ok {
+		return decimal.Zero, ErrOverflow
+	}
+	return res, nil
+}
+
+// Tanh 执行双曲正切运算
+func (c *Calculator) Tanh(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return tanhDec(v, prec)
+	}), nil
+}
+
+// Coth 执行双曲余切运算
+func (c *Calculator) Coth(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if v.IsZero() {
+		return decimal.Zero, domainError("双曲余切函数在 0 处无定义")
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		// |x| 越小 tanh x 越接近 0，需要更多小数位才能保证倒数的精度
+		t := tanhDec(v, prec+guardDigits+2*leadingZeros(v))
+		return one.DivRound(t, prec)
+	}), nil
+}
+
+// Sech 执行双曲正割运算
+func (c *Calculator) Sech(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		ch, ok := coshDec(v, prec+guardDigits)
+		if !ok {
+			// cosh 溢出时 sech 小于任何精度
+			return decimal.Zero
+		}
+		return one.DivRound(ch, prec)
+	}), nil
+}
+
+// Csch 执行双曲余割运算
+func (c *Calculator) Csch(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if v.IsZero() {
+		return decimal.Zero, domainError("双曲余割函数在 0 处无定义")
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		sh, ok := sinhDec(v, prec+guardDigits+2*leadingZeros(v))
+		if !ok {
+			return decimal.Zero
+		}
+		return one.DivRound(sh, prec)
+	}), nil
+}
+
+// Asinh 执行反双曲正弦运算
+func (c *Calculator) Asinh(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return asinhDec(v, prec)
+	}), nil
+}
+
+// Acosh 执行反双曲余弦运算
+func (c *Calculator) Acosh(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if v.LessThan(one) {
+		return decimal.Zero, domainError("反双曲余弦函数的输入必须大于等于1")
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return acoshDec(v, prec)
+	}), nil
+}
+
+// Atanh 执行反双曲正切运算
+func (c *Calculator) Atanh(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if v.Abs().GreaterThanOrEqual(one) {
+		return decimal.Zero, domainError("反双曲正切函数的输入必须在 (-1,1) 范围内")
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return atanhDec(v, prec)
+	}), nil
+}
+
+// mulI 返回 i·z
+func mulI(z Complex) Complex {
+	return Complex{Re: z.Im.Neg(), Im: z.Re}
+}
+
+// mulNegI 返回 -i·z
+func mulNegI(z Complex) Complex {
+	return Complex{Re: z.Im, Im: z.Re.Neg()}
+}
+
+// csinhcosh 计算 sinh(z) = -i·sin(iz) 与 cosh(z) = cos(iz)，保留 prec 位小数
+func csinhcosh(z Complex, prec int32) (sh, ch Complex, ok bool) {
+	s, ok := csin(mulI(z), prec)
+	if !ok {
+		return Complex{}, Complex{}, false
+	}
+	ch, _ = ccos(mulI(z), prec)
+	return mulNegI(s), ch, true
+}
+
+// chyperbolic 计算 num(z)/den(z)，num、den 取自 sinh、cosh 或 1，分母为零时返回定义域错误
+func (c *Calculator) chyperbolic(z Complex, ratio func(sh, ch Complex) (num, den Complex), msg string) (Complex, error) {
+	wp := c.workPrecision() + guardDigits
+	sh, ch, ok := csinhcosh(z, wp)
+	if !ok {
+		return Complex{}, ErrOverflow
+	}
+	if _, den := ratio(sh, ch); cround(den, c.workPrecision()).IsZero() {
+		return Complex{}, domainError(msg)
+	}
+	return c.approxComplex(func(prec int32) Complex {
+		sh, ch, _ := csinhcosh(z, prec+guardDigits)
+		num, den := ratio(sh, ch)
+		return cdiv(num, den, prec)
+	}), nil
+}
+
+// CSinh 执行复数的双曲正弦运算
+func (c *Calculator) CSinh(z Complex) (Complex, error) {
+	if _, _, ok := csinhcosh(z, c.workPrecision()); !ok {
+		return Complex{}, ErrOverflow
+	}
+	return c.approxComplex(func(prec int32) Complex {
+		sh, _, _ := csinhcosh(z, prec)
+		return sh
+	}), nil
+}
+
+// CCosh 执行复数的双曲余弦运算
+func (c *Calculator) CCosh(z Complex) (Complex, error) {
+	if _, _, ok := csinhcosh(z, c.workPrecision()); !ok {
+		return Complex{}, ErrOverflow
+	}
+	return c.approxComplex(func(prec int32) Complex {
+		_, ch, _ := csinhcosh(z, prec)
+		return ch
+	}), nil
+}
+
+// CTanh 执行复数的双曲正切运算
+func (c *Calculator) CTanh(z Complex) (Complex, error) {
+	return c.chyperbolic(z, func(sh, ch Complex) (Complex, Complex) {
+		return sh, ch
+	}, "双曲正切函数在 (π/2 + kπ)i 处无定义")
+}
+
+// CCoth 执行复数的双曲余切运算
+func (c *Calculator) CCoth(z Complex) (Complex, error) {
+	return c.chyperbolic(z, func(sh, ch Complex) (Complex, Complex) {
+		return ch, sh
+	}, "双曲余切函数在 kπi 处无定义")
+}
+
+// CSech 执行复数的双曲正割运算
+func (c *Calculator) CSech(z Complex) (Complex, error) {
+	return c.chyperbolic(z, func(_, ch Complex) (Complex, Complex) {
+		return Complex{Re: one, Im: decimal.Zero}, ch
+	}, "双曲正割函数在 (π/2 + kπ)i 处无定义")
+}
+
+// CCsch 执行复数的双曲余割运算
+func (c *Calculator) CCsch(z Complex) (Complex, error) {
+	return c.chyperbolic(z, func(sh, _ Complex) (Complex, Complex) {
+		return Complex{Re: one, Im: decimal.Zero}, sh
+	}, "双曲余割函数在 kπi 处无定义")
+}
+
+// CAsinh 执行复数的反双曲正弦运算，asinh(z) = -i·arcsin(iz)
+func (c *Calculator) CAsinh(z Complex) (Complex, error) {
+	return c.approxComplex(func(prec int32) Complex {
+		return mulNegI(casin(mulI(z), prec))
+	}), nil
+}
+
+// CAcosh 执行复数的反双曲余弦运算，acosh(z) = ln(z + sqrt(z + 1)·sqrt(z - 1))
+func (c *Calculator) CAcosh(z Complex) (Complex, error) {
+	unit := Complex{Re: one, Im: decimal.Zero}
+	return c.approxComplex(func(prec int32) Complex {
+		wp := prec + guardDigits
+		root := cmul(csqrt(cadd(z, unit), wp), csqrt(csub(z, unit), wp))
+		return cln(cadd(z, root), prec)
+	}), nil
+}
+
+// CAtanh 执行复数的反双曲正切运算，atanh(z) = -i·arctan(iz)
+func (c *Calculator) CAtanh(z Complex) (Complex, error) {
+	if z.Im.IsZero() && z.Re.Abs().Equal(one) {
+		return Complex{}, domainError("反双曲正切函数在 ±1 处无定义")
+	}
+	return c.approxComplex(func(prec int32) Complex {
+		return mulNegI(catan(mulI(z), prec))
+	}), nil
+}
@@ -0,0 +1,198 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// parseInteger 将数字字符串解析为整数，name 用于错误信息
+func parseInteger(value, name string) (*big.Int, error) {
+	d, err := ParseNumber(value)
+	if err != nil {
+		return nil, err
+	}
+	if !d.IsInteger() {
+		return nil, domainError(fmt.Sprintf("%s的参数必须为整数", name))
+	}
+	return d.BigInt(), nil
+}
+
+// parseNatural 将数字字符串解析为非负整数，name 用于错误信息
+func parseNatural(value, name string) (*big.Int, error) {
+	n, err := parseInteger(value, name)
+	if err != nil {
+		return nil, err
+	}
+	if n.Sign() < 0 {
+		return nil, domainError(fmt.Sprintf("%s的参数不能为负数", name))
+	}
+	return n, nil
+}
+
+// checkDigits 按 ln(结果) 估算十进制位数，超过 maxResultDigits 或无法估算时返回 ErrOverflow
+func checkDigits(lnResult float64) error {
+	if math.IsNaN(lnResult) || lnResult/math.Ln10 > maxResultDigits {
+		return ErrOverflow
+	}
+	return nil
+}
+
+// lnFalling 返回下降阶乘 n·(n-1)·…·(n-r+1) 的自然对数的近似值，r 不大于 n
+func lnFalling(n, r *big.Int) float64 {
+	if n.BitLen() > 50 {
+		// n 过大时 lgamma 相减会损失精度，改用上界 r·ln n
+		if r.BitLen() > 50 {
+			return math.Inf(1)
+		}
+		nf, _ := new(big.Float).SetInt(n).Float64()
+		return float64(r.Int64()) * math.Log(nf)
+	}
+	a, _ := math.Lgamma(float64(n.Int64()) + 1)
+	b, _ := math.Lgamma(float64(n.Int64()-r.Int64()) + 1)
+	return a - b
+}
+
+// fallingFactorial 计算 n·(n-1)·…·(n-r+1)，r 不大于 n
+func fallingFactorial(n, r *big.Int) *big.Int {
+	res := big.NewInt(1)
+	step := big.NewInt(1)
+	k := new(big.Int).Sub(n, r)
+	for k.Cmp(n) < 0 {
+		k.Add(k, step)
+		res.Mul(res, k)
+	}
+	return res
+}
+
+// Factorial 计算 n 的阶乘 n!，结果为精确整数
+func (c *Calculator) Factorial(n string) (*big.Int, error) {
+	v, err := parseNatural(n, "fact")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDigits(lnFalling(v, v)); err != nil {
+		return nil, err
+	}
+	return new(big.Int).MulRange(1, v.Int64()), nil
+}
+
+// DoubleFactorial 计算 n 的双阶乘 n!! = n·(n-2)·(n-4)·…，结果为精确整数，0!! = 1
+func (c *Calculator) DoubleFactorial(n string) (*big.Int, error) {
+	v, err := parseNatural(n, "dfact")
+	if err != nil {
+		return nil, err
+	}
+	// 偶数 n = 2k 时 n!! = 2^k·k!，奇数 n = 2k+1 时 n!! = (2k+1)!/(2^k·k!)
+	k := new(big.Int).Rsh(v, 1)
+	lnResult := float64(k.Int64())*math.Ln2 + lnFalling(k, k)
+	if v.Bit(0) == 1 {
+		lnResult = lnFalling(v, v) - lnResult
+	}
+	if err := checkDigits(lnResult); err != nil {
+		return nil, err
+	}
+	res := big.NewInt(1)
+	for i := v.Int64(); i > 1; i -= 2 {
+		res.Mul(res, big.NewInt(i))
+	}
+	return res, nil
+}
+
+// Permutations 计算排列数 nPr = n!/(n-r)!，r > n 时为 0
+func (c *Calculator) Permutations(n, r string) (*big.Int, error) {
+	nv, err := parseNatural(n, "nPr")
+	if err != nil {
+		return nil, err
+	}
+	rv, err := parseNatural(r, "nPr")
+	if err != nil {
+		return nil, err
+	}
+	if rv.Cmp(nv) > 0 {
+		return new(big.Int), nil
+	}
+	if err := checkDigits(lnFalling(nv, rv)); err != nil {
+		return nil, err
+	}
+	return fallingFactorial(nv, rv), nil
+}
+
+// Binomial 计算组合数 nCr = n!/(r!(n-r)!)，r > n 时为 0
+func (c *Calculator) Binomial(n, r string) (*big.Int, error) {
+	nv, err := parseNatural(n, "nCr")
+	if err != nil {
+		return nil, err
+	}
+	rv, err := parseNatural(r, "nCr")
+	if err != nil {
+		return nil, err
+	}
+	if rv.Cmp(nv) > 0 {
+		return new(big.Int), nil
+	}
+	// nCr = nC(n-r)，取较小的一个计算下降阶乘
+	if k := new(big.Int).Sub(nv, rv); k.Cmp(rv) < 0 {
+		rv = k
+	}
+	if err := checkDigits(lnFalling(nv, rv) - lnFalling(rv, rv)); err != nil {
+		return nil, err
+	}
+	res := fallingFactorial(nv, rv)
+	return res.Quo(res, fallingFactorial(rv, rv)), nil
+}
+
+// GCD 计算若干整数的最大公约数，结果非负
+func (c *Calculator) GCD(values ...string) (*big.Int, error) {
+	res := new(big.Int)
+	for _, value := range values {
+		v, err := parseInteger(value, "gcd")
+		if err != nil {
+			return nil, err
+		}
+		res.GCD(nil, nil, res, v.Abs(v))
+	}
+	return res, nil
+}
+
+// LCM 计算若干整数的最小公倍数，结果非负，任一参数为 0 时结果为 0
+func (c *Calculator) LCM(values ...string) (*big.Int, error) {
+	res := big.NewInt(1)
+	for _, value := range values {
+		v, err := parseInteger(value, "lcm")
+		if err != nil {
+			return nil, err
+		}
+		if v.Sign() == 0 {
+			return new(big.Int), nil
+		}
+		v.Abs(v)
+		g := new(big.Int).GCD(nil, nil, res, v)
+		res.Mul(res, v.Quo(v, g))
+		if res.BitLen() > maxResultDigits*332/100 {
+			return nil, ErrOverflow
+		}
+	}
+	return res, nil
+}
+
+// Mod 计算 a 除以 b 的余数，结果与 b 同号，例如 mod(-7, 3) = 2
+func (c *Calculator) Mod(a, b string) (*big.Int, error) {
+	av, err := parseInteger(a, "mod")
+	if err != nil {
+		return nil, err
+	}
+	bv, err := parseInteger(b, "mod")
+	if err != nil {
+		return nil, err
+	}
+	if bv.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	// big.Int.Mod 的结果总是非负，除数为负时调整为与除数同号
+	res := new(big.Int).Mod(av, bv)
+	if bv.Sign() < 0 && res.Sign() > 0 {
+		res.Add(res, bv)
+	}
+	return res, nil
+}
@@ -0,0 +1,361 @@
+package calculator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// UncertaintyMode 定义带不确定度的测量值如何参与运算
+type UncertaintyMode int
+
+const (
+	IntervalArithmetic  UncertaintyMode = iota // 区间运算，结果保证包含所有可能的取值（默认）
+	GaussianPropagation                        // 一阶高斯误差传播，各测量值的误差相互独立
+)
+
+// uncertaintyModeNames 不确定度模式与其名称的对应关系
+var uncertaintyModeNames = map[UncertaintyMode]string{
+	IntervalArithmetic:  "interval",
+	GaussianPropagation: "gaussian",
+}
+
+func (m UncertaintyMode) String() string {
+	if name, ok := uncertaintyModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("UncertaintyMode(%d)", int(m))
+}
+
+// ParseUncertaintyMode 根据名称解析不确定度模式，名称不区分大小写
+func ParseUncertaintyMode(name string) (UncertaintyMode, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for mode, n := range uncertaintyModeNames {
+		if n == name {
+			return mode, nil
+		}
+	}
+	return IntervalArithmetic, fmt.Errorf("未知的不确定度模式: %s", name)
+}
+
+// WithUncertainty 设置测量值的运算方式，默认为 IntervalArithmetic
+func WithUncertainty(mode UncertaintyMode) Option {
+	return func(c *Calculator) {
+		c.uncertainty = mode
+	}
+}
+
+// Uncertainty 返回测量值的运算方式
+func (c *Calculator) Uncertainty() UncertaintyMode {
+	return c.uncertainty
+}
+
+// Interval 表示闭区间 [Lo, Hi]
+type Interval struct {
+	Lo, Hi decimal.Decimal
+}
+
+// NewInterval 创建闭区间 [lo, hi]
+func NewInterval(lo, hi decimal.Decimal) (Interval, error) {
+	if lo.GreaterThan(hi) {
+		return Interval{}, domainError("区间的下界不能大于上界")
+	}
+	return Interval{Lo: lo, Hi: hi}, nil
+}
+
+// PointInterval 返回只包含 d 的区间
+func PointInterval(d decimal.Decimal) Interval {
+	return Interval{Lo: d, Hi: d}
+}
+
+// PlusMinus 返回 v ± u 对应的区间 [v-u, v+u]
+func PlusMinus(v, u decimal.Decimal) (Interval, error) {
+	if u.IsNegative() {
+		return Interval{}, domainError("不确定度不能为负数")
+	}
+	return Interval{Lo: v.Sub(u), Hi: v.Add(u)}, nil
+}
+
+// containsZero 判断区间是否包含零
+func (x Interval) containsZero() bool {
+	return x.Lo.Sign() <= 0 && x.Hi.Sign() >= 0
+}
+
+// widened 返回多保留 guardDigits 位的计算器副本，用于计算需要向外舍入的近似值
+func (c *Calculator) widened() *Calculator {
+	w := *c
+	w.precision += guardDigits
+	w.rounding = RoundHalfEven
+	return &w
+}
+
+// slack 返回 widened 计算的近似值 d 可能存在的最大误差
+func (c *Calculator) slack(d decimal.Decimal) decimal.Decimal {
+	return decimal.New(1, -(c.places(d) + guardDigits - 2))
+}
+
+// lower 将下界向负无穷方向舍入到当前精度，inexact 表示 d 为 widened 计算的近似值
+func (c *Calculator) lower(d decimal.Decimal, inexact bool) decimal.Decimal {
+	if inexact {
+		d = d.Sub(c.slack(d))
+	}
+	return roundDec(d, c.places(d), RoundFloor)
+}
+
+// upper 将上界向正无穷方向舍入到当前精度，inexact 的含义与 lower 相同
+func (c *Calculator) upper(d decimal.Decimal, inexact bool) decimal.Decimal {
+	if inexact {
+		d = d.Add(c.slack(d))
+	}
+	return roundDec(d, c.places(d), RoundCeiling)
+}
+
+// hull 返回包含全部 values 的最小区间并向外舍入
+func (c *Calculator) hull(inexact bool, values ...decimal.Decimal) Interval {
+	lo, hi := decimal.Min(values[0], values[1:]...), decimal.Max(values[0], values[1:]...)
+	return Interval{Lo: c.lower(lo, inexact), Hi: c.upper(hi, inexact)}
+}
+
+// enclose 返回包含全部近似值 values 的区间并向外舍入，exact[i] 为 true 表示 values[i] 没有误差
+func (c *Calculator) enclose(values []decimal.Decimal, exact []bool) Interval {
+	var res Interval
+	for i, v := range values {
+		lo, hi := c.lower(v, !exact[i]), c.upper(v, !exact[i])
+		if i == 0 || lo.LessThan(res.Lo) {
+			res.Lo = lo
+		}
+		if i == 0 || hi.GreaterThan(res.Hi) {
+			res.Hi = hi
+		}
+	}
+	return res
+}
+
+// IAdd 计算区间加法
+func (c *Calculator) IAdd(x, y Interval) (Interval, error) {
+	return c.hull(false, x.Lo.Add(y.Lo), x.Hi.Add(y.Hi)), nil
+}
+
+// ISubtract 计算区间减法
+func (c *Calculator) ISubtract(x, y Interval) (Interval, error) {
+	return c.hull(false, x.Lo.Sub(y.Hi), x.Hi.Sub(y.Lo)), nil
+}
+
+// IMultiply 计算区间乘法
+func (c *Calculator) IMultiply(x, y Interval) (Interval, error) {
+	return c.hull(false, x.Lo.Mul(y.Lo), x.Lo.Mul(y.Hi), x.Hi.Mul(y.Lo), x.Hi.Mul(y.Hi)), nil
+}
+
+// IDivide 计算区间除法，除数区间不能包含零
+func (c *Calculator) IDivide(x, y Interval) (Interval, error) {
+	if y.containsZero() {
+		return Interval{}, fmt.Errorf("%w: 除数区间包含零", ErrDivisionByZero)
+	}
+	w := c.widened()
+	return c.hull(true, w.div(x.Lo, y.Lo), w.div(x.Lo, y.Hi), w.div(x.Hi, y.Lo), w.div(x.Hi, y.Hi)), nil
+}
+
+// ISqrt 计算区间的平方根，区间不能包含负数
+func (c *Calculator) ISqrt(x Interval) (Interval, error) {
+	if x.Lo.IsNegative() {
+		return Interval{}, domainError("区间包含负数，不能开方")
+	}
+	w := c.widened()
+	root := func(d decimal.Decimal) (decimal.Decimal, bool) {
+		if d.IsZero() {
+			return d, false
+		}
+		s := w.sqrt(d)
+		return s, !s.Mul(s).Equal(d)
+	}
+	lo, loInexact := root(x.Lo)
+	hi, hiInexact := root(x.Hi)
+	return Interval{Lo: c.lower(lo, loInexact), Hi: c.upper(hi, hiInexact)}, nil
+}
+
+// maxIntervalPower 精确计算区间整数次幂的最大指数
+const maxIntervalPower = 1000
+
+// IPower 计算区间的乘方
+//
+// 指数为整数时按奇偶性精确计算，如 [-2, 3]^2 = [0, 9]；
+// 其他指数要求底数区间为正，结果在区间的四个顶点之一取到最大值和最小值。
+func (c *Calculator) IPower(x, y Interval) (Interval, error) {
+	if y.Lo.Equal(y.Hi) && y.Lo.IsInteger() && y.Lo.Abs().LessThanOrEqual(decimal.NewFromInt(maxIntervalPower)) {
+		n := y.Lo.IntPart()
+		if n < 0 {
+			p, err := c.IPower(x, PointInterval(decimal.NewFromInt(-n)))
+			if err != nil {
+				return Interval{}, err
+			}
+			return c.IDivide(PointInterval(one), p)
+		}
+		e := decimal.NewFromInt(n)
+		a, aok := powExact(x.Lo, e)
+		b, bok := powExact(x.Hi, e)
+		if !aok || !bok {
+			return Interval{}, ErrOverflow
+		}
+		switch {
+		case n%2 == 1 || x.Lo.Sign() >= 0:
+			return c.hull(false, a, b), nil
+		case x.Hi.Sign() <= 0:
+			return c.hull(false, b, a), nil
+		}
+		return c.hull(false, decimal.Zero, a, b), nil
+	}
+
+	if x.Lo.IsNegative() {
+		return Interval{}, domainError("非整数次幂要求底数区间不包含负数")
+	}
+	if x.Lo.IsZero() && !y.Lo.IsPositive() {
+		return Interval{}, fmt.Errorf("%w: 底数区间包含零时指数必须为正数", ErrDivisionByZero)
+	}
+	w := c.widened()
+	var values []decimal.Decimal
+	var exact []bool
+	for _, b := range []decimal.Decimal{x.Lo, x.Hi} {
+		for _, e := range []decimal.Decimal{y.Lo, y.Hi} {
+			v, err := w.Power(b.String(), e.String())
+			if err != nil {
+				return Interval{}, err
+			}
+			// 0^y、1^y 与 b^0 的结果没有误差
+			values = append(values, v)
+			exact = append(exact, b.IsZero() || b.Equal(one) || e.IsZero())
+		}
+	}
+	return c.enclose(values, exact), nil
+}
+
+// turnDec 返回当前单位下一整圈的大小，弧度制下保留 prec 位小数
+func (c *Calculator) turnDec(prec int32) decimal.Decimal {
+	if c.angleUnit == Radians {
+		return piDec(prec).Mul(two)
+	}
+	return c.angleUnit.turn()
+}
+
+// reaches 判断区间是否包含 offset + k·period 形式的点，k 为整数
+//
+// 弧度制下 π 无法精确表示，判断时将区间略微放宽，宁可多包含一个极值点也不会漏掉。
+func (c *Calculator) reaches(x Interval, offset, period decimal.Decimal) bool {
+	lo, hi := x.Lo, x.Hi
+	if c.angleUnit == Radians {
+		eps := decimal.New(1, -c.workPrecision())
+		lo, hi = lo.Sub(eps), hi.Add(eps)
+	}
+	k := lo.Sub(offset).DivRound(period, guardDigits).Floor()
+	for i := 0; i < 2; i++ {
+		p := offset.Add(k.Mul(period))
+		if p.GreaterThanOrEqual(lo) && p.LessThanOrEqual(hi) {
+			return true
+		}
+		k = k.Add(one)
+	}
+	return false
+}
+
+// endpoints 以更高的精度计算 f 在区间两个端点处的值
+func (c *Calculator) endpoints(x Interval, f func(*Calculator, string) (decimal.Decimal, error)) (a, b decimal.Decimal, err error) {
+	w := c.widened()
+	if a, err = f(w, x.Lo.String()); err != nil {
+		return
+	}
+	b, err = f(w, x.Hi.String())
+	return
+}
+
+// itrig 计算正弦或余弦在区间上的取值范围，端点之间经过极值点时取 ±1
+//
+// maxAt、minAt 为一圈内取到最大值和最小值的位置占整圈的比例；sin、cos 与 tan 在 0 处的值没有误差。
+func (c *Calculator) itrig(x Interval, f func(*Calculator, string) (decimal.Decimal, error), maxAt, minAt decimal.Decimal) (Interval, error) {
+	wp := c.workPrecision() + intDigits(x.Hi.Abs()) + intDigits(x.Lo.Abs())
+	t := c.turnDec(wp)
+	if x.Hi.Sub(x.Lo).GreaterThanOrEqual(t) {
+		return Interval{Lo: one.Neg(), Hi: one}, nil
+	}
+	a, b, err := c.endpoints(x, f)
+	if err != nil {
+		return Interval{}, err
+	}
+	res := c.enclose([]decimal.Decimal{a, b}, []bool{x.Lo.IsZero(), x.Hi.IsZero()})
+	if c.reaches(x, maxAt.Mul(t), t) {
+		res.Hi = one
+	}
+	if c.reaches(x, minAt.Mul(t), t) {
+		res.Lo = one.Neg()
+	}
+	return res, nil
+}
+
+// ISin 计算区间的正弦，角度单位与 Sin 相同
+func (c *Calculator) ISin(x Interval) (Interval, error) {
+	return c.itrig(x, (*Calculator).Sin, decimal.New(25, -2), decimal.New(75, -2))
+}
+
+// ICos 计算区间的余弦，角度单位与 Cos 相同
+func (c *Calculator) ICos(x Interval) (Interval, error) {
+	return c.itrig(x, (*Calculator).Cos, decimal.Zero, oneHalf)
+}
+
+// ITan 计算区间的正切，区间不能包含正切函数的无定义点
+func (c *Calculator) ITan(x Interval) (Interval, error) {
+	wp := c.workPrecision() + intDigits(x.Hi.Abs()) + intDigits(x.Lo.Abs())
+	half := c.turnDec(wp).Mul(oneHalf)
+	if x.Hi.Sub(x.Lo).GreaterThanOrEqual(half) || c.reaches(x, half.Mul(oneHalf), half) {
+		return Interval{}, domainError("区间包含正切函数的无定义点")
+	}
+	a, b, err := c.endpoints(x, (*Calculator).Tan)
+	if err != nil {
+		return Interval{}, err
+	}
+	return c.enclose([]decimal.Decimal{a, b}, []bool{x.Lo.IsZero(), x.Hi.IsZero()}), nil
+}
+
+// imonotone 计算单调函数 f 在区间上的取值范围，f 在 exactAt 处的值没有误差
+//
+// 单调函数在区间端点处取到最大值和最小值，端点超出 f 的定义域时返回 f 的错误。
+func (c *Calculator) imonotone(x Interval, f func(*Calculator, string) (decimal.Decimal, error), exactAt decimal.Decimal) (Interval, error) {
+	a, b, err := c.endpoints(x, f)
+	if err != nil {
+		return Interval{}, err
+	}
+	return c.enclose([]decimal.Decimal{a, b}, []bool{x.Lo.Equal(exactAt), x.Hi.Equal(exactAt)}), nil
+}
+
+// IAsin 计算区间的反正弦，区间必须在 [-1, 1] 内，角度单位与 Asin 相同
+func (c *Calculator) IAsin(x Interval) (Interval, error) {
+	return c.imonotone(x, (*Calculator).Asin, decimal.Zero)
+}
+
+// IAcos 计算区间的反余弦，区间必须在 [-1, 1] 内，角度单位与 Acos 相同
+func (c *Calculator) IAcos(x Interval) (Interval, error) {
+	return c.imonotone(x, (*Calculator).Acos, one)
+}
+
+// IAtan 计算区间的反正切，角度单位与 Atan 相同
+func (c *Calculator) IAtan(x Interval) (Interval, error) {
+	return c.imonotone(x, (*Calculator).Atan, decimal.Zero)
+}
+
+// ILn 计算区间的自然对数，区间必须为正
+func (c *Calculator) ILn(x Interval) (Interval, error) {
+	return c.imonotone(x, (*Calculator).Ln, one)
+}
+
+// IExp 计算区间的指数函数 e^x
+func (c *Calculator) IExp(x Interval) (Interval, error) {
+	return c.imonotone(x, (*Calculator).Exp, decimal.Zero)
+}
+
+// FormatInterval 将区间格式化为中点 ± 半径，如 3.00 ± 0.05
+//
+// 中点按当前精度舍入，半径向上舍入，保证 中点 ± 半径 包含整个区间。
+func (c *Calculator) FormatInterval(x Interval) string {
+	mid := c.round(x.Lo.Add(x.Hi).Mul(oneHalf))
+	places := c.places(mid)
+	radius := decimal.Max(x.Hi.Sub(mid), mid.Sub(x.Lo))
+	return mid.StringFixed(places) + " ± " + roundDec(radius, places, RoundCeiling).StringFixed(places)
+}
@@ -0,0 +1,466 @@
+package calculator
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxMatrixDim 矩阵允许的最大行数和列数
+const maxMatrixDim = 100
+
+// Matrix 表示按行存储的实数矩阵，向量是只有一行或一列的矩阵
+type Matrix [][]decimal.Decimal
+
+// NewMatrix 由各行元素创建矩阵，各行的元素个数必须相同
+func NewMatrix(rows [][]decimal.Decimal) (Matrix, error) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil, fmt.Errorf("%w: 矩阵不能为空", ErrDimensionMismatch)
+	}
+	if len(rows) > maxMatrixDim || len(rows[0]) > maxMatrixDim {
+		return nil, fmt.Errorf("%w: 矩阵的行数和列数不能超过 %d", ErrDimensionMismatch, maxMatrixDim)
+	}
+	for _, row := range rows[1:] {
+		if len(row) != len(rows[0]) {
+			return nil, fmt.Errorf("%w: 矩阵各行的元素个数必须相同", ErrDimensionMismatch)
+		}
+	}
+	return Matrix(rows), nil
+}
+
+// Rows 返回矩阵的行数
+func (m Matrix) Rows() int {
+	return len(m)
+}
+
+// Cols 返回矩阵的列数
+func (m Matrix) Cols() int {
+	return len(m[0])
+}
+
+// IsVector 判断矩阵是否为行向量或列向量
+func (m Matrix) IsVector() bool {
+	return m.Rows() == 1 || m.Cols() == 1
+}
+
+// elements 按行优先的顺序返回矩阵的全部元素
+func (m Matrix) elements() []decimal.Decimal {
+	res := make([]decimal.Decimal, 0, m.Rows()*m.Cols())
+	for _, row := range m {
+		res = append(res, row...)
+	}
+	return res
+}
+
+// vector 要求 m 为向量并返回其元素，name 用于错误信息
+func (m Matrix) vector(name string) ([]decimal.Decimal, error) {
+	if !m.IsVector() {
+		return nil, fmt.Errorf("%w: %s的参数必须为向量", ErrDimensionMismatch, name)
+	}
+	return m.elements(), nil
+}
+
+// square 要求 m 为方阵，name 用于错误信息
+func (m Matrix) square(name string) error {
+	if m.Rows() != m.Cols() {
+		return fmt.Errorf("%w: %s只对方阵有定义，得到 %d×%d 矩阵", ErrDimensionMismatch, name, m.Rows(), m.Cols())
+	}
+	return nil
+}
+
+// mapMatrix 创建 rows×cols 的矩阵，元素为 f(i, j)
+func mapMatrix(rows, cols int, f func(i, j int) decimal.Decimal) Matrix {
+	res := make(Matrix, rows)
+	for i := range res {
+		res[i] = make([]decimal.Decimal, cols)
+		for j := range res[i] {
+			res[i][j] = f(i, j)
+		}
+	}
+	return res
+}
+
+// sameShape 要求两个矩阵的形状相同，op 用于错误信息
+func sameShape(a, b Matrix, op string) error {
+	if a.Rows() != b.Rows() || a.Cols() != b.Cols() {
+		return fmt.Errorf("%w: %d×%d 与 %d×%d 矩阵不能%s", ErrDimensionMismatch, a.Rows(), a.Cols(), b.Rows(), b.Cols(), op)
+	}
+	return nil
+}
+
+// MAdd 计算两个同形矩阵的逐元素和
+func (c *Calculator) MAdd(a, b Matrix) (Matrix, error) {
+	if err := sameShape(a, b, "相加"); err != nil {
+		return nil, err
+	}
+	return mapMatrix(a.Rows(), a.Cols(), func(i, j int) decimal.Decimal {
+		return c.round(a[i][j].Add(b[i][j]))
+	}), nil
+}
+
+// MSubtract 计算两个同形矩阵的逐元素差
+func (c *Calculator) MSubtract(a, b Matrix) (Matrix, error) {
+	if err := sameShape(a, b, "相减"); err != nil {
+		return nil, err
+	}
+	return mapMatrix(a.Rows(), a.Cols(), func(i, j int) decimal.Decimal {
+		return c.round(a[i][j].Sub(b[i][j]))
+	}), nil
+}
+
+// MHadamard 计算两个同形矩阵的逐元素积
+func (c *Calculator) MHadamard(a, b Matrix) (Matrix, error) {
+	if err := sameShape(a, b, "逐元素相乘"); err != nil {
+		return nil, err
+	}
+	return mapMatrix(a.Rows(), a.Cols(), func(i, j int) decimal.Decimal {
+		return c.round(a[i][j].Mul(b[i][j]))
+	}), nil
+}
+
+// MScale 计算矩阵与标量之积
+func (c *Calculator) MScale(m Matrix, k decimal.Decimal) (Matrix, error) {
+	return mapMatrix(m.Rows(), m.Cols(), func(i, j int) decimal.Decimal {
+		return c.round(m[i][j].Mul(k))
+	}), nil
+}
+
+// MDivide 计算矩阵除以标量的商
+func (c *Calculator) MDivide(m Matrix, k decimal.Decimal) (Matrix, error) {
+	if k.IsZero() {
+		return nil, ErrDivisionByZero
+	}
+	return mapMatrix(m.Rows(), m.Cols(), func(i, j int) decimal.Decimal {
+		return c.div(m[i][j], k)
+	}), nil
+}
+
+// MMultiply 计算矩阵乘积 a·b，a 的列数必须等于 b 的行数
+func (c *Calculator) MMultiply(a, b Matrix) (Matrix, error) {
+	if a.Cols() != b.Rows() {
+		return nil, fmt.Errorf("%w: %d×%d 与 %d×%d 矩阵不能相乘", ErrDimensionMismatch, a.Rows(), a.Cols(), b.Rows(), b.Cols())
+	}
+	return mapMatrix(a.Rows(), b.Cols(), func(i, j int) decimal.Decimal {
+		sum := decimal.Zero
+		for k := range b {
+			sum = sum.Add(a[i][k].Mul(b[k][j]))
+		}
+		return c.round(sum)
+	}), nil
+}
+
+// MPower 计算方阵的整数次幂，负指数为逆矩阵的幂，零次幂为单位矩阵
+//
+// 计算过程使用有理数精确进行，只对结果舍入一次。
+func (c *Calculator) MPower(m Matrix, exponent string) (Matrix, error) {
+	e, err := parseInteger(exponent, "矩阵的指数")
+	if err != nil {
+		return nil, err
+	}
+	if err := m.square("矩阵的乘方"); err != nil {
+		return nil, err
+	}
+	n := m.Rows()
+	base := toRats(m)
+	if e.Sign() < 0 {
+		if base, err = invertRats(base); err != nil {
+			return nil, err
+		}
+		e.Neg(e)
+	}
+	res := identityRats(n)
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		res = mulRats(res, res)
+		if e.Bit(i) == 1 {
+			res = mulRats(res, base)
+		}
+		if ratsBits(res) > maxShiftBits {
+			return nil, ErrOverflow
+		}
+	}
+	return c.fromRats(res), nil
+}
+
+// MTranspose 返回矩阵的转置
+func (c *Calculator) MTranspose(m Matrix) (Matrix, error) {
+	return mapMatrix(m.Cols(), m.Rows(), func(i, j int) decimal.Decimal {
+		return c.round(m[j][i])
+	}), nil
+}
+
+// Trace 计算方阵的迹，即主对角线元素之和
+func (c *Calculator) Trace(m Matrix) (decimal.Decimal, error) {
+	if err := m.square("迹"); err != nil {
+		return decimal.Zero, err
+	}
+	sum := decimal.Zero
+	for i := range m {
+		sum = sum.Add(m[i][i])
+	}
+	return c.round(sum), nil
+}
+
+// Det 计算方阵的行列式
+//
+// 以有理数精确消元，只对结果舍入一次。
+func (c *Calculator) Det(m Matrix) (decimal.Decimal, error) {
+	if err := m.square("行列式"); err != nil {
+		return decimal.Zero, err
+	}
+	a := toRats(m)
+	pivots, det := reduce(a, m.Cols())
+	if len(pivots) < m.Rows() {
+		return decimal.Zero, nil
+	}
+	return c.RatDecimal(det), nil
+}
+
+// Inv 计算方阵的逆矩阵，奇异矩阵返回 ErrSingular
+func (c *Calculator) Inv(m Matrix) (Matrix, error) {
+	if err := m.square("逆矩阵"); err != nil {
+		return nil, err
+	}
+	inv, err := invertRats(toRats(m))
+	if err != nil {
+		return nil, err
+	}
+	return c.fromRats(inv), nil
+}
+
+// Rank 计算矩阵的秩
+func (c *Calculator) Rank(m Matrix) (int, error) {
+	pivots, _ := reduce(toRats(m), m.Cols())
+	return len(pivots), nil
+}
+
+// Dot 计算两个等长向量的点积，行向量和列向量均可
+func (c *Calculator) Dot(a, b Matrix) (decimal.Decimal, error) {
+	x, err := a.vector("点积")
+	if err != nil {
+		return decimal.Zero, err
+	}
+	y, err := b.vector("点积")
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if len(x) != len(y) {
+		return decimal.Zero, fmt.Errorf("%w: 长度为 %d 与 %d 的向量不能求点积", ErrDimensionMismatch, len(x), len(y))
+	}
+	sum := decimal.Zero
+	for i := range x {
+		sum = sum.Add(x[i].Mul(y[i]))
+	}
+	return c.round(sum), nil
+}
+
+// Cross 计算两个三维向量的叉积，结果与 a 同为行向量或列向量
+//
+// 二维向量视为 z 分量为 0 的三维向量，如 [1, 2] × [3, 4] = [0, 0, -2]。
+func (c *Calculator) Cross(a, b Matrix) (Matrix, error) {
+	x, err := a.vector("叉积")
+	if err != nil {
+		return nil, err
+	}
+	y, err := b.vector("叉积")
+	if err != nil {
+		return nil, err
+	}
+	if len(x) == 2 && len(y) == 2 {
+		x, y = append(x, decimal.Zero), append(y, decimal.Zero)
+	}
+	if len(x) != 3 || len(y) != 3 {
+		return nil, fmt.Errorf("%w: 叉积只对二维或三维向量有定义", ErrDimensionMismatch)
+	}
+	z := []decimal.Decimal{
+		c.round(x[1].Mul(y[2]).Sub(x[2].Mul(y[1]))),
+		c.round(x[2].Mul(y[0]).Sub(x[0].Mul(y[2]))),
+		c.round(x[0].Mul(y[1]).Sub(x[1].Mul(y[0]))),
+	}
+	if a.Rows() == 1 {
+		return Matrix{z}, nil
+	}
+	return mapMatrix(3, 1, func(i, _ int) decimal.Decimal { return z[i] }), nil
+}
+
+// Norm 计算向量的欧几里得范数，对一般矩阵计算 Frobenius 范数
+func (c *Calculator) Norm(m Matrix) (decimal.Decimal, error) {
+	sum := decimal.Zero
+	for _, x := range m.elements() {
+		sum = sum.Add(x.Mul(x))
+	}
+	if sum.IsZero() {
+		return decimal.Zero, nil
+	}
+	return c.sqrt(sum), nil
+}
+
+// Solve 求解线性方程组 a·x = b，b 为元素个数与 a 的行数相同的向量，解为列向量
+//
+// 以有理数精确消元，只对解舍入一次。无论 a 是否为方阵，独立方程少于未知数时返回 ErrUnderdetermined，
+// 方程相互矛盾时返回 ErrInconsistent。
+func (c *Calculator) Solve(a, b Matrix) (Matrix, error) {
+	y, err := b.vector("方程组的右端")
+	if err != nil {
+		return nil, err
+	}
+	if len(y) != a.Rows() {
+		return nil, fmt.Errorf("%w: 右端有 %d 个元素，系数矩阵有 %d 行", ErrDimensionMismatch, len(y), a.Rows())
+	}
+	n := a.Cols()
+	aug := toRats(a)
+	for i := range aug {
+		aug[i] = append(aug[i], y[i].Rat())
+	}
+	pivots, _ := reduce(aug, n)
+	for _, row := range aug[len(pivots):] {
+		if row[n].Sign() != 0 {
+			return nil, ErrInconsistent
+		}
+	}
+	if len(pivots) < n {
+		return nil, ErrUnderdetermined
+	}
+	return mapMatrix(n, 1, func(i, _ int) decimal.Decimal {
+		return c.RatDecimal(aug[i][n])
+	}), nil
+}
+
+// FormatMatrix 将矩阵格式化为 [a, b; c, d]，各元素按当前精度输出
+func (c *Calculator) FormatMatrix(m Matrix) string {
+	rows := make([]string, len(m))
+	for i, row := range m {
+		cells := make([]string, len(row))
+		for j, x := range row {
+			cells[j] = c.Format(x)
+		}
+		rows[i] = strings.Join(cells, ", ")
+	}
+	return "[" + strings.Join(rows, "; ") + "]"
+}
+
+// toRats 将矩阵精确地转换为有理数矩阵
+func toRats(m Matrix) [][]*big.Rat {
+	res := make([][]*big.Rat, len(m))
+	for i, row := range m {
+		res[i] = make([]*big.Rat, len(row))
+		for j, x := range row {
+			res[i][j] = x.Rat()
+		}
+	}
+	return res
+}
+
+// fromRats 按当前精度将有理数矩阵转换为矩阵
+func (c *Calculator) fromRats(a [][]*big.Rat) Matrix {
+	return mapMatrix(len(a), len(a[0]), func(i, j int) decimal.Decimal {
+		return c.RatDecimal(a[i][j])
+	})
+}
+
+// identityRats 返回 n 阶有理数单位矩阵
+func identityRats(n int) [][]*big.Rat {
+	res := make([][]*big.Rat, n)
+	for i := range res {
+		res[i] = make([]*big.Rat, n)
+		for j := range res[i] {
+			res[i][j] = new(big.Rat)
+		}
+		res[i][i].SetInt64(1)
+	}
+	return res
+}
+
+// mulRats 计算有理数矩阵的乘积
+func mulRats(a, b [][]*big.Rat) [][]*big.Rat {
+	res := make([][]*big.Rat, len(a))
+	t := new(big.Rat)
+	for i := range a {
+		res[i] = make([]*big.Rat, len(b[0]))
+		for j := range res[i] {
+			sum := new(big.Rat)
+			for k := range b {
+				sum.Add(sum, t.Mul(a[i][k], b[k][j]))
+			}
+			res[i][j] = sum
+		}
+	}
+	return res
+}
+
+// ratsBits 返回有理数矩阵中最大的分子或分母的二进制位数，用于判断结果是否过大
+func ratsBits(a [][]*big.Rat) int {
+	bits := 0
+	for _, row := range a {
+		for _, x := range row {
+			bits = max(bits, x.Num().BitLen(), x.Denom().BitLen())
+		}
+	}
+	return bits
+}
+
+// reduce 将有理数矩阵 a 就地化为行最简形，只在前 cols 列中选取主元
+//
+// 返回各主元所在的列，以及各主元之积与行交换符号之积；
+// 当 cols 等于方阵的阶数且主元个数与阶数相同时，后者即为行列式。
+func reduce(a [][]*big.Rat, cols int) (pivots []int, det *big.Rat) {
+	det = big.NewRat(1, 1)
+	t := new(big.Rat)
+	row := 0
+	for col := 0; col < cols && row < len(a); col++ {
+		p := -1
+		for i := row; i < len(a); i++ {
+			if a[i][col].Sign() != 0 {
+				p = i
+				break
+			}
+		}
+		if p < 0 {
+			continue
+		}
+		if p != row {
+			a[p], a[row] = a[row], a[p]
+			det.Neg(det)
+		}
+		pivot := new(big.Rat).Set(a[row][col])
+		det.Mul(det, pivot)
+		for j := range a[row] {
+			a[row][j].Quo(a[row][j], pivot)
+		}
+		for i := range a {
+			if i == row || a[i][col].Sign() == 0 {
+				continue
+			}
+			f := new(big.Rat).Set(a[i][col])
+			for j := range a[i] {
+				a[i][j].Sub(a[i][j], t.Mul(f, a[row][j]))
+			}
+		}
+		pivots = append(pivots, col)
+		row++
+	}
+	return pivots, det
+}
+
+// invertRats 以高斯-约当消元计算有理数方阵的逆矩阵，奇异矩阵返回 ErrSingular
+func invertRats(a [][]*big.Rat) ([][]*big.Rat, error) {
+	n := len(a)
+	aug := make([][]*big.Rat, n)
+	id := identityRats(n)
+	for i := range a {
+		aug[i] = make([]*big.Rat, 0, 2*n)
+		for _, x := range a[i] {
+			aug[i] = append(aug[i], new(big.Rat).Set(x))
+		}
+		aug[i] = append(aug[i], id[i]...)
+	}
+	if pivots, _ := reduce(aug, n); len(pivots) < n {
+		return nil, ErrSingular
+	}
+	res := make([][]*big.Rat, n)
+	for i := range aug {
+		res[i] = aug[i][n:]
+	}
+	return res, nil
+}
@@ -0,0 +1,77 @@
+package calculator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// PrecisionMode 定义精度 precision 的含义
+type PrecisionMode int
+
+const (
+	DecimalPlaces     PrecisionMode = iota // 保留 precision 位小数（默认）
+	SignificantDigits                      // 保留 precision 位有效数字
+)
+
+// precisionModeNames 精度模式与其名称的对应关系
+var precisionModeNames = map[PrecisionMode]string{
+	DecimalPlaces:     "decimal",
+	SignificantDigits: "significant",
+}
+
+func (m PrecisionMode) String() string {
+	if name, ok := precisionModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("PrecisionMode(%d)", int(m))
+}
+
+// ParsePrecisionMode 根据名称解析精度模式，名称不区分大小写
+func ParsePrecisionMode(name string) (PrecisionMode, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for mode, n := range precisionModeNames {
+		if n == name {
+			return mode, nil
+		}
+	}
+	return DecimalPlaces, fmt.Errorf("未知的精度模式: %s", name)
+}
+
+// maxSignificantPlaces 有效数字模式下为寻找非零结果最多尝试的小数位数
+const maxSignificantPlaces = 1000
+
+// magnitude 返回 d 最高位数字所在的位置：123 为 3，0.0012 为 -2，d 不能为零
+func magnitude(d decimal.Decimal) int32 {
+	return int32(d.NumDigits()) + d.Exponent()
+}
+
+// places 返回舍入 d 时应保留的小数位数
+func (c *Calculator) places(d decimal.Decimal) int32 {
+	if c.precisionMode == DecimalPlaces || d.IsZero() {
+		return c.precision
+	}
+	return c.precision - magnitude(d)
+}
+
+// approx 以足够的工作精度调用 f 近似计算结果，再按当前精度与舍入方式舍入
+//
+// f 的参数为需要保留的小数位数。有效数字模式下结果的量级越小，
+// 保留相同有效数字需要的小数位越多，因此会按结果的量级提高工作精度后重新计算。
+func (c *Calculator) approx(f func(prec int32) decimal.Decimal) decimal.Decimal {
+	wp := c.workPrecision()
+	res := f(wp)
+	if c.precisionMode == SignificantDigits {
+		for res.IsZero() && wp < maxSignificantPlaces {
+			wp *= 2
+			res = f(wp)
+		}
+		if !res.IsZero() {
+			if need := c.places(res) + guardDigits; need > wp {
+				res = f(need)
+			}
+		}
+	}
+	return c.roundApprox(res)
+}
@@ -0,0 +1,323 @@
+package calculator
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// maxPrimeDigits 素性检验与素数搜索允许的最大位数
+const maxPrimeDigits = 1000
+
+// maxRhoIterations Pollard rho 算法寻找一个因子时允许的最大迭代次数
+const maxRhoIterations = 1 << 22
+
+// millerRabinRounds 素性检验中随机底数的 Miller-Rabin 测试轮数，另外总会进行一次 Baillie-PSW 测试
+const millerRabinRounds = 20
+
+// bigOne 整数 1，只读
+var bigOne = big.NewInt(1)
+
+// smallPrimes 试除使用的小素数表
+var smallPrimes = func() []int64 {
+	var primes []int64
+	for n := int64(2); n < 1000; n++ {
+		prime := true
+		for _, p := range primes {
+			if p*p > n {
+				break
+			}
+			if n%p == 0 {
+				prime = false
+				break
+			}
+		}
+		if prime {
+			primes = append(primes, n)
+		}
+	}
+	return primes
+}()
+
+// Factor 表示质因数分解中的一项 Prime^Exponent，负数的分解以 -1 开头
+type Factor struct {
+	Prime    *big.Int
+	Exponent int
+}
+
+// FormatFactors 将质因数分解格式化为 2^3 * 3 * 7 的形式，空分解表示 1
+func FormatFactors(factors []Factor) string {
+	if len(factors) == 0 {
+		return "1"
+	}
+	parts := make([]string, len(factors))
+	for i, f := range factors {
+		parts[i] = f.Prime.String()
+		if f.Exponent > 1 {
+			parts[i] += fmt.Sprintf("^%d", f.Exponent)
+		}
+	}
+	return strings.Join(parts, " * ")
+}
+
+// parsePrimeCandidate 解析素数相关函数的整数参数，并限制其位数
+func parsePrimeCandidate(value, name string) (*big.Int, error) {
+	n, err := parseInteger(value, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(n.String()) > maxPrimeDigits {
+		return nil, ErrOverflow
+	}
+	return n, nil
+}
+
+// isPrime 判断 n 是否为素数，使用 Miller-Rabin 与 Baillie-PSW 测试
+func isPrime(n *big.Int) bool {
+	return n.ProbablyPrime(millerRabinRounds)
+}
+
+// IsPrime 判断整数 n 是否为素数，是则返回 1，否则返回 0
+func (c *Calculator) IsPrime(n string) (*big.Int, error) {
+	v, err := parsePrimeCandidate(n, "isprime")
+	if err != nil {
+		return nil, err
+	}
+	if v.Sign() > 0 && isPrime(v) {
+		return big.NewInt(1), nil
+	}
+	return new(big.Int), nil
+}
+
+// NextPrime 返回大于 n 的最小素数
+func (c *Calculator) NextPrime(n string) (*big.Int, error) {
+	v, err := parsePrimeCandidate(n, "nextprime")
+	if err != nil {
+		return nil, err
+	}
+	if v.Cmp(big.NewInt(2)) < 0 {
+		return big.NewInt(2), nil
+	}
+	// 从 n 之后的第一个奇数开始，只检验奇数
+	res := new(big.Int).Add(v, bigOne)
+	if res.Bit(0) == 0 {
+		res.Add(res, bigOne)
+	}
+	step := big.NewInt(2)
+	for !isPrime(res) {
+		res.Add(res, step)
+	}
+	return res, nil
+}
+
+// pollardRho 使用 Brent 改进的 Pollard rho 算法寻找合数 n 的一个非平凡因子，失败时返回 nil
+func pollardRho(n *big.Int) *big.Int {
+	const batch = 128 // 累积若干个差值后再求一次最大公约数
+	iterations := 0
+	for c := int64(1); iterations < maxRhoIterations; c++ {
+		cv := big.NewInt(c)
+		// f(x) = x² + c mod n
+		f := func(x *big.Int) {
+			x.Mul(x, x).Add(x, cv).Mod(x, n)
+		}
+
+		y := big.NewInt(2)
+		x := new(big.Int)
+		ys := new(big.Int)
+		q := big.NewInt(1)
+		g := big.NewInt(1)
+		diff := new(big.Int)
+		for r := 1; g.Cmp(bigOne) == 0 && iterations < maxRhoIterations; r *= 2 {
+			x.Set(y)
+			for i := 0; i < r; i++ {
+				f(y)
+			}
+			iterations += r
+			for k := 0; k < r && g.Cmp(bigOne) == 0; k += batch {
+				ys.Set(y)
+				for i := 0; i < batch && i < r-k; i++ {
+					f(y)
+					q.Mul(q, diff.Sub(x, y).Abs(diff)).Mod(q, n)
+				}
+				g.GCD(nil, nil, q, n)
+				iterations += batch
+			}
+		}
+		if g.Cmp(n) == 0 {
+			// 一批差值的乘积恰为 n 的倍数，逐步回退找出因子
+			for {
+				f(ys)
+				g.GCD(nil, nil, diff.Sub(x, ys).Abs(diff), n)
+				if g.Cmp(bigOne) != 0 {
+					break
+				}
+			}
+		}
+		if g.Cmp(bigOne) != 0 && g.Cmp(n) != 0 {
+			return g
+		}
+	}
+	return nil
+}
+
+// factorize 将正整数 n 分解为质因数，结果按质数从小到大排列
+func factorize(n *big.Int) ([]Factor, error) {
+	counts := map[string]int{}
+	primes := map[string]*big.Int{}
+	add := func(p *big.Int) {
+		key := p.String()
+		if counts[key] == 0 {
+			primes[key] = new(big.Int).Set(p)
+		}
+		counts[key]++
+	}
+
+	m := new(big.Int).Set(n)
+	rem := new(big.Int)
+	for _, sp := range smallPrimes {
+		p := big.NewInt(sp)
+		for {
+			q, r := new(big.Int).QuoRem(m, p, rem)
+			if r.Sign() != 0 {
+				break
+			}
+			add(p)
+			m = q
+		}
+	}
+
+	// 剩余部分不含小于 1000 的因子，逐个拆分为素数
+	stack := []*big.Int{}
+	if m.Cmp(bigOne) > 0 {
+		stack = append(stack, m)
+	}
+	for len(stack) > 0 {
+		m := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if isPrime(m) {
+			add(m)
+			continue
+		}
+		if s := new(big.Int).Sqrt(m); new(big.Int).Mul(s, s).Cmp(m) == 0 {
+			// rho 算法对完全平方数效率较低，直接拆分
+			stack = append(stack, s, s)
+			continue
+		}
+		d := pollardRho(m)
+		if d == nil {
+			return nil, fmt.Errorf("%w: 无法在限定步数内分解 %s", ErrOverflow, m)
+		}
+		stack = append(stack, d, new(big.Int).Quo(m, d))
+	}
+
+	factors := make([]Factor, 0, len(primes))
+	for key, p := range primes {
+		factors = append(factors, Factor{Prime: p, Exponent: counts[key]})
+	}
+	sort.Slice(factors, func(i, j int) bool {
+		return factors[i].Prime.Cmp(factors[j].Prime) < 0
+	})
+	return factors, nil
+}
+
+// Factorize 将非零整数 n 分解为质因数，负数的分解以 -1 开头，1 的分解为空
+func (c *Calculator) Factorize(n string) ([]Factor, error) {
+	v, err := parsePrimeCandidate(n, "factor")
+	if err != nil {
+		return nil, err
+	}
+	if v.Sign() == 0 {
+		return nil, domainError("factor的参数不能为0")
+	}
+	var factors []Factor
+	if v.Sign() < 0 {
+		factors = append(factors, Factor{Prime: big.NewInt(-1), Exponent: 1})
+		v.Neg(v)
+	}
+	rest, err := factorize(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(factors, rest...), nil
+}
+
+// Totient 计算欧拉函数 φ(n)，即不超过 n 且与 n 互素的正整数个数
+func (c *Calculator) Totient(n string) (*big.Int, error) {
+	v, err := parsePrimeCandidate(n, "totient")
+	if err != nil {
+		return nil, err
+	}
+	if v.Sign() <= 0 {
+		return nil, domainError("totient的参数必须为正整数")
+	}
+	factors, err := factorize(v)
+	if err != nil {
+		return nil, err
+	}
+	// φ(n) = n·∏(1 - 1/p)
+	res := new(big.Int).Set(v)
+	for _, f := range factors {
+		res.Quo(res, f.Prime)
+		res.Mul(res, new(big.Int).Sub(f.Prime, bigOne))
+	}
+	return res, nil
+}
+
+// parseModulus 解析模运算的模数，要求为正整数
+func parseModulus(value, name string) (*big.Int, error) {
+	m, err := parseInteger(value, name)
+	if err != nil {
+		return nil, err
+	}
+	if m.Sign() <= 0 {
+		return nil, domainError(fmt.Sprintf("%s的模数必须为正整数", name))
+	}
+	return m, nil
+}
+
+// PowMod 计算 a^b mod m，结果在 [0, m) 内；b 为负数时使用 a 的模逆元
+func (c *Calculator) PowMod(a, b, m string) (*big.Int, error) {
+	av, err := parseInteger(a, "powmod")
+	if err != nil {
+		return nil, err
+	}
+	bv, err := parseInteger(b, "powmod")
+	if err != nil {
+		return nil, err
+	}
+	mv, err := parseModulus(m, "powmod")
+	if err != nil {
+		return nil, err
+	}
+	if mv.Cmp(bigOne) == 0 {
+		return new(big.Int), nil
+	}
+	av.Mod(av, mv)
+	res := new(big.Int).Exp(av, bv, mv)
+	if res == nil {
+		return nil, domainError(fmt.Sprintf("%s与%s不互素，不存在模逆元", a, m))
+	}
+	return res, nil
+}
+
+// ModInverse 计算 a 关于模 m 的逆元 x，满足 a·x ≡ 1 (mod m)，结果在 [0, m) 内
+func (c *Calculator) ModInverse(a, m string) (*big.Int, error) {
+	av, err := parseInteger(a, "modinv")
+	if err != nil {
+		return nil, err
+	}
+	mv, err := parseModulus(m, "modinv")
+	if err != nil {
+		return nil, err
+	}
+	if mv.Cmp(bigOne) == 0 {
+		return new(big.Int), nil
+	}
+	av.Mod(av, mv)
+	res := new(big.Int).ModInverse(av, mv)
+	if res == nil {
+		return nil, domainError(fmt.Sprintf("%s与%s不互素，不存在模逆元", a, m))
+	}
+	return res, nil
+}
@@ -0,0 +1,96 @@
+package calculator
+
+import (
+	"math/big"
+
+	"github.com/shopspring/decimal"
+)
+
+// Exact 报告计算器是否处于精确模式
+func (c *Calculator) Exact() bool {
+	return c.exact
+}
+
+// ParseRational 将十进制数字字符串精确地解析为有理数
+func ParseRational(value string) (*big.Rat, error) {
+	d, err := ParseNumber(value)
+	if err != nil {
+		return nil, err
+	}
+	return d.Rat(), nil
+}
+
+// RatAdd 执行有理数加法
+func (c *Calculator) RatAdd(a, b *big.Rat) (*big.Rat, error) {
+	return new(big.Rat).Add(a, b), nil
+}
+
+// RatSubtract 执行有理数减法
+func (c *Calculator) RatSubtract(a, b *big.Rat) (*big.Rat, error) {
+	return new(big.Rat).Sub(a, b), nil
+}
+
+// RatMultiply 执行有理数乘法
+func (c *Calculator) RatMultiply(a, b *big.Rat) (*big.Rat, error) {
+	return new(big.Rat).Mul(a, b), nil
+}
+
+// RatDivide 执行有理数除法
+func (c *Calculator) RatDivide(a, b *big.Rat) (*big.Rat, error) {
+	if b.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	return new(big.Rat).Quo(a, b), nil
+}
+
+// RatPower 计算有理数的整数次幂
+//
+// 指数不是整数时返回 ErrDomain；结果的位数超过 maxExactPowDigits 时返回 ErrOverflow，
+// 调用方可以改用近似计算。
+func (c *Calculator) RatPower(base, exponent *big.Rat) (*big.Rat, error) {
+	if !exponent.IsInt() {
+		return nil, domainError("精确模式下只支持整数次幂")
+	}
+	e := exponent.Num()
+	if base.Sign() == 0 {
+		if e.Sign() < 0 {
+			return nil, ErrDivisionByZero
+		}
+		if e.Sign() == 0 {
+			return big.NewRat(1, 1), nil
+		}
+		return new(big.Rat), nil
+	}
+
+	// 按分子分母的二进制位数估算结果大小（每个十进制位约 3.32 个二进制位），避免构造过大的整数
+	n := new(big.Int).Abs(e)
+	bits := big.NewInt(int64(base.Num().BitLen() + base.Denom().BitLen() - 2))
+	if bits.Mul(bits, n).Cmp(big.NewInt(maxExactPowDigits*332/100)) > 0 {
+		return nil, ErrOverflow
+	}
+	num := new(big.Int).Exp(base.Num(), n, nil)
+	den := new(big.Int).Exp(base.Denom(), n, nil)
+	if e.Sign() < 0 {
+		num, den = den, num
+	}
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+// RatDecimal 按当前精度和舍入方式将有理数转换为十进制数
+func (c *Calculator) RatDecimal(r *big.Rat) decimal.Decimal {
+	return c.div(decimal.NewFromBigInt(r.Num(), 0), decimal.NewFromBigInt(r.Denom(), 0))
+}
+
+// FormatRational 将有理数格式化为最简分数及其十进制值
+//
+// 整数直接输出，例如 1；其余输出 "7/12 ≈ 0.5833333333"，十进制值恰好相等时用 "=" 连接。
+func (c *Calculator) FormatRational(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	d := c.RatDecimal(r)
+	if d.Rat().Cmp(r) == 0 {
+		return r.RatString() + " = " + c.Format(d)
+	}
+	return r.RatString() + " ≈ " + c.Format(d)
+}
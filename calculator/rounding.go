@@ -0,0 +1,123 @@
+package calculator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// RoundingMode 定义计算结果舍入到指定精度时采用的规则
+type RoundingMode int
+
+const (
+	RoundHalfUp   RoundingMode = iota // 四舍五入，恰好一半时远离零（默认）
+	RoundHalfEven                     // 银行家舍入，恰好一半时取偶数
+	RoundHalfDown                     // 五舍六入，恰好一半时趋向零
+	RoundDown                         // 截断，直接舍弃多余的位数
+	RoundCeiling                      // 向正无穷方向舍入
+	RoundFloor                        // 向负无穷方向舍入
+	RoundToOdd                        // 向奇数舍入，结果不精确时末位总为奇数
+)
+
+// roundingModeNames 舍入方式与其名称的对应关系
+var roundingModeNames = map[RoundingMode]string{
+	RoundHalfUp:   "half_up",
+	RoundHalfEven: "half_even",
+	RoundHalfDown: "half_down",
+	RoundDown:     "down",
+	RoundCeiling:  "ceiling",
+	RoundFloor:    "floor",
+	RoundToOdd:    "odd",
+}
+
+func (m RoundingMode) String() string {
+	if name, ok := roundingModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("RoundingMode(%d)", int(m))
+}
+
+// ParseRoundingMode 根据名称解析舍入方式，名称不区分大小写
+func ParseRoundingMode(name string) (RoundingMode, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for mode, n := range roundingModeNames {
+		if n == name {
+			return mode, nil
+		}
+	}
+	return RoundHalfUp, fmt.Errorf("未知的舍入方式: %s", name)
+}
+
+// finish 根据截断后的结果 t 完成舍入
+//
+// sign 为精确值的符号，exact 表示截断时没有舍弃任何数字，
+// cmpHalf 为被舍弃部分与半个末位单位比较的结果（-1、0、1）。
+func (m RoundingMode) finish(t decimal.Decimal, sign int, exact bool, cmpHalf int, prec int32) decimal.Decimal {
+	if exact {
+		return t
+	}
+
+	var away bool
+	switch m {
+	case RoundHalfUp:
+		away = cmpHalf >= 0
+	case RoundHalfDown:
+		away = cmpHalf > 0
+	case RoundHalfEven:
+		away = cmpHalf > 0 || cmpHalf == 0 && lastDigitOdd(t, prec)
+	case RoundCeiling:
+		away = sign > 0
+	case RoundFloor:
+		away = sign < 0
+	case RoundToOdd:
+		away = !lastDigitOdd(t, prec)
+	}
+	if !away {
+		return t
+	}
+
+	ulp := decimal.New(1, -prec)
+	if sign < 0 {
+		return t.Sub(ulp)
+	}
+	return t.Add(ulp)
+}
+
+// lastDigitOdd 判断 t 在第 prec 位小数上的数字是否为奇数
+func lastDigitOdd(t decimal.Decimal, prec int32) bool {
+	return t.Shift(prec).BigInt().Bit(0) == 1
+}
+
+// roundDec 按舍入方式 mode 将精确值 d 舍入到 prec 位小数
+func roundDec(d decimal.Decimal, prec int32, mode RoundingMode) decimal.Decimal {
+	t := d.RoundDown(prec)
+	rest := d.Sub(t).Abs()
+	return mode.finish(t, d.Sign(), rest.IsZero(), rest.Cmp(decimal.New(5, -prec-1)), prec)
+}
+
+// divRound 计算 l / r 并按舍入方式 mode 精确舍入到 prec 位小数，r 不能为零
+func divRound(l, r decimal.Decimal, prec int32, mode RoundingMode) decimal.Decimal {
+	// QuoRem 得到向零截断的商，余数与 l 同号
+	q, rem := l.QuoRem(r, prec)
+	twice := rem.Abs().Mul(two)
+	unit := r.Abs().Shift(-prec)
+	return mode.finish(q, l.Sign()*r.Sign(), rem.IsZero(), twice.Cmp(unit), prec)
+}
+
+// sqrtRound 计算 v 的平方根并按舍入方式 mode 精确舍入到 prec 位小数，v 必须非负
+func sqrtRound(v decimal.Decimal, prec int32, mode RoundingMode) decimal.Decimal {
+	ulp := decimal.New(1, -prec)
+
+	// 先求近似值，再校正为满足 s² ≤ v < (s+ulp)² 的截断结果
+	s := sqrtDec(v, prec+guardDigits).RoundDown(prec)
+	for s.Mul(s).GreaterThan(v) {
+		s = s.Sub(ulp)
+	}
+	for next := s.Add(ulp); next.Mul(next).LessThanOrEqual(v); next = s.Add(ulp) {
+		s = next
+	}
+
+	mid := s.Add(decimal.New(5, -prec-1))
+	return mode.finish(s, 1, s.Mul(s).Equal(v), v.Cmp(mid.Mul(mid)), prec)
+}
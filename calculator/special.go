@@ -0,0 +1,386 @@
+package calculator
+
+import (
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// oneHalf 常量 0.5
+var oneHalf = decimal.New(5, -1)
+
+// bernoulliCache 缓存已计算的偶数下标伯努利数 B_2、B_4、…
+var bernoulliCache struct {
+	sync.Mutex
+	values []*big.Rat
+}
+
+// bernoulli 返回伯努利数 B_2k（k ≥ 1）
+func bernoulli(k int) *big.Rat {
+	bernoulliCache.Lock()
+	defer bernoulliCache.Unlock()
+
+	if k > len(bernoulliCache.values) {
+		n := 2 * len(bernoulliCache.values)
+		if n < k {
+			n = k
+		}
+		if n < 32 {
+			n = 32
+		}
+		bernoulliCache.values = tangentBernoulli(n)
+	}
+	return bernoulliCache.values[k-1]
+}
+
+// tangentBernoulli 先计算正切数 T_1…T_n，再由 B_2k = (-1)^(k-1)·2k·T_k / (2^2k·(2^2k - 1)) 得到伯努利数
+//
+// 正切数只涉及整数运算，比直接用有理数递推快得多（Brent-Harvey 算法）。
+func tangentBernoulli(n int) []*big.Rat {
+	t := make([]*big.Int, n+1)
+	t[1] = big.NewInt(1)
+	for k := 2; k <= n; k++ {
+		t[k] = new(big.Int).Mul(big.NewInt(int64(k-1)), t[k-1])
+	}
+	a := new(big.Int)
+	for k := 2; k <= n; k++ {
+		for j := k; j <= n; j++ {
+			// T_j = (j-k)·T_(j-1) + (j-k+2)·T_j
+			a.Mul(big.NewInt(int64(j-k)), t[j-1])
+			t[j].Mul(t[j], big.NewInt(int64(j-k+2))).Add(t[j], a)
+		}
+	}
+
+	res := make([]*big.Rat, n)
+	for k := 1; k <= n; k++ {
+		p := new(big.Int).Lsh(big.NewInt(1), uint(2*k))
+		den := new(big.Int).Sub(p, big.NewInt(1))
+		den.Mul(den, p)
+		num := new(big.Int).Mul(big.NewInt(int64(2*k)), t[k])
+		if k%2 == 0 {
+			num.Neg(num)
+		}
+		res[k-1] = new(big.Rat).SetFrac(num, den)
+	}
+	return res
+}
+
+// asymptoticShift 返回使用渐近展开前需要把参数平移到的下限
+//
+// 斯特林级数的项在 k ≈ πy 之前单调递减，y 不小于精度位数时足以收敛到 10^(-prec)。
+func asymptoticShift(prec int32) decimal.Decimal {
+	return decimal.NewFromInt(int64(prec) + 10)
+}
+
+// bernoulliSum 计算 Σ B_2k / (d(k)·y^(2k-s))，直到项小于 10^(-prec)
+//
+// s 为 1 时 d(k) = 2k(2k-1)，用于 ln Γ 的斯特林级数；s 为 0 时 d(k) = 2k，用于 ψ 的渐近展开。
+func bernoulliSum(y decimal.Decimal, s int64, prec int32) decimal.Decimal {
+	sig := prec + guardDigits
+	y2 := y.Mul(y)
+	pow := y2 // y^(2k-s)，按有效数字舍入以控制位数
+	if s == 1 {
+		pow = y
+	}
+	sum := decimal.Zero
+	for k := int64(1); ; k++ {
+		b := bernoulli(int(k))
+		d := 2 * k
+		if s == 1 {
+			d *= 2*k - 1
+		}
+		den := decimal.NewFromBigInt(b.Denom(), 0).Mul(decimal.NewFromInt(d)).Mul(pow)
+		term := decimal.NewFromBigInt(b.Num(), 0).DivRound(den, prec)
+		if term.IsZero() {
+			break
+		}
+		sum = sum.Add(term)
+		pow = pow.Mul(y2)
+		pow = pow.Round(sig - magnitude(pow))
+	}
+	return sum
+}
+
+// lnGammaPos 计算 x > 0 时的 ln Γ(x)，保留 prec 位小数
+//
+// x 较小时先用 Γ(x) = Γ(x+n) / (x·(x+1)·…·(x+n-1)) 平移到斯特林级数的收敛范围。
+func lnGammaPos(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	y := x
+	prod := one
+	for limit := asymptoticShift(wp); y.LessThan(limit); y = y.Add(one) {
+		prod = prod.Mul(y)
+		prod = prod.Round(wp + guardDigits - magnitude(prod))
+	}
+
+	// ln Γ(y) ≈ (y - 1/2)·ln y - y + ln(2π)/2 + Σ B_2k / (2k(2k-1)·y^(2k-1))
+	lny := lnDec(y, wp+intDigits(y))
+	res := y.Sub(oneHalf).Mul(lny).Sub(y)
+	res = res.Add(lnDec(piDec(wp).Mul(two), wp).DivRound(two, wp))
+	res = res.Add(bernoulliSum(y, 1, wp))
+	if !prod.Equal(one) {
+		res = res.Sub(lnDec(prod, wp))
+	}
+	return res.Round(prec)
+}
+
+// digammaPos 计算 x > 0 时的 ψ(x)，保留 prec 位小数
+//
+// ψ(x) = ψ(x+n) - Σ 1/(x+k)，ψ(y) ≈ ln y - 1/(2y) - Σ B_2k / (2k·y^2k)。
+func digammaPos(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	y := x
+	shift := decimal.Zero
+	for limit := asymptoticShift(wp); y.LessThan(limit); y = y.Add(one) {
+		shift = shift.Add(one.DivRound(y, wp))
+	}
+	res := lnDec(y, wp).Sub(one.DivRound(y.Mul(two), wp))
+	res = res.Sub(bernoulliSum(y, 0, wp))
+	return res.Sub(shift).Round(prec)
+}
+
+// sinCosPi 计算 sin(πx) 与 cos(πx)，x 接近整数时 sin(πx) 仍保持 prec 位有效数字
+func sinCosPi(x decimal.Decimal, prec int32) (s, c decimal.Decimal) {
+	n := x.Round(0)
+	r := x.Sub(n) // |r| ≤ 1/2，sin(πx) = (-1)^n·sin(πr)
+	wp := prec + guardDigits + leadingZeros(r)
+	a := piDec(wp).Mul(r)
+	s, c = sinDec(a, wp), cosDec(a, wp)
+	if n.Mod(two).Abs().Equal(one) {
+		s, c = s.Neg(), c.Neg()
+	}
+	return s, c
+}
+
+// gammaPole 报告 x 是否为 Γ 函数的极点，即非正整数
+func gammaPole(x decimal.Decimal) bool {
+	return x.IsInteger() && !x.IsPositive()
+}
+
+// lnGammaAbs 计算 ln|Γ(x)| 及 Γ(x) 的符号，保留 prec 位小数，x 不能为非正整数
+func lnGammaAbs(x decimal.Decimal, prec int32) (decimal.Decimal, int) {
+	if x.IsPositive() {
+		return lnGammaPos(x, prec), 1
+	}
+	// 反射公式 Γ(x)·Γ(1-x) = π / sin(πx)，x ≤ 0 时 Γ(1-x) > 0
+	wp := prec + guardDigits
+	s, _ := sinCosPi(x, wp)
+	res := lnDec(piDec(wp), wp).Sub(lnDec(s.Abs(), wp)).Sub(lnGammaPos(one.Sub(x), wp))
+	return res.Round(prec), s.Sign()
+}
+
+// digammaDec 计算 ψ(x)，保留 prec 位小数，x 不能为非正整数
+func digammaDec(x decimal.Decimal, prec int32) decimal.Decimal {
+	if x.IsPositive() {
+		return digammaPos(x, prec)
+	}
+	// 反射公式 ψ(x) = ψ(1-x) - π·cot(πx)
+	wp := prec + guardDigits
+	s, c := sinCosPi(x, wp)
+	cot := c.DivRound(s, wp)
+	return digammaPos(one.Sub(x), wp).Sub(piDec(wp + intDigits(cot)).Mul(cot)).Round(prec)
+}
+
+// maxGammaDigits 伽马、贝塔函数结果整数部分允许的最大位数
+//
+// ln|Γ(x)| 需要按结果的位数提高精度计算，耗时比 exp 增长得更快，因此上限低于 maxApproxDigits。
+const maxGammaDigits = 2500
+
+// lnGammaMagnitude 粗略估算 ln|Γ(x)| 对应的十进制位数，超过 maxGammaDigits 时 ok 为 false
+func lnGammaMagnitude(l decimal.Decimal) (digits int32, ok bool) {
+	d := l.InexactFloat64() / math.Ln10
+	if d > maxGammaDigits {
+		return 0, false
+	}
+	if d < 0 {
+		return 0, true
+	}
+	return int32(d) + 1, true
+}
+
+// erfSeries 计算 erf(x) = 2/√π·e^(-x²)·Σ 2^n·x^(2n+1) / (1·3·…·(2n+1))，保留 prec 位小数
+//
+// 级数各项均为正，不存在相消；级数和约为 e^(x²)，需要相应增加位数。
+func erfSeries(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	x2 := x.Mul(x)
+	sp := wp + int32(x2.InexactFloat64()/math.Ln10) + 1
+	term := x
+	sum := x
+	for n := int64(1); ; n++ {
+		term = term.Mul(x2).Mul(two).DivRound(decimal.NewFromInt(2*n+1), sp)
+		if term.IsZero() {
+			break
+		}
+		sum = sum.Add(term)
+	}
+	e, _ := expDec(x2.Neg(), sp)
+	return sum.Mul(e).Mul(two).DivRound(sqrtDec(piDec(wp), wp), prec)
+}
+
+// erfcFraction 使用连分式计算 x > 0 时的 erfc(x)，保留 prec 位小数
+//
+// erfc(x) = e^(-x²)/√π · 1/(x + (1/2)/(x + 1/(x + (3/2)/(x + …))))，x 越大收敛越快。
+func erfcFraction(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	eps := decimal.New(1, -wp+2)
+	// Lentz 算法计算 f = x + a_1/(x + a_2/(x + …))，a_j = j/2
+	f := x
+	c := x
+	d := decimal.Zero
+	for j := int64(1); ; j++ {
+		a := decimal.NewFromInt(j).Mul(oneHalf)
+		d = one.DivRound(x.Add(a.Mul(d)), wp)
+		c = x.Add(a.DivRound(c, wp))
+		delta := c.Mul(d).Round(wp)
+		f = f.Mul(delta).Round(wp)
+		if delta.Sub(one).Abs().LessThan(eps) {
+			break
+		}
+	}
+	e, _ := expDec(x.Mul(x).Neg(), wp)
+	return e.DivRound(sqrtDec(piDec(wp), wp).Mul(f), prec)
+}
+
+// erfcThreshold x 不小于该值时用连分式计算 erfc，否则用 1 - erf 的级数
+var erfcThreshold = decimal.NewFromInt(3)
+
+// erfDec 计算误差函数 erf(x)，保留 prec 位小数
+func erfDec(x decimal.Decimal, prec int32) decimal.Decimal {
+	a := x.Abs()
+	var res decimal.Decimal
+	if a.LessThan(erfcThreshold) {
+		res = erfSeries(a, prec)
+	} else {
+		res = one.Sub(erfcFraction(a, prec))
+	}
+	if x.IsNegative() {
+		res = res.Neg()
+	}
+	return res
+}
+
+// erfcDec 计算互补误差函数 erfc(x) = 1 - erf(x)，保留 prec 位小数
+func erfcDec(x decimal.Decimal, prec int32) decimal.Decimal {
+	if x.IsNegative() {
+		return two.Sub(erfcDec(x.Neg(), prec))
+	}
+	if x.LessThan(erfcThreshold) {
+		return one.Sub(erfSeries(x, prec))
+	}
+	return erfcFraction(x, prec)
+}
+
+// Gamma 计算伽马函数 Γ(x)
+func (c *Calculator) Gamma(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if gammaPole(v) {
+		return decimal.Zero, domainError("伽马函数在非正整数处无定义")
+	}
+	// |Γ(x)| 的整数位数越多，ln|Γ(x)| 需要的小数位越多
+	l, _ := lnGammaAbs(v, 5)
+	digits, ok := lnGammaMagnitude(l)
+	if !ok {
+		return decimal.Zero, ErrOverflow
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		l, sign := lnGammaAbs(v, prec+digits+2)
+		res, _ := expDec(l, prec)
+		if sign < 0 {
+			res = res.Neg()
+		}
+		return res
+	}), nil
+}
+
+// LGamma 计算伽马函数绝对值的自然对数 ln|Γ(x)|
+func (c *Calculator) LGamma(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if gammaPole(v) {
+		return decimal.Zero, domainError("对数伽马函数在非正整数处无定义")
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		l, _ := lnGammaAbs(v, prec)
+		return l
+	}), nil
+}
+
+// Beta 计算贝塔函数 B(a, b) = Γ(a)·Γ(b) / Γ(a+b)
+func (c *Calculator) Beta(a, b string) (decimal.Decimal, error) {
+	x, y, err := parsePair(a, b)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if gammaPole(x) || gammaPole(y) {
+		return decimal.Zero, domainError("贝塔函数的参数不能为非正整数")
+	}
+	s := x.Add(y)
+	if gammaPole(s) {
+		// 1/Γ(a+b) = 0
+		return decimal.Zero, nil
+	}
+
+	lnBeta := func(prec int32) (decimal.Decimal, int) {
+		lx, sx := lnGammaAbs(x, prec)
+		ly, sy := lnGammaAbs(y, prec)
+		ls, ss := lnGammaAbs(s, prec)
+		return lx.Add(ly).Sub(ls), sx * sy * ss
+	}
+	l, _ := lnBeta(5)
+	digits, ok := lnGammaMagnitude(l)
+	if !ok {
+		return decimal.Zero, ErrOverflow
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		l, sign := lnBeta(prec + digits + 2)
+		res, _ := expDec(l, prec)
+		if sign < 0 {
+			res = res.Neg()
+		}
+		return res
+	}), nil
+}
+
+// Digamma 计算双伽马函数 ψ(x) = Γ'(x)/Γ(x)
+func (c *Calculator) Digamma(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if gammaPole(v) {
+		return decimal.Zero, domainError("双伽马函数在非正整数处无定义")
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return digammaDec(v, prec)
+	}), nil
+}
+
+// Erf 计算误差函数 erf(x)
+func (c *Calculator) Erf(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return erfDec(v, prec)
+	}), nil
+}
+
+// Erfc 计算互补误差函数 erfc(x) = 1 - erf(x)
+func (c *Calculator) Erfc(value string) (decimal.Decimal, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		return erfcDec(v, prec)
+	}), nil
+}
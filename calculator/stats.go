@@ -0,0 +1,244 @@
+package calculator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// parseValues 解析统计函数的全部参数，至少需要 minCount 个
+func parseValues(values []string, name string, minCount int) ([]decimal.Decimal, error) {
+	if len(values) < minCount {
+		return nil, domainError(fmt.Sprintf("%s至少需要%d个数据", name, minCount))
+	}
+	res := make([]decimal.Decimal, len(values))
+	for i, value := range values {
+		v, err := ParseNumber(value)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+// sortedValues 返回从小到大排列的数据副本
+func sortedValues(values []decimal.Decimal) []decimal.Decimal {
+	res := append([]decimal.Decimal(nil), values...)
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].LessThan(res[j])
+	})
+	return res
+}
+
+// Sum 计算全部数据之和
+func (c *Calculator) Sum(values ...string) (decimal.Decimal, error) {
+	xs, err := parseValues(values, "sum", 1)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.round(decimal.Sum(xs[0], xs[1:]...)), nil
+}
+
+// Product 计算全部数据之积
+func (c *Calculator) Product(values ...string) (decimal.Decimal, error) {
+	xs, err := parseValues(values, "prod", 1)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	res := one
+	for _, x := range xs {
+		res = res.Mul(x)
+		if res.NumDigits() > maxResultDigits {
+			return decimal.Zero, ErrOverflow
+		}
+	}
+	return c.round(res), nil
+}
+
+// Min 返回最小值
+func (c *Calculator) Min(values ...string) (decimal.Decimal, error) {
+	xs, err := parseValues(values, "min", 1)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.round(decimal.Min(xs[0], xs[1:]...)), nil
+}
+
+// Max 返回最大值
+func (c *Calculator) Max(values ...string) (decimal.Decimal, error) {
+	xs, err := parseValues(values, "max", 1)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.round(decimal.Max(xs[0], xs[1:]...)), nil
+}
+
+// Mean 计算算术平均数
+func (c *Calculator) Mean(values ...string) (decimal.Decimal, error) {
+	xs, err := parseValues(values, "mean", 1)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return c.div(decimal.Sum(xs[0], xs[1:]...), decimal.NewFromInt(int64(len(xs)))), nil
+}
+
+// Median 计算中位数，数据个数为偶数时取中间两个数的平均值
+func (c *Calculator) Median(values ...string) (decimal.Decimal, error) {
+	xs, err := parseValues(values, "median", 1)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	xs = sortedValues(xs)
+	n := len(xs)
+	if n%2 == 1 {
+		return c.round(xs[n/2]), nil
+	}
+	return c.round(xs[n/2-1].Add(xs[n/2]).Mul(oneHalf)), nil
+}
+
+// Mode 返回出现次数最多的数，次数相同时取其中最小的一个
+func (c *Calculator) Mode(values ...string) (decimal.Decimal, error) {
+	xs, err := parseValues(values, "mode", 1)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	xs = sortedValues(xs)
+	best, bestCount := xs[0], 0
+	for i := 0; i < len(xs); {
+		j := i
+		for j < len(xs) && xs[j].Equal(xs[i]) {
+			j++
+		}
+		if j-i > bestCount {
+			best, bestCount = xs[i], j-i
+		}
+		i = j
+	}
+	return c.round(best), nil
+}
+
+// sumOfSquares 返回方差公式 (n·Σx² - (Σx)²) / d 的分子与分母
+//
+// 总体方差 d = n²，样本方差 d = n·(n-1)，分子分母均为精确值。
+func sumOfSquares(xs []decimal.Decimal, sample bool) (num, den decimal.Decimal) {
+	n := decimal.NewFromInt(int64(len(xs)))
+	sum, sq := decimal.Zero, decimal.Zero
+	for _, x := range xs {
+		sum = sum.Add(x)
+		sq = sq.Add(x.Mul(x))
+	}
+	num = n.Mul(sq).Sub(sum.Mul(sum))
+	if sample {
+		return num, n.Mul(n.Sub(one))
+	}
+	return num, n.Mul(n)
+}
+
+// variance 计算总体方差或样本方差
+func (c *Calculator) variance(values []string, name string, sample bool) (decimal.Decimal, error) {
+	minCount := 1
+	if sample {
+		minCount = 2
+	}
+	xs, err := parseValues(values, name, minCount)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	num, den := sumOfSquares(xs, sample)
+	return c.div(num, den), nil
+}
+
+// stddev 计算总体标准差或样本标准差
+func (c *Calculator) stddev(values []string, name string, sample bool) (decimal.Decimal, error) {
+	minCount := 1
+	if sample {
+		minCount = 2
+	}
+	xs, err := parseValues(values, name, minCount)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	num, den := sumOfSquares(xs, sample)
+	if num.IsZero() {
+		return decimal.Zero, nil
+	}
+	return c.approx(func(prec int32) decimal.Decimal {
+		// 方差保留 2·prec 位小数，开方后误差不超过 10^(-prec)
+		return sqrtDec(num.DivRound(den, 2*prec), prec)
+	}), nil
+}
+
+// Variance 计算总体方差 Σ(x - x̄)² / n
+func (c *Calculator) Variance(values ...string) (decimal.Decimal, error) {
+	return c.variance(values, "variance", false)
+}
+
+// SampleVariance 计算样本方差 Σ(x - x̄)² / (n - 1)
+func (c *Calculator) SampleVariance(values ...string) (decimal.Decimal, error) {
+	return c.variance(values, "variance_s", true)
+}
+
+// StdDev 计算总体标准差
+func (c *Calculator) StdDev(values ...string) (decimal.Decimal, error) {
+	return c.stddev(values, "stddev", false)
+}
+
+// SampleStdDev 计算样本标准差
+func (c *Calculator) SampleStdDev(values ...string) (decimal.Decimal, error) {
+	return c.stddev(values, "stddev_s", true)
+}
+
+// hundred 常量 100
+var hundred = decimal.NewFromInt(100)
+
+// Percentile 计算第 p 百分位数，p ∈ [0, 100]
+//
+// 采用线性插值：位置 h = (n-1)·p/100，结果为 x_⌊h⌋ + (h - ⌊h⌋)·(x_(⌊h⌋+1) - x_⌊h⌋)，与 Excel 的 PERCENTILE.INC 一致。
+func (c *Calculator) Percentile(p string, values ...string) (decimal.Decimal, error) {
+	pv, err := ParseNumber(p)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if pv.IsNegative() || pv.GreaterThan(hundred) {
+		return decimal.Zero, domainError("百分位必须在0到100之间")
+	}
+	xs, err := parseValues(values, "percentile", 1)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	xs = sortedValues(xs)
+	h := pv.Mul(decimal.NewFromInt(int64(len(xs) - 1))).Shift(-2)
+	lo := h.Floor()
+	i := int(lo.IntPart())
+	if i == len(xs)-1 {
+		return c.round(xs[i]), nil
+	}
+	return c.round(xs[i].Add(h.Sub(lo).Mul(xs[i+1].Sub(xs[i])))), nil
+}
+
+// GeoMean 计算几何平均数 (x_1·x_2·…·x_n)^(1/n)，要求全部数据为正数
+func (c *Calculator) GeoMean(values ...string) (decimal.Decimal, error) {
+	xs, err := parseValues(values, "geomean", 1)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	for _, x := range xs {
+		if !x.IsPositive() {
+			return decimal.Zero, domainError("几何平均数的数据必须为正数")
+		}
+	}
+	n := decimal.NewFromInt(int64(len(xs)))
+	// 结果不超过最大值，exp 会把对数的误差按结果的大小放大
+	extra := intDigits(decimal.Max(xs[0], xs[1:]...))
+	return c.approx(func(prec int32) decimal.Decimal {
+		wp := prec + extra + guardDigits
+		sum := decimal.Zero
+		for _, x := range xs {
+			sum = sum.Add(lnDec(x, wp))
+		}
+		res, _ := expDec(sum.DivRound(n, wp), prec)
+		return res
+	}), nil
+}
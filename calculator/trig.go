@@ -0,0 +1,244 @@
+package calculator
+
+import (
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// guardDigits 内部计算时额外保留的位数，用于抵消中间步骤的舍入误差
+const guardDigits = 10
+
+var (
+	one = decimal.NewFromInt(1)
+	two = decimal.NewFromInt(2)
+)
+
+// intDigits 返回 |v| 整数部分的位数，|v| < 1 时返回 0
+func intDigits(v decimal.Decimal) int32 {
+	n := int32(v.NumDigits()) + v.Exponent()
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// leadingZeros 返回 0 < |v| < 1 时小数点后首个非零数字之前零的个数
+func leadingZeros(v decimal.Decimal) int32 {
+	n := -(int32(v.NumDigits()) + v.Exponent())
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// sqrtDec 使用牛顿迭代法计算 v 的平方根，保留 prec 位小数，v 必须非负
+func sqrtDec(v decimal.Decimal, prec int32) decimal.Decimal {
+	if v.Sign() <= 0 {
+		return decimal.Zero
+	}
+
+	// 借助 float64 给出初值，先把 v 缩放到 float64 可表示的范围
+	mag := int32(v.NumDigits()) + v.Exponent()
+	half := mag / 2
+	scaled := v.Shift(-2 * half).InexactFloat64()
+	z := decimal.NewFromFloat(math.Sqrt(scaled)).Shift(half)
+
+	wp := prec + guardDigits
+	tolerance := decimal.New(1, -wp)
+	for i := 0; i < 100; i++ {
+		next := z.Add(v.DivRound(z, wp)).DivRound(two, wp)
+		if next.Sub(z).Abs().LessThanOrEqual(tolerance) {
+			z = next
+			break
+		}
+		z = next
+	}
+	return z.Round(prec)
+}
+
+// sinSeries 使用泰勒级数计算 sin(x)，要求 |x| ≤ π/4
+func sinSeries(x decimal.Decimal, prec int32) decimal.Decimal {
+	x2 := x.Mul(x).Round(prec)
+	term := x
+	sum := x
+	for k := int64(1); ; k++ {
+		term = term.Mul(x2).DivRound(decimal.NewFromInt((2*k)*(2*k+1)), prec).Neg()
+		if term.IsZero() {
+			break
+		}
+		sum = sum.Add(term)
+	}
+	return sum
+}
+
+// cosSeries 使用泰勒级数计算 cos(x)，要求 |x| ≤ π/4
+func cosSeries(x decimal.Decimal, prec int32) decimal.Decimal {
+	x2 := x.Mul(x).Round(prec)
+	term := one
+	sum := one
+	for k := int64(1); ; k++ {
+		term = term.Mul(x2).DivRound(decimal.NewFromInt((2*k-1)*(2*k)), prec).Neg()
+		if term.IsZero() {
+			break
+		}
+		sum = sum.Add(term)
+	}
+	return sum
+}
+
+// reduceQuarter 将 x 约化为 x = k·π/2 + r，|r| ≤ π/4，返回 r 和 k mod 4
+func reduceQuarter(x decimal.Decimal, prec int32) (decimal.Decimal, int64) {
+	// x 越大，约化时π需要的有效位数越多
+	wp := prec + intDigits(x)
+	halfPi := piDec(wp).DivRound(two, wp)
+	k := x.DivRound(halfPi, 0)
+	r := x.Sub(k.Mul(halfPi)).Round(prec)
+	q := k.Mod(decimal.NewFromInt(4)).IntPart()
+	if q < 0 {
+		q += 4
+	}
+	return r, q
+}
+
+// sinDec 计算 sin(x)，保留 prec 位小数
+func sinDec(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	r, q := reduceQuarter(x, wp)
+	var res decimal.Decimal
+	switch q {
+	case 0:
+		res = sinSeries(r, wp)
+	case 1:
+		res = cosSeries(r, wp)
+	case 2:
+		res = sinSeries(r, wp).Neg()
+	default:
+		res = cosSeries(r, wp).Neg()
+	}
+	return res.Round(prec)
+}
+
+// cosDec 计算 cos(x)，保留 prec 位小数
+func cosDec(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	r, q := reduceQuarter(x, wp)
+	var res decimal.Decimal
+	switch q {
+	case 0:
+		res = cosSeries(r, wp)
+	case 1:
+		res = sinSeries(r, wp).Neg()
+	case 2:
+		res = cosSeries(r, wp).Neg()
+	default:
+		res = sinSeries(r, wp)
+	}
+	return res.Round(prec)
+}
+
+// tanDec 计算 tan(x)，保留 prec 位小数；ok 为 false 表示 x 过于接近 π/2 + kπ
+func tanDec(x decimal.Decimal, prec int32) (res decimal.Decimal, ok bool) {
+	wp := prec + guardDigits
+	for {
+		s := sinDec(x, wp)
+		c := cosDec(x, wp)
+		if c.IsZero() {
+			return decimal.Zero, false
+		}
+		// cos 越接近 0，商的绝对误差越大，需要相应提高工作精度
+		extra := 2 * leadingZeros(c)
+		if extra <= wp-prec-guardDigits {
+			return s.DivRound(c, prec), true
+		}
+		wp = prec + guardDigits + extra
+	}
+}
+
+// atanDec 计算 arctan(x)，保留 prec 位小数
+func atanDec(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	if x.IsZero() {
+		return decimal.Zero
+	}
+	if x.Abs().GreaterThan(one) {
+		// arctan(x) = ±π/2 - arctan(1/x)
+		halfPi := piDec(wp).DivRound(two, wp)
+		inv := atanDec(one.DivRound(x, wp), wp)
+		if x.IsNegative() {
+			halfPi = halfPi.Neg()
+		}
+		return halfPi.Sub(inv).Round(prec)
+	}
+
+	// 利用 arctan(x) = 2·arctan(x / (1 + sqrt(1 + x²))) 把参数缩小到 0.1 以内
+	limit := decimal.New(1, -1)
+	doublings := 0
+	for x.Abs().GreaterThan(limit) {
+		x = x.DivRound(one.Add(sqrtDec(one.Add(x.Mul(x)), wp)), wp)
+		doublings++
+	}
+
+	x2 := x.Mul(x).Round(wp)
+	power := x
+	sum := x
+	for k := int64(1); ; k++ {
+		power = power.Mul(x2).Round(wp).Neg()
+		term := power.DivRound(decimal.NewFromInt(2*k+1), wp)
+		if term.IsZero() {
+			break
+		}
+		sum = sum.Add(term)
+	}
+	return sum.Mul(decimal.NewFromInt(int64(1) << doublings)).Round(prec)
+}
+
+// asinDec 计算 arcsin(x)，要求 |x| ≤ 1，保留 prec 位小数
+func asinDec(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	if x.Abs().Equal(one) {
+		halfPi := piDec(wp).DivRound(two, wp)
+		if x.IsNegative() {
+			halfPi = halfPi.Neg()
+		}
+		return halfPi.Round(prec)
+	}
+	// arcsin(x) = arctan(x / sqrt(1 - x²))，x 接近 ±1 时平方根需要更多位数
+	d := one.Sub(x.Mul(x))
+	wp += leadingZeros(d)
+	return atanDec(x.DivRound(sqrtDec(d, wp), wp), wp).Round(prec)
+}
+
+// acosDec 计算 arccos(x)，要求 |x| ≤ 1，保留 prec 位小数
+func acosDec(x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	halfPi := piDec(wp).DivRound(two, wp)
+	return halfPi.Sub(asinDec(x, wp)).Round(prec)
+}
+
+// atan2Dec 计算点 (x, y) 的辐角，结果在 (-π, π] 范围内，保留 prec 位小数
+func atan2Dec(y, x decimal.Decimal, prec int32) decimal.Decimal {
+	wp := prec + guardDigits
+	switch {
+	case x.IsZero() && y.IsZero():
+		return decimal.Zero
+	case x.IsZero():
+		halfPi := piDec(wp).DivRound(two, wp)
+		if y.IsNegative() {
+			halfPi = halfPi.Neg()
+		}
+		return halfPi.Round(prec)
+	}
+
+	// y/x 的量级越大，atan 的结果对商的误差越不敏感，但商本身需要足够的有效数字
+	wp += leadingZeros(x) + intDigits(y)
+	res := atanDec(y.DivRound(x, wp), wp)
+	if x.IsNegative() {
+		if y.IsNegative() {
+			res = res.Sub(piDec(wp))
+		} else {
+			res = res.Add(piDec(wp))
+		}
+	}
+	return res.Round(prec)
+}
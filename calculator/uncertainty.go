@@ -0,0 +1,211 @@
+package calculator
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// Uncertain 表示测量值 Value 及其标准不确定度 Sigma
+type Uncertain struct {
+	Value, Sigma decimal.Decimal
+}
+
+// NewUncertain 创建测量值 v ± sigma
+func NewUncertain(v, sigma decimal.Decimal) (Uncertain, error) {
+	if sigma.IsNegative() {
+		return Uncertain{}, domainError("不确定度不能为负数")
+	}
+	return Uncertain{Value: v, Sigma: sigma}, nil
+}
+
+// propagate 按当前精度舍入结果值，并由各偏导数与不确定度之积合成标准不确定度
+//
+// terms 依次为 ∂f/∂xᵢ·σᵢ，合成的不确定度为 sqrt(Σ termsᵢ²)。
+func (c *Calculator) propagate(v decimal.Decimal, terms ...decimal.Decimal) Uncertain {
+	sum := decimal.Zero
+	for _, t := range terms {
+		sum = sum.Add(t.Mul(t))
+	}
+	sigma := decimal.Zero
+	if sum.IsPositive() {
+		sigma = c.widened().sqrt(sum)
+	}
+	return Uncertain{Value: c.round(v), Sigma: c.round(sigma)}
+}
+
+// UAdd 计算测量值之和
+func (c *Calculator) UAdd(x, y Uncertain) (Uncertain, error) {
+	return c.propagate(x.Value.Add(y.Value), x.Sigma, y.Sigma), nil
+}
+
+// USubtract 计算测量值之差
+func (c *Calculator) USubtract(x, y Uncertain) (Uncertain, error) {
+	return c.propagate(x.Value.Sub(y.Value), x.Sigma, y.Sigma), nil
+}
+
+// UMultiply 计算测量值之积
+func (c *Calculator) UMultiply(x, y Uncertain) (Uncertain, error) {
+	return c.propagate(x.Value.Mul(y.Value), y.Value.Mul(x.Sigma), x.Value.Mul(y.Sigma)), nil
+}
+
+// UDivide 计算测量值之商
+func (c *Calculator) UDivide(x, y Uncertain) (Uncertain, error) {
+	if y.Value.IsZero() {
+		return Uncertain{}, ErrDivisionByZero
+	}
+	w := c.widened()
+	v := w.div(x.Value, y.Value)
+	return c.propagate(v, w.div(x.Sigma, y.Value), w.div(v.Mul(y.Sigma), y.Value)), nil
+}
+
+// UPower 计算测量值的乘方，∂/∂x = y·x^(y-1)，∂/∂y = x^y·ln x
+func (c *Calculator) UPower(x, y Uncertain) (Uncertain, error) {
+	w := c.widened()
+	v, err := w.Power(x.Value.String(), y.Value.String())
+	if err != nil {
+		return Uncertain{}, err
+	}
+	var dx, dy decimal.Decimal
+	if !x.Sigma.IsZero() {
+		p, err := w.Power(x.Value.String(), y.Value.Sub(one).String())
+		if err != nil {
+			return Uncertain{}, fmt.Errorf("%w: 底数处的导数不存在", err)
+		}
+		dx = y.Value.Mul(p).Mul(x.Sigma)
+	}
+	if !y.Sigma.IsZero() {
+		ln, err := w.Ln(x.Value.String())
+		if err != nil {
+			return Uncertain{}, err
+		}
+		dy = v.Mul(ln).Mul(y.Sigma)
+	}
+	return c.propagate(v, dx, dy), nil
+}
+
+// USqrt 计算测量值的平方根，∂/∂x = 1/(2·sqrt(x))
+func (c *Calculator) USqrt(x Uncertain) (Uncertain, error) {
+	if x.Value.IsNegative() {
+		return Uncertain{}, domainError("不能对负数进行开方")
+	}
+	if x.Value.IsZero() {
+		if !x.Sigma.IsZero() {
+			return Uncertain{}, domainError("平方根在零处不可导，无法传播不确定度")
+		}
+		return Uncertain{}, nil
+	}
+	w := c.widened()
+	v := w.sqrt(x.Value)
+	return c.propagate(v, w.div(x.Sigma, v.Mul(two))), nil
+}
+
+// angleScale 返回当前单位下角度对弧度的导数，即 2π/一圈的大小
+func (c *Calculator) angleScale(prec int32) decimal.Decimal {
+	if c.angleUnit == Radians {
+		return one
+	}
+	return piDec(prec).Mul(two).DivRound(c.angleUnit.turn(), prec)
+}
+
+// USin 计算测量值的正弦，∂/∂x = cos(x)
+func (c *Calculator) USin(x Uncertain) (Uncertain, error) {
+	w := c.widened()
+	v, _ := w.Sin(x.Value.String())
+	d, _ := w.Cos(x.Value.String())
+	return c.propagate(v, d.Mul(c.angleScale(w.workPrecision())).Mul(x.Sigma)), nil
+}
+
+// UCos 计算测量值的余弦，∂/∂x = -sin(x)
+func (c *Calculator) UCos(x Uncertain) (Uncertain, error) {
+	w := c.widened()
+	v, _ := w.Cos(x.Value.String())
+	d, _ := w.Sin(x.Value.String())
+	return c.propagate(v, d.Mul(c.angleScale(w.workPrecision())).Mul(x.Sigma)), nil
+}
+
+// UTan 计算测量值的正切，∂/∂x = 1 + tan²(x)
+func (c *Calculator) UTan(x Uncertain) (Uncertain, error) {
+	w := c.widened()
+	v, err := w.Tan(x.Value.String())
+	if err != nil {
+		return Uncertain{}, err
+	}
+	d := one.Add(v.Mul(v))
+	return c.propagate(v, d.Mul(c.angleScale(w.workPrecision())).Mul(x.Sigma)), nil
+}
+
+// arcSlope 返回 σ/sqrt(1 - x²) 换算到当前角度单位后的值，即反正弦与反余弦的不确定度分量
+func (c *Calculator) arcSlope(w *Calculator, x Uncertain) (decimal.Decimal, error) {
+	if x.Sigma.IsZero() {
+		return decimal.Zero, nil
+	}
+	r := one.Sub(x.Value.Mul(x.Value))
+	if !r.IsPositive() {
+		return decimal.Zero, domainError("反正弦与反余弦在 ±1 处不可导，无法传播不确定度")
+	}
+	return w.div(x.Sigma, w.sqrt(r).Mul(c.angleScale(w.workPrecision()))), nil
+}
+
+// UAsin 计算测量值的反正弦，∂/∂x = 1/sqrt(1 - x²)
+func (c *Calculator) UAsin(x Uncertain) (Uncertain, error) {
+	w := c.widened()
+	v, err := w.Asin(x.Value.String())
+	if err != nil {
+		return Uncertain{}, err
+	}
+	d, err := c.arcSlope(w, x)
+	if err != nil {
+		return Uncertain{}, err
+	}
+	return c.propagate(v, d), nil
+}
+
+// UAcos 计算测量值的反余弦，∂/∂x = -1/sqrt(1 - x²)
+func (c *Calculator) UAcos(x Uncertain) (Uncertain, error) {
+	w := c.widened()
+	v, err := w.Acos(x.Value.String())
+	if err != nil {
+		return Uncertain{}, err
+	}
+	d, err := c.arcSlope(w, x)
+	if err != nil {
+		return Uncertain{}, err
+	}
+	return c.propagate(v, d), nil
+}
+
+// UAtan 计算测量值的反正切，∂/∂x = 1/(1 + x²)
+func (c *Calculator) UAtan(x Uncertain) (Uncertain, error) {
+	w := c.widened()
+	v, _ := w.Atan(x.Value.String())
+	d := one.Add(x.Value.Mul(x.Value)).Mul(c.angleScale(w.workPrecision()))
+	return c.propagate(v, w.div(x.Sigma, d)), nil
+}
+
+// ULn 计算测量值的自然对数，∂/∂x = 1/x
+func (c *Calculator) ULn(x Uncertain) (Uncertain, error) {
+	w := c.widened()
+	v, err := w.Ln(x.Value.String())
+	if err != nil {
+		return Uncertain{}, err
+	}
+	return c.propagate(v, w.div(x.Sigma, x.Value)), nil
+}
+
+// UExp 计算测量值的指数函数，∂/∂x = e^x
+func (c *Calculator) UExp(x Uncertain) (Uncertain, error) {
+	w := c.widened()
+	v, err := w.Exp(x.Value.String())
+	if err != nil {
+		return Uncertain{}, err
+	}
+	return c.propagate(v, v.Mul(x.Sigma)), nil
+}
+
+// FormatUncertain 将测量值格式化为 值 ± 不确定度，两者保留相同的小数位数
+func (c *Calculator) FormatUncertain(x Uncertain) string {
+	v := c.round(x.Value)
+	places := c.places(v)
+	return v.StringFixed(places) + " ± " + roundDec(x.Sigma, places, c.rounding).StringFixed(places)
+}
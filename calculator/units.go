@@ -0,0 +1,259 @@
+package calculator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Dimension 表示物理量的量纲，依次为长度、质量、时间、电流、温度、物质的量、发光强度的指数
+type Dimension [7]int
+
+// baseUnitNames 各基本量在国际单位制中的单位
+var baseUnitNames = [7]string{"m", "kg", "s", "A", "K", "mol", "cd"}
+
+// IsZero 判断是否为无量纲
+func (d Dimension) IsZero() bool {
+	return d == Dimension{}
+}
+
+// Mul 返回两个量相乘后的量纲
+func (d Dimension) Mul(e Dimension) Dimension {
+	for i := range d {
+		d[i] += e[i]
+	}
+	return d
+}
+
+// Div 返回两个量相除后的量纲
+func (d Dimension) Div(e Dimension) Dimension {
+	for i := range d {
+		d[i] -= e[i]
+	}
+	return d
+}
+
+// Pow 返回量的 n 次幂的量纲，各指数乘以 n 后不是整数时 ok 为 false
+func (d Dimension) Pow(n decimal.Decimal) (res Dimension, ok bool) {
+	for i := range d {
+		e := n.Mul(decimal.NewFromInt(int64(d[i])))
+		if !e.IsInteger() {
+			return res, false
+		}
+		res[i] = int(e.IntPart())
+	}
+	return res, true
+}
+
+// complexity 返回各指数绝对值之和，用于选择最简的单位写法
+func (d Dimension) complexity() int {
+	n := 0
+	for _, e := range d {
+		if e < 0 {
+			e = -e
+		}
+		n += e
+	}
+	return n
+}
+
+// covers 判断量纲 u 涉及的每个基本量的指数都与 d 相同
+func (d Dimension) covers(u Dimension) bool {
+	for i := range u {
+		if u[i] != 0 && d[i] != u[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// String 返回量纲在国际单位制中的单位写法，如 m/s^2、kg/(m*s)
+//
+// 能够用导出单位完整表示其中一部分基本量时优先使用导出单位，如 N、J、J/K。
+func (d Dimension) String() string {
+	derived, rest := "", d
+	for _, name := range derivedUnitNames {
+		u := units[name].dim
+		if !d.covers(u) {
+			continue
+		}
+		if r := d.Div(u); derived == "" || r.complexity() < rest.complexity() {
+			derived, rest = name, r
+		}
+	}
+
+	var num, den []string
+	if derived != "" {
+		num = append(num, derived)
+	}
+	for i, e := range rest {
+		switch {
+		case e == 1:
+			num = append(num, baseUnitNames[i])
+		case e > 1:
+			num = append(num, fmt.Sprintf("%s^%d", baseUnitNames[i], e))
+		case e == -1:
+			den = append(den, baseUnitNames[i])
+		case e < -1:
+			den = append(den, fmt.Sprintf("%s^%d", baseUnitNames[i], -e))
+		}
+	}
+
+	res := strings.Join(num, "*")
+	if len(num) == 0 {
+		res = "1"
+	}
+	switch len(den) {
+	case 0:
+	case 1:
+		res += "/" + den[0]
+	default:
+		res += "/(" + strings.Join(den, "*") + ")"
+	}
+	return res
+}
+
+// Unit 表示一个可以参与换算的单位
+//
+// 1 个该单位等于 num/den 个国际单位制单位，分子分母分开保存，km/h 等组合单位的换算也不会舍入。
+type Unit struct {
+	Name string
+	Dim  Dimension
+	num  decimal.Decimal
+	den  decimal.Decimal
+}
+
+// SIUnit 返回量纲 d 在国际单位制中的单位
+func SIUnit(d Dimension) Unit {
+	return Unit{Name: d.String(), Dim: d, num: one, den: one}
+}
+
+// Mul 返回两个单位之积，如 N*m
+func (u Unit) Mul(v Unit) Unit {
+	return Unit{Name: u.Name + "*" + v.Name, Dim: u.Dim.Mul(v.Dim), num: u.num.Mul(v.num), den: u.den.Mul(v.den)}
+}
+
+// Div 返回两个单位之商，如 m/s
+func (u Unit) Div(v Unit) Unit {
+	return Unit{Name: u.Name + "/" + v.Name, Dim: u.Dim.Div(v.Dim), num: u.num.Mul(v.den), den: u.den.Mul(v.num)}
+}
+
+// Pow 返回单位的整数次幂，如 s^2
+func (u Unit) Pow(n int) Unit {
+	dim, _ := u.Dim.Pow(decimal.NewFromInt(int64(n)))
+	name := u.Name
+	if strings.ContainsAny(name, "*/") {
+		name = "(" + name + ")"
+	}
+	res := Unit{Name: fmt.Sprintf("%s^%d", name, n), Dim: dim, num: one, den: one}
+	num, den := u.num, u.den
+	if n < 0 {
+		num, den, n = den, num, -n
+	}
+	for i := 0; i < n; i++ {
+		res.num, res.den = res.num.Mul(num), res.den.Mul(den)
+	}
+	return res
+}
+
+// unitDef 描述单位表中的一个单位，prefixed 表示是否可以加国际单位制词头
+type unitDef struct {
+	factor   decimal.Decimal
+	dim      Dimension
+	prefixed bool
+}
+
+// 常用量纲
+var (
+	lengthDim   = Dimension{1}
+	massDim     = Dimension{0, 1}
+	timeDim     = Dimension{0, 0, 1}
+	volumeDim   = Dimension{3}
+	speedDim    = Dimension{1, 0, -1}
+	forceDim    = Dimension{1, 1, -2}
+	energyDim   = Dimension{2, 1, -2}
+	powerDim    = Dimension{2, 1, -3}
+	pressureDim = Dimension{-1, 1, -2}
+	chargeDim   = Dimension{0, 0, 1, 1}
+)
+
+// unit 创建单位表中的一项，factor 为精确的十进制数
+func unit(factor string, dim Dimension, prefixed bool) unitDef {
+	return unitDef{factor: decimal.RequireFromString(factor), dim: dim, prefixed: prefixed}
+}
+
+// units 支持的单位，名称区分大小写
+var units = map[string]unitDef{
+	// 国际单位制基本单位，质量以克为基础加词头
+	"m":   unit("1", lengthDim, true),
+	"g":   unit("0.001", massDim, true),
+	"s":   unit("1", timeDim, true),
+	"A":   unit("1", Dimension{0, 0, 0, 1}, true),
+	"K":   unit("1", Dimension{0, 0, 0, 0, 1}, true),
+	"mol": unit("1", Dimension{0, 0, 0, 0, 0, 1}, true),
+	"cd":  unit("1", Dimension{0, 0, 0, 0, 0, 0, 1}, true),
+
+	// 导出单位
+	"N":  unit("1", forceDim, true),
+	"J":  unit("1", energyDim, true),
+	"W":  unit("1", powerDim, true),
+	"Pa": unit("1", pressureDim, true),
+	"Hz": unit("1", Dimension{0, 0, -1}, true),
+	"C":  unit("1", chargeDim, true),
+	"V":  unit("1", Dimension{2, 1, -3, -1}, true),
+	"L":  unit("0.001", volumeDim, true),
+	"Wh": unit("3600", energyDim, true),
+	"eV": unit("0.0000000000000000001602176634", energyDim, true),
+
+	// 其他常用单位
+	"min":  unit("60", timeDim, false),
+	"h":    unit("3600", timeDim, false),
+	"day":  unit("86400", timeDim, false),
+	"cal":  unit("4.184", energyDim, true),
+	"bar":  unit("100000", pressureDim, true),
+	"atm":  unit("101325", pressureDim, false),
+	"inch": unit("0.0254", lengthDim, false),
+	"ft":   unit("0.3048", lengthDim, false),
+	"yd":   unit("0.9144", lengthDim, false),
+	"mi":   unit("1609.344", lengthDim, false),
+	"lb":   unit("0.45359237", massDim, false),
+	"oz":   unit("0.028349523125", massDim, false),
+	"gal":  unit("0.003785411784", volumeDim, false),
+	"mph":  unit("0.44704", speedDim, false),
+	"lbf":  unit("4.4482216152605", forceDim, false),
+}
+
+// derivedUnitNames 格式化结果时可以使用的导出单位，按优先顺序排列
+var derivedUnitNames = []string{"N", "J", "W", "Pa", "C", "V"}
+
+// unitPrefixes 国际单位制词头及其对应的 10 的幂
+var unitPrefixes = map[string]int32{
+	"Y": 24, "Z": 21, "E": 18, "P": 15, "T": 12, "G": 9, "M": 6, "k": 3, "h": 2, "da": 1,
+	"d": -1, "c": -2, "m": -3, "μ": -6, "u": -6, "n": -9, "p": -12, "f": -15, "a": -18, "z": -21, "y": -24,
+}
+
+// LookupUnit 根据名称查找单位，支持 km、mg、μs 等带国际单位制词头的写法
+func LookupUnit(name string) (Unit, bool) {
+	if def, ok := units[name]; ok {
+		return Unit{Name: name, Dim: def.dim, num: def.factor, den: one}, true
+	}
+	for prefix, exp := range unitPrefixes {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		if def, ok := units[rest]; ok && def.prefixed {
+			return Unit{Name: name, Dim: def.dim, num: def.factor.Shift(exp), den: one}, true
+		}
+	}
+	return Unit{}, false
+}
+
+// ConvertUnit 将以 from 为单位的数值 value 换算为以 to 为单位的数值，量纲不同时返回 ErrUnitMismatch
+func (c *Calculator) ConvertUnit(value decimal.Decimal, from, to Unit) (decimal.Decimal, error) {
+	if from.Dim != to.Dim {
+		return decimal.Zero, fmt.Errorf("%w: 无法将 %s 换算为 %s", ErrUnitMismatch, from.Dim, to.Name)
+	}
+	return c.div(value.Mul(from.num).Mul(to.den), from.den.Mul(to.num)), nil
+}
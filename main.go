@@ -1,18 +1,33 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/to404hanga/calculator-mcp/calculator"
 	"github.com/to404hanga/calculator-mcp/mcp"
 )
 
 func main() {
+	ratesFile := flag.String("rates", os.Getenv("CALC_RATES_FILE"), "JSON or CSV exchange rate file used for currency conversion")
+	flag.Parse()
+
 	log.SetOutput(os.Stderr)
 	log.Printf("Starting calculator-mcp server...")
 
-	s := mcp.NewCalcServer()
+	var opts []mcp.ServerOption
+	if *ratesFile != "" {
+		log.Printf("Loading rates from %s...", *ratesFile)
+		rates, err := calculator.LoadRates(*ratesFile)
+		if err != nil {
+			log.Fatalf("Failed to load rates: %v", err)
+		}
+		opts = append(opts, mcp.WithRates(rates), mcp.WithRatesFile(*ratesFile))
+	}
+
+	s := mcp.NewCalcServer(opts...)
 
 	log.Printf("Starting stdio server...")
 	if err := server.ServeStdio(s); err != nil {
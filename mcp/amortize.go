@@ -0,0 +1,173 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shopspring/decimal"
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+const amortizeDescriptionEN = `Loan Amortization Schedule
+Builds the period-by-period repayment schedule of a fixed-payment loan:
+
+- principal: Amount borrowed, e.g., 200000; at most two decimal places (whole cents)
+- annual_rate: Nominal annual interest rate as a fraction, e.g., 0.05 for 5%
+- years: Loan term in years, which must give a whole number of payments
+- frequency: "monthly" (default), "annual", "semiannual", "quarterly", "biweekly" or "weekly";
+  the rate per period is annual_rate divided by the number of payments per year
+
+Each period lists the payment, the interest and principal parts, and the remaining balance.
+Payments and interest are rounded to cents with banker's rounding; the final payment is
+adjusted so that the balance ends at exactly 0.
+The schedule is returned as a markdown table followed by the same data as JSON.`
+
+var amortizeInputSchema = mcp.ToolInputSchema{
+	Type: "object",
+	Properties: map[string]any{
+		"principal": map[string]any{
+			"type":        "number",
+			"description": "The amount borrowed",
+		},
+		"annual_rate": map[string]any{
+			"type":        "number",
+			"description": "The nominal annual interest rate as a fraction, e.g., 0.05 for 5%",
+		},
+		"years": map[string]any{
+			"type":        "number",
+			"description": "The loan term in years",
+		},
+		"frequency": map[string]any{
+			"type":        "string",
+			"enum":        []string{"monthly", "annual", "semiannual", "quarterly", "biweekly", "weekly"},
+			"description": "The number of payments per year, defaults to monthly",
+		},
+	},
+	Required: []string{"principal", "annual_rate", "years"},
+}
+
+// amortizationRowJSON 还款计划中一期的 JSON 表示，金额为保留两位小数的字符串
+type amortizationRowJSON struct {
+	Period    int    `json:"period"`
+	Payment   string `json:"payment"`
+	Interest  string `json:"interest"`
+	Principal string `json:"principal"`
+	Balance   string `json:"balance"`
+}
+
+// amortizationJSON 还款计划的 JSON 表示
+type amortizationJSON struct {
+	Principal     string                `json:"principal"`
+	AnnualRate    string                `json:"annual_rate"`
+	Years         string                `json:"years"`
+	Frequency     string                `json:"frequency"`
+	Payment       string                `json:"payment"`
+	TotalPayment  string                `json:"total_payment"`
+	TotalInterest string                `json:"total_interest"`
+	Schedule      []amortizationRowJSON `json:"schedule"`
+}
+
+// cents 将金额格式化为保留两位小数的字符串
+func cents(d decimal.Decimal) string {
+	return d.StringFixed(2)
+}
+
+// numberArgument 读取数值类型的参数并转为字符串，也接受字符串形式的数字以保留全部精度
+func numberArgument(arguments map[string]any, name string) (string, error) {
+	return numberValue(arguments[name], name)
+}
+
+// numberValue 将 JSON 中的数值或字符串形式的数字转为字符串，name 用于错误信息
+func numberValue(value any, name string) (string, error) {
+	switch v := value.(type) {
+	case float64:
+		return decimal.NewFromFloat(v).String(), nil
+	case int:
+		return fmt.Sprint(v), nil
+	case string:
+		return v, nil
+	}
+	return "", fmt.Errorf("%s is required", name)
+}
+
+// formatAmortization 将还款计划格式化为 markdown 表格
+func formatAmortization(a *calculator.Amortization, frequency calculator.PaymentFrequency) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s payments of %s, total paid %s, total interest %s\n\n",
+		len(a.Schedule), frequency, cents(a.Payment), cents(a.TotalPayment), cents(a.TotalInterest))
+	b.WriteString("| Period | Payment | Interest | Principal | Balance |\n")
+	b.WriteString("|---:|---:|---:|---:|---:|\n")
+	for _, row := range a.Schedule {
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %s |\n",
+			row.Period, cents(row.Payment), cents(row.Interest), cents(row.Principal), cents(row.Balance))
+	}
+	return b.String()
+}
+
+func (s *CalcServer) handleAmortize(arguments map[string]any) (*mcp.CallToolResult, error) {
+	log.Printf("handleAmortize called with arguments: %+v", arguments)
+
+	var values [3]string
+	for i, name := range []string{"principal", "annual_rate", "years"} {
+		v, err := numberArgument(arguments, name)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	frequency := calculator.Monthly
+	if name, ok := arguments["frequency"].(string); ok {
+		f, err := calculator.ParsePaymentFrequency(name)
+		if err != nil {
+			return nil, err
+		}
+		frequency = f
+	}
+
+	calc := calculator.NewCalculator(2)
+	a, err := calc.Amortize(values[0], values[1], values[2], frequency)
+	if err != nil {
+		log.Printf("Error running amortize: %v", err)
+		return nil, err
+	}
+
+	data := amortizationJSON{
+		Principal:     values[0],
+		AnnualRate:    values[1],
+		Years:         values[2],
+		Frequency:     frequency.String(),
+		Payment:       cents(a.Payment),
+		TotalPayment:  cents(a.TotalPayment),
+		TotalInterest: cents(a.TotalInterest),
+		Schedule:      make([]amortizationRowJSON, len(a.Schedule)),
+	}
+	for i, row := range a.Schedule {
+		data.Schedule[i] = amortizationRowJSON{
+			Period:    row.Period,
+			Payment:   cents(row.Payment),
+			Interest:  cents(row.Interest),
+			Principal: cents(row.Principal),
+			Balance:   cents(row.Balance),
+		}
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []any{
+			map[string]any{
+				"type": "text",
+				"text": formatAmortization(a, frequency),
+			},
+			map[string]any{
+				"type": "text",
+				"text": string(encoded),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,217 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shopspring/decimal"
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+const linsolveDescriptionEN = `Linear System Solver
+Solves the linear system A·x = b and computes the eigenvalues and eigenvectors of A:
+
+- matrix: Coefficient matrix A as an array of rows, e.g., [[2, 1], [1, 3]];
+  elements may be numbers or strings such as "0.1" to keep every digit
+- rhs: Right-hand side b as an array with one element per row of A, e.g., [3, 5]
+- precision: Number of decimal places in the results, from 0 to 75, defaults to 10
+
+The system is solved by exact rational elimination, so only the solution is rounded.
+A status of "solved" comes with the solution; otherwise the status reports a system that is
+"underdetermined" (infinitely many solutions) or "inconsistent" (no solution), including when
+A is square but not invertible.
+
+For a square A, the eigenvalues and unit eigenvectors are computed by shifted QR iteration at the
+requested precision, sorted from largest to smallest; each eigenvector's largest component is positive.
+Only real eigenvalues of matrices up to 20×20 are supported. A matrix that is not diagonalizable,
+such as [[1, 1], [0, 1]], has too few independent eigenvectors; only its eigenvalues are returned
+and eigen_error says why the eigenvectors are missing.
+
+The results are returned as markdown tables followed by the same data as JSON.`
+
+var linsolveInputSchema = mcp.ToolInputSchema{
+	Type: "object",
+	Properties: map[string]any{
+		"matrix": map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "array"},
+			"description": "The coefficient matrix as an array of rows",
+		},
+		"rhs": map[string]any{
+			"type":        "array",
+			"description": "The right-hand side, one element per row of the matrix",
+		},
+		"precision": map[string]any{
+			"type":        "number",
+			"description": "The number of decimal places in the results, from 0 to 75, defaults to 10",
+		},
+	},
+	Required: []string{"matrix", "rhs"},
+}
+
+// eigenJSON 特征值与特征向量的 JSON 表示，vectors[i] 为 values[i] 的特征向量
+type eigenJSON struct {
+	Values  []string   `json:"values"`
+	Vectors [][]string `json:"vectors,omitempty"`
+}
+
+// linsolveJSON 线性方程组求解结果的 JSON 表示，数值均为字符串以保留全部精度
+type linsolveJSON struct {
+	Status     string     `json:"status"`
+	Solution   []string   `json:"solution,omitempty"`
+	Eigen      *eigenJSON `json:"eigen,omitempty"`
+	EigenError string     `json:"eigen_error,omitempty"`
+}
+
+// solveStatus 方程组无唯一解时的错误与对应的状态和说明
+type solveStatus struct {
+	err     error
+	status  string
+	message string
+}
+
+// solveStatuses 按 calculator.Solve 返回的错误列出的求解状态
+var solveStatuses = []solveStatus{
+	{calculator.ErrUnderdetermined, "underdetermined", "The system is underdetermined: it has infinitely many solutions."},
+	{calculator.ErrInconsistent, "inconsistent", "The system is inconsistent: it has no solution."},
+}
+
+// matrixArgument 读取以行数组表示的矩阵参数
+func matrixArgument(arguments map[string]any, name string) (calculator.Matrix, error) {
+	rows, ok := arguments[name].([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s is required", name)
+	}
+	values := make([][]decimal.Decimal, len(rows))
+	for i, row := range rows {
+		cells, ok := row.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%s[%d] must be an array", name, i)
+		}
+		values[i] = make([]decimal.Decimal, len(cells))
+		for j, cell := range cells {
+			v, err := numberValue(cell, fmt.Sprintf("%s[%d][%d]", name, i, j))
+			if err != nil {
+				return nil, err
+			}
+			if values[i][j], err = calculator.ParseNumber(v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return calculator.NewMatrix(values)
+}
+
+func (s *CalcServer) handleLinsolve(arguments map[string]any) (*mcp.CallToolResult, error) {
+	log.Printf("handleLinsolve called with arguments: %+v", arguments)
+
+	a, err := matrixArgument(arguments, "matrix")
+	if err != nil {
+		return nil, err
+	}
+	rhs, ok := arguments["rhs"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("rhs is required")
+	}
+	b := make([]decimal.Decimal, len(rhs))
+	for i, v := range rhs {
+		str, err := numberValue(v, fmt.Sprintf("rhs[%d]", i))
+		if err != nil {
+			return nil, err
+		}
+		if b[i], err = calculator.ParseNumber(str); err != nil {
+			return nil, err
+		}
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("rhs is required")
+	}
+
+	precision, err := precisionArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
+	calc := calculator.NewCalculator(precision)
+	var text strings.Builder
+	data := linsolveJSON{Status: "solved"}
+
+	x, err := calc.Solve(a, calculator.Matrix{b})
+	if err != nil {
+		i := slices.IndexFunc(solveStatuses, func(st solveStatus) bool { return errors.Is(err, st.err) })
+		if i < 0 {
+			log.Printf("Error running linsolve: %v", err)
+			return nil, err
+		}
+		data.Status = solveStatuses[i].status
+		text.WriteString(solveStatuses[i].message + "\n")
+	} else {
+		fmt.Fprintf(&text, "Solution of the %d×%d system:\n\n", a.Rows(), a.Cols())
+		text.WriteString("| Unknown | Value |\n")
+		text.WriteString("|---|---:|\n")
+		for i, row := range x {
+			fmt.Fprintf(&text, "| x%d | %s |\n", i+1, calc.Format(row[0]))
+			data.Solution = append(data.Solution, calc.Format(row[0]))
+		}
+	}
+
+	if a.Rows() == a.Cols() {
+		text.WriteString("\n")
+		values, vectors, err := calc.Eigen(a)
+		switch {
+		case errors.Is(err, calculator.ErrDefective):
+			// 特征值仍然有效，只省略线性相关的特征向量
+			data.EigenError = err.Error()
+			data.Eigen = &eigenJSON{}
+			fmt.Fprintf(&text, "Eigenvectors could not be computed: %v\n\n", err)
+			text.WriteString("Eigenvalues of the coefficient matrix:\n\n")
+			text.WriteString("| # | Eigenvalue |\n")
+			text.WriteString("|---:|---:|\n")
+			for i, value := range values {
+				fmt.Fprintf(&text, "| %d | %s |\n", i+1, calc.Format(value))
+				data.Eigen.Values = append(data.Eigen.Values, calc.Format(value))
+			}
+		case err != nil:
+			data.EigenError = err.Error()
+			fmt.Fprintf(&text, "Eigenvalues could not be computed: %v\n", err)
+		default:
+			data.Eigen = &eigenJSON{}
+			text.WriteString("Eigenvalues and eigenvectors of the coefficient matrix:\n\n")
+			text.WriteString("| # | Eigenvalue | Eigenvector |\n")
+			text.WriteString("|---:|---:|---|\n")
+			for i, value := range values {
+				vector := make([]decimal.Decimal, len(vectors))
+				strs := make([]string, len(vectors))
+				for j, row := range vectors {
+					vector[j] = row[i]
+					strs[j] = calc.Format(row[i])
+				}
+				fmt.Fprintf(&text, "| %d | %s | %s |\n", i+1, calc.Format(value), calc.FormatMatrix(calculator.Matrix{vector}))
+				data.Eigen.Values = append(data.Eigen.Values, calc.Format(value))
+				data.Eigen.Vectors = append(data.Eigen.Vectors, strs)
+			}
+		}
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []any{
+			map[string]any{
+				"type": "text",
+				"text": text.String(),
+			},
+			map[string]any{
+				"type": "text",
+				"text": string(encoded),
+			},
+		},
+	}, nil
+}
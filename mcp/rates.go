@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shopspring/decimal"
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+const ratesDescriptionEN = `Currency Rate Table
+Lists or changes the exchange rates used for currency amounts in the calc tool:
+
+- action "list" (default): Returns the current table
+- action "update": Merges the given rates into the table
+  - rates: Object mapping currency codes to rates, e.g., {"EUR": 0.92, "JPY": "148.2"};
+    each rate is the number of units of that currency per 1 unit of base
+  - base: Currency the rates are quoted against, defaults to the table's base currency;
+    it must already be in the table unless the table is empty; base itself may only be quoted at 1,
+    and a rate for the table's base currency is ignored because that currency stays at 1
+  - as_of: Date of the rates, defaults to today (UTC)
+- action "load": Reloads the table from the rate file given at server start
+  (-rates or CALC_RATES_FILE), discarding updates; other files cannot be loaded
+
+The table is returned as a markdown table followed by the same data as JSON.`
+
+var ratesInputSchema = mcp.ToolInputSchema{
+	Type: "object",
+	Properties: map[string]any{
+		"action": map[string]any{
+			"type":        "string",
+			"enum":        []string{"list", "update", "load"},
+			"description": "Whether to list, update or reload the rates from the server's rate file, defaults to list",
+		},
+		"rates": map[string]any{
+			"type":        "object",
+			"description": "Currency codes mapped to the number of units per 1 unit of base, for update",
+		},
+		"base": map[string]any{
+			"type":        "string",
+			"description": "The currency the rates are quoted against, for update",
+		},
+		"as_of": map[string]any{
+			"type":        "string",
+			"description": "The date of the rates, for update, defaults to today",
+		},
+	},
+}
+
+// ratesJSON 汇率表的 JSON 表示，汇率为字符串以保留全部精度
+type ratesJSON struct {
+	Base      string            `json:"base"`
+	RatesAsOf string            `json:"rates_as_of"`
+	Rates     map[string]string `json:"rates"`
+}
+
+// formatRates 将汇率表格式化为 markdown 表格
+func formatRates(rates *calculator.Rates) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Rates as of %s, per 1 %s\n\n", rates.AsOf, rates.Base)
+	b.WriteString("| Currency | Rate |\n")
+	b.WriteString("|---|---:|\n")
+	for _, code := range rates.Currencies() {
+		rate, _ := rates.Rate(code)
+		fmt.Fprintf(&b, "| %s | %s |\n", code, rate)
+	}
+	return b.String()
+}
+
+// updateRates 将参数中的汇率合并到汇率表中
+func (s *CalcServer) updateRates(arguments map[string]any) error {
+	values, ok := arguments["rates"].(map[string]any)
+	if !ok || len(values) == 0 {
+		return fmt.Errorf("rates is required")
+	}
+	rates := make(map[string]decimal.Decimal, len(values))
+	for code := range values {
+		v, err := numberArgument(values, code)
+		if err != nil {
+			return err
+		}
+		rate, err := calculator.ParseNumber(v)
+		if err != nil {
+			return fmt.Errorf("invalid rate for %s: %w", code, err)
+		}
+		rates[strings.ToUpper(code)] = rate
+	}
+	asOf, _ := arguments["as_of"].(string)
+	if asOf == "" {
+		asOf = time.Now().UTC().Format("2006-01-02")
+	}
+
+	s.ratesMu.Lock()
+	defer s.ratesMu.Unlock()
+	base, _ := arguments["base"].(string)
+	base = strings.ToUpper(base)
+	if base == "" {
+		if s.rates == nil {
+			return fmt.Errorf("base is required")
+		}
+		base = s.rates.Base
+	}
+	updated, err := s.rates.Update(base, asOf, rates)
+	if err != nil {
+		return err
+	}
+	s.rates = updated
+	return nil
+}
+
+func (s *CalcServer) handleRates(arguments map[string]any) (*mcp.CallToolResult, error) {
+	log.Printf("handleRates called with arguments: %+v", arguments)
+
+	action, _ := arguments["action"].(string)
+	switch action {
+	case "", "list":
+	case "update":
+		if err := s.updateRates(arguments); err != nil {
+			log.Printf("Error updating rates: %v", err)
+			return nil, err
+		}
+	case "load":
+		if s.ratesFile == "" {
+			return nil, fmt.Errorf("no rate file configured, start the server with -rates or CALC_RATES_FILE")
+		}
+		rates, err := calculator.LoadRates(s.ratesFile)
+		if err != nil {
+			log.Printf("Error loading rates: %v", err)
+			return nil, err
+		}
+		s.ratesMu.Lock()
+		s.rates = rates
+		s.ratesMu.Unlock()
+	default:
+		return nil, fmt.Errorf("unknown action: %s", action)
+	}
+
+	rates := s.currentRates()
+	if rates == nil {
+		return nil, fmt.Errorf("no rate table loaded, start the server with -rates or use action \"update\"")
+	}
+	data := ratesJSON{Base: rates.Base, RatesAsOf: rates.AsOf, Rates: map[string]string{}}
+	for _, code := range rates.Currencies() {
+		rate, _ := rates.Rate(code)
+		data.Rates[code] = rate.String()
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []any{
+			map[string]any{
+				"type": "text",
+				"text": formatRates(rates),
+			},
+			map[string]any{
+				"type": "text",
+				"text": string(encoded),
+			},
+		},
+	}, nil
+}
@@ -1,8 +1,11 @@
 package mcp
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -15,8 +18,10 @@ This is a scientific calculator supporting high-precision calculations with the
 
 1. Basic Operations
    - Addition(+), Subtraction(-), Multiplication(*), Division(/)
+   - A leading - or + is a sign that binds tighter than * and / but looser than ^, e.g., 2 * -3 = -6, 2 ^ -1 = 0.5, -2 ^ 2 = -4
    - Supports nested parentheses, e.g., (1 + 2) * 3
    - Supports arbitrary precision decimal calculations
+   - Numbers may use scientific notation, e.g., 1e-30, 2.5E+3
 
 2. Mathematical Constants
    - PI (π): Mathematical constant pi
@@ -24,10 +29,13 @@ This is a scientific calculator supporting high-precision calculations with the
 
 3. Mathematical Functions
    - sqrt(x): Square root calculation
-   - pow(x, y): Exponentiation, e.g., 2 ^ 3
+   - pow(x, y): Exponentiation, e.g., 2 ^ 3; ^ binds tighter than * and / and groups from the right, e.g., 2 ^ 3 ^ 2 = 512
    - log(x,b): Logarithm with base b, e.g., log(8,2) = 3
    - ln(x): Natural logarithm (base e), e.g., ln(e) = 1
    - lg(x): Common logarithm (base 10), e.g., lg(100) = 2
+   - exp(x): Exponential function (base e), e.g., exp(1) = e
+   - Approximate results such as exp(x), sinh(x) or 2 ^ 0.5 may have at most 10000 integer digits;
+     larger results are reported as an overflow, e.g., exp(1000000)
    
 4. Trigonometric Functions
    - sin(x): Sine function
@@ -36,11 +44,179 @@ This is a scientific calculator supporting high-precision calculations with the
    - asin(x): Arcsine function, input range [-1,1]
    - acos(x): Arccosine function, input range [-1,1]
    - atan(x): Arctangent function
+   - angle_unit "rad" (default), "deg" or "grad" sets the unit of trigonometric inputs
+     and of inverse function results, e.g., sin(30) = 0.5 and asin(0.5) = 30 in "deg"
+   - deg(x), rad(x), grad(x): Convert an angle given in degrees, radians or gradians
+     to the selected unit, e.g., sin(deg(30)) = 0.5 in any unit
+   - Degree-minute-second literals such as 30°15'10" are accepted
 
-5. Precision Control
+5. Hyperbolic Functions
+   - sinh(x), cosh(x), tanh(x): Hyperbolic sine, cosine and tangent
+   - coth(x), sech(x), csch(x): Hyperbolic cotangent, secant and cosecant, coth(0) and csch(0) are undefined
+   - asinh(x): Inverse hyperbolic sine
+   - acosh(x): Inverse hyperbolic cosine, real for x >= 1
+   - atanh(x): Inverse hyperbolic tangent, real for -1 < x < 1
+
+6. Integer Functions
+   - fact(n) or n!: Factorial, e.g., 5! = 120
+   - dfact(n) or n!!: Double factorial n·(n-2)·(n-4)·…, e.g., 5!! = 15; write (5!)! or 5! ! for the factorial of 5!
+   - nCr(n, r), nPr(n, r): Combinations and permutations
+   - gcd(a, b, ...), lcm(a, b, ...): Greatest common divisor and least common multiple
+   - mod(a, b): Remainder with the sign of b, e.g., mod(-7, 3) = 2
+   - Computed exactly with big integers; every digit is returned regardless of precision,
+     e.g., fact(500) returns all 1135 digits
+   - Arguments must be integers; fact, dfact, nCr and nPr also require non-negative arguments
+   - isprime(n): 1 if n is prime, otherwise 0 (Miller-Rabin and Baillie-PSW tests)
+   - nextprime(n): Smallest prime greater than n
+   - factor(n): Prime factorization by trial division and Pollard rho, e.g., factor(168) = 2^3 * 3 * 7;
+     negative numbers start with -1, and the result behaves as n in further arithmetic
+   - totient(n): Euler's totient function, e.g., totient(36) = 12
+   - powmod(a, b, m): a^b mod m in [0, m), a negative b uses the modular inverse of a
+   - modinv(a, m): Modular inverse of a modulo m, e.g., modinv(3, 7) = 5
+   - isprime, nextprime, factor and totient accept numbers of up to 1000 digits;
+     factor reports an overflow error when a large factor cannot be found within its step limit
+
+7. Special Functions
+   - gamma(x): Gamma function, e.g., gamma(5) = 24, gamma(0.5) = sqrt(PI); undefined at 0, -1, -2, ...
+   - lgamma(x): Natural logarithm of |gamma(x)|, usable where gamma(x) itself would overflow;
+     gamma and beta results may have at most 2500 integer digits
+   - beta(a, b): Beta function gamma(a) * gamma(b) / gamma(a + b)
+   - digamma(x): Logarithmic derivative of the gamma function, e.g., digamma(1) = -0.5772156649
+   - erf(x), erfc(x): Error function and complementary error function; erfc keeps full precision for large x
+   - j0(x), j1(x): Bessel functions of the first kind of order 0 and 1
+   - y0(x), y1(x): Bessel functions of the second kind of order 0 and 1, defined for x > 0
+   - Computed to the requested precision
+
+8. Statistics
+   - Take any number of arguments, e.g., mean(1, 2, 3, 4) = 2.5
+   - sum(...), prod(...), min(...), max(...)
+   - mean(...), median(...), geomean(...): Arithmetic mean, median and geometric mean (positive data only)
+   - mode(...): Most frequent value, the smallest one on ties
+   - variance(...), stddev(...): Population variance and standard deviation, e.g., stddev(2, 4, 4, 4, 5, 5, 7, 9) = 2
+   - variance_s(...), stddev_s(...): Sample variance and standard deviation, at least two values
+   - percentile(p, ...): p-th percentile (0 to 100) with linear interpolation, as Excel PERCENTILE.INC
+   - Computed in decimal; sums, means, variances and percentiles are exact before the final rounding
+
+9. Probability Distributions
+   - normpdf(x, μ, σ), normcdf(x, μ, σ): Normal density and P(X <= x); μ and σ default to 0 and 1
+   - norminv(p, μ, σ): Normal quantile for 0 < p < 1, e.g., norminv(0.975) = 1.9599639845
+   - binompdf(k, n, p), binomcdf(k, n, p): Binomial P(X = k) and P(X <= k) for n trials with success probability p
+   - poissonpdf(k, λ), poissoncdf(k, λ): Poisson P(X = k) and P(X <= k) with mean λ
+   - tcdf(t, ν): Student's t distribution P(T <= t) with ν degrees of freedom
+   - chi2cdf(x, k): Chi-squared distribution P(X <= x) with k degrees of freedom
+   - expcdf(x, λ): Exponential distribution P(X <= x) with rate λ
+   - Computed to the requested precision, so tail probabilities such as 1 - normcdf(6) keep their digits
+     at a high enough precision; with precision_mode "significant" use normcdf(-6) for upper tails
+
+10. Financial Functions
+   - Follow spreadsheet sign conventions: money paid out is negative, money received is positive;
+     type 0 (default) means payments at the end of each period, 1 at the beginning
+   - pmt(rate, nper, pv, fv, type): Payment per period, e.g., pmt(0.005, 360, 200000) = -1199.1010503055
+   - fv(rate, nper, pmt, pv, type), pv(rate, nper, pmt, fv, type): Future and present value
+   - nper(rate, pmt, pv, fv, type): Number of periods
+   - rate(nper, pmt, pv, fv, type, guess): Interest rate per period, solved from guess (default 0.1)
+   - npv(rate, ...): Net present value of cash flows at the end of periods 1, 2, ...
+   - irr(...): Internal rate of return of cash flows at periods 0, 1, ...; needs both signs
+   - fv, pv and type may be omitted and default to 0
+   - Arguments are evaluated at a higher working precision and only the result is rounded,
+     e.g., pmt(0.05 / 12, 360, 200000) = -1073.64 at precision 2
+   - Computed in decimal; rate and irr report an error when the solver does not converge
+
+11. Precision Control
    - Supports custom calculation precision
    - Default precision of 10 decimal places
-   - Maximum precision up to 75 decimal places
+   - Maximum precision up to 75 decimal places; larger or negative precision values are rejected
+   - precision_mode "significant" keeps N significant digits instead of N decimal places,
+     e.g., 1e-21 * 3 gives 0.0000000000000000000030000 at precision 5; precision 0 counts as 1 digit
+
+12. Rounding Modes
+   - half_up (default), half_even (banker's rounding), half_down
+   - down (truncation), ceiling, floor, odd (round to odd)
+   - Applied to every operation, including division, sqrt and power
+
+13. Complex Numbers
+   - i: Imaginary unit, e.g., 3 + 4i, 2i * 3
+   - Arithmetic, powers, sqrt, exp, ln, log, trigonometric and hyperbolic functions accept complex arguments
+   - abs(z): Modulus, arg(z): Argument in (-π, π], expressed in the selected angle unit
+   - conj(z): Conjugate, re(z): Real part, im(z): Imaginary part
+   - polar(r, θ): Complex number from polar form r·e^(iθ), θ in the selected angle unit
+   - Complex results are shown as a + bi, e.g., sqrt(-4) = 0 + 2i
+
+14. Exact Mode
+   - exact: true keeps + - * / and integer powers exact as fractions
+   - Results are shown as a reduced fraction and its decimal value, e.g., 1/3 + 1/4 = 7/12 ≈ 0.5833333333
+   - Integer results are shown without decimals, e.g., 1/3 * 3 = 1
+   - Other functions and constants fall back to decimal calculation
+
+15. Units
+   - A unit written after a number attaches to it, e.g., 5 km, 9.81 m/s^2, 3 kg*m^2
+   - SI units m, g, s, A, K, mol, cd and derived units N, J, W, Pa, Hz, C, V, L, Wh, eV, cal, bar
+     accept SI prefixes from y (10^-24) to Y (10^24), e.g., km, mg, μs (or us), kWh, hPa
+   - Other units: min, h, day, atm, inch, ft, yd, mi, lb, oz, gal, mph, lbf
+   - expr in unit converts the result, e.g., 5 km + 300 m in mi = 3.2932673189 mi
+   - Adding or subtracting quantities requires the same dimension; 0 may be combined with any unit
+   - *, /, ^ and sqrt combine dimensions, e.g., 9.81 m/s^2 * 70 kg = 686.7 N, sqrt(16 m^2) = 4 m
+   - Results keep the unit of a single quantity and otherwise use SI units,
+     simplified to N, J, W, Pa, C or V where possible, e.g., 2 N * 3 m = 6 J
+   - Other functions do not accept quantities with units
+
+16. Currencies
+   - A currency code from the rate table written after a number makes an amount, e.g., 100 USD, 50 EUR
+   - expr in CODE converts the result, e.g., 100 USD + 50 EUR in CNY
+   - Adding amounts in different currencies converts the right one into the left one's currency
+   - Amounts may be multiplied or divided by numbers; dividing two amounts gives their ratio
+   - Amounts are kept in decimal and rounded once, on the final result, to the currency's minor unit, e.g., JPY 0, USD 2, KWD 3 decimals
+   - Rates come from the file given at server start and the rates tool;
+     results in a currency also return the date of the rates as rates_as_of
+
+17. Programmer Mode
+   - Integer literals with a base prefix: 0xFF (hex), 0b1010 (binary), 0o17 (octal); _ may separate digits, e.g., 0xFFFF_FFFF
+   - Bitwise operators on big integers: a & b, a | b, a xor b, ~a, a << n, a >> n
+   - Negative numbers behave as infinite two's complement, e.g., ~0 = -1, -256 >> 4 = -16
+   - Precedence from lowest to highest: |, xor, &, << and >>, then + and -, e.g., 1 << 2 + 1 = 8
+   - base "hex", "bin" or "oct" shows integer results as 0xff, 0b1010 or 0o17;
+     results that are not integers are shown in decimal
+
+18. Measurements and Intervals
+   - v±u enters a measured value, e.g., 3.00±0.05; interval(lo, hi) enters an interval, e.g., interval(2.9, 3.1)
+   - +, -, *, /, ^, sqrt, sin, cos, tan, asin, acos, atan, ln and exp propagate the uncertainty
+   - A measured value cannot carry a unit, so (3±0.1) m is an error
+   - uncertainty "interval" (default) computes guaranteed bounds: every result is an interval rounded
+     outward, so it always contains the true range, e.g., sin([0, 3]) = 0.5 ± 0.5
+   - uncertainty "gaussian" uses first-order error propagation for independent errors,
+     e.g., (3±0.1) * (2±0.2) = 6 ± 0.632; interval(lo, hi) is read as its midpoint ± half its width
+   - Results are shown as value ± uncertainty; for intervals the midpoint ± the half width
+   - Dividing by an interval that contains 0 is an error, as is tan over an interval containing a pole
+
+19. Matrices and Vectors
+   - [a, b, c; d, e, f] enters a matrix: commas separate elements, semicolons separate rows
+   - [1, 2, 3] is a row vector and [1; 2; 3] a column vector
+   - + and - work elementwise on matrices of the same shape; a matrix times or divided by a number scales every element
+   - * between two matrices is the matrix product, e.g., [1, 2; 3, 4] * [5; 6] = [17; 39];
+     matrices of the same shape that cannot be multiplied that way are multiplied elementwise, e.g., [1, 2, 3] * [1, 2, 3] = [1, 4, 9]
+   - .* always multiplies elementwise, e.g., [1, 2; 3, 4] .* [1, 2; 3, 4] = [1, 4; 9, 16]
+   - A^n raises a square matrix to an integer power; A^-1 is its inverse
+   - det(A), inv(A), transpose(A), trace(A), rank(A)
+   - dot(u, v), cross(u, v) for 3-vectors (2-vectors lie in the xy-plane, cross([1, 2], [3, 4]) = [0, 0, -2]), norm(v) (Euclidean; Frobenius for matrices)
+   - det, inv and rank use exact rational elimination, so only the final result is rounded
+   - Matrix results are rendered as markdown tables
+   - Linear systems and eigenvalues are solved by the linsolve tool
+
+20. Symbolic Differentiation
+   - diff(expr, x) returns the derivative of expr with respect to the variable x as an expression,
+     e.g., diff(x^2*sin(x), x) = 2*x*sin(x) + x^2*cos(x)
+   - diff(expr, x, at) evaluates the derivative at a point, exactly where possible, e.g., diff(x^3, x, 0.5) = 3/4
+   - Supports + - * / ^, sqrt, ln, lg, log, exp and the trigonometric, inverse trigonometric and hyperbolic functions
+   - Trigonometric derivatives follow angle_unit, e.g., diff(sin(x), x) = PI/180*cos(x) in degrees
+   - Derivatives are printed with the same precedence as the input, where ^ binds tighter than * and / and groups from the right,
+     so they can be pasted back into an expression; a negative result is written with a leading minus, e.g., diff(cos(x), x) = -sin(x)
+
+21. Symbolic Simplification
+   - simplify(expr) returns expr simplified; every identifier in it other than PI, E and function names is a variable
+   - Folds numeric constants exactly and collects like terms, e.g., simplify(x + x) = 2*x, simplify(x*x) = x^2
+   - Applies identities such as x*1 = x, x^0 = 1, ln(E^x) = x and sin(x)^2 + cos(x)^2 = 1
+   - Orders terms by descending degree with the constant last, e.g., simplify(1 + x + x^2) = x^2 + x + 1
+   - simplify(diff(expr, x)) simplifies a derivative; the simplify tool does the same for a bare expression
 
 Usage Examples:
 1. Basic operation: 1 + 2 * 3
@@ -52,13 +228,32 @@ Usage Examples:
 7. Logarithm: log(1000,10) = 3
 8. Natural logarithm: ln(E^2) = 2
 9. Common logarithm: lg(1000) = 3
+10. Complex numbers: E ^ (i * PI) = -1, abs(3 + 4i) = 5
+11. Hyperbolic functions: cosh(1)^2 - sinh(1)^2 = 1
+12. Angles: sin(30°) = 0.5, acos(0) = 90 with angle_unit "deg"
+13. Integers: nCr(1000, 500), gcd(12, 18) = 6, factor(2^64 + 1) = 274177 * 67280421310721
+14. Special functions: gamma(0.5) ^ 2 = PI, beta(2, 3) = 1/12, erfc(10)
+15. Statistics: stddev(2, 4, 4, 4, 5, 5, 7, 9) = 2, percentile(90, 15, 20, 35, 40, 50) = 46
+16. Distributions: 1 - normcdf(6) at precision 30, binomcdf(3, 10, 0.5) = 0.171875
+17. Finance: pmt(0.05 / 12, 360, 200000) = -1073.6432460242, irr(-70000, 12000, 15000, 18000, 21000, 26000) = 0.086630948
+18. Units: 5 km + 300 m in mi, 100 km/h in m/s, 9.81 m/s^2 * 70 kg = 686.7 N
+19. Currencies: 100 USD + 50 EUR in CNY, 1000 JPY / 3 = 333 JPY
+20. Programmer mode: 0xF0 | 0x0F = 255, (1 << 64) - 1 = 0xffffffffffffffff with base "hex"
+21. Measurements: (3±0.1) / (2±0.2), sqrt([4, 9]) = 2.5 ± 0.5, (3±0.1) * (2±0.2) with uncertainty "gaussian"
+22. Matrices: det([1, 2; 3, 4]) = -2, inv([1, 2; 3, 4]), dot([1, 2, 3], [4, 5, 6]) = 32
+23. Derivatives: diff(x^2*sin(x), x) = 2*x*sin(x) + x^2*cos(x), diff(1/x, x, 3) = -1/9
+24. Simplification: simplify(sin(x)^2 + cos(x)^2 + x + x) = 2*x + 1, simplify(diff(x/(x + 1), x)) = 1/(x + 1)^2
 
 Important Notes:
 1. Division by zero is not allowed
-2. Square root of negative numbers is not allowed
-3. Input values for inverse trigonometric functions must be within valid range
-4. Logarithm input and base must be positive, base cannot be 1
-5. Natural logarithm input must be positive`
+2. Square roots, logarithms and non-integer powers of negative numbers return complex results
+3. asin(x) and acos(x) return complex results outside [-1,1], as do acosh(x) below 1 and atanh(x) outside (-1,1)
+4. Logarithm input cannot be 0 and base cannot be 0 or 1
+5. arg(0) is undefined
+6. Adding quantities of different dimensions, such as 5 m + 3 s, is an error; inches are written inch because in means conversion
+7. ^ is exponentiation; bitwise exclusive or is written xor
+8. Inverting a singular matrix is an error, as is combining matrices whose dimensions do not match
+9. A derivative without a point is an expression and cannot be combined with numbers; functions such as abs cannot be differentiated`
 
 var calcInputSchema = mcp.ToolInputSchema{
 	Type: "object",
@@ -69,7 +264,36 @@ var calcInputSchema = mcp.ToolInputSchema{
 		},
 		"precision": map[string]any{
 			"type":        "number",
-			"description": "The precision of the result",
+			"description": "The precision of the result, from 0 to 75, defaults to 10",
+		},
+		"precision_mode": map[string]any{
+			"type":        "string",
+			"enum":        []string{"decimal", "significant"},
+			"description": "Whether precision counts decimal places or significant digits, defaults to decimal",
+		},
+		"rounding": map[string]any{
+			"type":        "string",
+			"enum":        []string{"half_up", "half_even", "half_down", "down", "ceiling", "floor", "odd"},
+			"description": "The rounding mode applied to every operation, defaults to half_up",
+		},
+		"angle_unit": map[string]any{
+			"type":        "string",
+			"enum":        []string{"rad", "deg", "grad"},
+			"description": "The angle unit used by trigonometric functions and returned by inverse functions, defaults to rad",
+		},
+		"exact": map[string]any{
+			"type":        "boolean",
+			"description": "Keep + - * / and integer powers exact as fractions, defaults to false",
+		},
+		"base": map[string]any{
+			"type":        "string",
+			"enum":        []string{"dec", "hex", "bin", "oct"},
+			"description": "The base integer results are shown in, defaults to dec",
+		},
+		"uncertainty": map[string]any{
+			"type":        "string",
+			"enum":        []string{"interval", "gaussian"},
+			"description": "How measured values such as 3.00±0.05 propagate: guaranteed interval bounds or first-order Gaussian errors, defaults to interval",
 		},
 	},
 	Required: []string{"expression"},
@@ -77,19 +301,63 @@ var calcInputSchema = mcp.ToolInputSchema{
 
 type CalcServer struct {
 	server *server.MCPServer
+
+	ratesMu   sync.RWMutex
+	rates     *calculator.Rates // 货币换算使用的汇率表，为 nil 时不支持货币
+	ratesFile string            // 启动时指定的汇率表文件，rates 工具只能从该文件重新加载
+}
+
+// ServerOption 用于在创建服务器时调整配置
+type ServerOption func(*CalcServer)
+
+// WithRates 设置服务器启动时使用的汇率表
+func WithRates(rates *calculator.Rates) ServerOption {
+	return func(s *CalcServer) {
+		s.rates = rates
+	}
+}
+
+// WithRatesFile 设置启动时指定的汇率表文件，rates 工具的 load 操作从该文件重新加载
+func WithRatesFile(path string) ServerOption {
+	return func(s *CalcServer) {
+		s.ratesFile = path
+	}
 }
 
-func (s *CalcServer) runCalc(expression string, precision int32) (result string, err error) {
+// currentRates 返回当前的汇率表
+func (s *CalcServer) currentRates() *calculator.Rates {
+	s.ratesMu.RLock()
+	defer s.ratesMu.RUnlock()
+	return s.rates
+}
+
+// evaluate 使用当前的汇率表解析并计算表达式，返回结果及计算所用的计算器
+func (s *CalcServer) evaluate(expression string, precision int32, opts ...calculator.Option) (value ast.Value, calc *calculator.Calculator, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			result, err = "Internal Error", r.(error)
+			value, err = nil, fmt.Errorf("internal error: %v", r)
 		}
 	}()
 
-	calc := calculator.NewCalculator(precision)
-	parser := ast.NewParser(expression, calc)
-	result = parser.Parse().Evaluate()
-	return result, nil
+	opts = append([]calculator.Option{calculator.WithRates(s.currentRates())}, opts...)
+	calc = calculator.NewCalculator(precision, opts...)
+	node, err := ast.NewParser(expression, calc).Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+	value, err = node.Evaluate()
+	if err != nil {
+		return nil, nil, err
+	}
+	return value, calc, nil
+}
+
+func (s *CalcServer) runCalc(expression string, precision int32, opts ...calculator.Option) (result string, err error) {
+	value, calc, err := s.evaluate(expression, precision, opts...)
+	if err != nil {
+		return "", err
+	}
+	return value.Format(calc), nil
 }
 
 func (s *CalcServer) handleToolCall(arguments map[string]any) (*mcp.CallToolResult, error) {
@@ -100,31 +368,131 @@ func (s *CalcServer) handleToolCall(arguments map[string]any) (*mcp.CallToolResu
 		return nil, fmt.Errorf("expression is required")
 	}
 
-	var precision int
-	if exist, ok := arguments["precision"]; ok {
-		if precision, ok = exist.(int); !ok {
-			precision = 10
+	precision, err := precisionArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []calculator.Option
+	if name, ok := arguments["precision_mode"].(string); ok {
+		mode, err := calculator.ParsePrecisionMode(name)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, calculator.WithPrecisionMode(mode))
+	}
+	if name, ok := arguments["rounding"].(string); ok {
+		mode, err := calculator.ParseRoundingMode(name)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, calculator.WithRounding(mode))
+	}
+	if name, ok := arguments["angle_unit"].(string); ok {
+		unit, err := calculator.ParseAngleUnit(name)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, calculator.WithAngleUnit(unit))
+	}
+	if exact, _ := arguments["exact"].(bool); exact {
+		opts = append(opts, calculator.WithExact())
+	}
+	if name, ok := arguments["base"].(string); ok {
+		base, err := calculator.ParseNumberBase(name)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, calculator.WithBase(base))
+	}
+	if name, ok := arguments["uncertainty"].(string); ok {
+		mode, err := calculator.ParseUncertaintyMode(name)
+		if err != nil {
+			return nil, err
 		}
+		opts = append(opts, calculator.WithUncertainty(mode))
 	}
 
-	result, err := s.runCalc(expression, int32(precision))
+	value, calc, err := s.evaluate(expression, precision, opts...)
 	if err != nil {
 		log.Printf("Error running calc: %v", err)
 		return nil, err
 	}
+	result := value.Format(calc)
+	// 矩阵结果以 markdown 表格输出
+	if m, ok := value.(ast.Matrix); ok {
+		result = formatMatrix(calc, m.Matrix)
+	}
 
-	return &mcp.CallToolResult{
-		Content: []any{
-			map[string]any{
-				"type": "text",
-				"text": result,
-			},
+	content := []any{
+		map[string]any{
+			"type": "text",
+			"text": result,
 		},
-	}, nil
+	}
+	// 金额结果附带所用汇率的日期
+	if _, ok := value.(ast.Money); ok {
+		encoded, err := json.Marshal(map[string]string{"result": result, "rates_as_of": calc.Rates().AsOf})
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, map[string]any{
+			"type": "text",
+			"text": string(encoded),
+		})
+	}
+
+	return &mcp.CallToolResult{Content: content}, nil
+}
+
+// formatMatrix 将矩阵格式化为 markdown 表格，首行和首列为列号和行号
+func formatMatrix(calc *calculator.Calculator, m calculator.Matrix) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d×%d matrix\n\n|", m.Rows(), m.Cols())
+	for j := range m.Cols() {
+		fmt.Fprintf(&b, " | %d", j+1)
+	}
+	b.WriteString(" |\n|---|")
+	b.WriteString(strings.Repeat("---:|", m.Cols()))
+	b.WriteString("\n")
+	for i, row := range m {
+		fmt.Fprintf(&b, "| %d", i+1)
+		for _, x := range row {
+			fmt.Fprintf(&b, " | %s", calc.Format(x))
+		}
+		b.WriteString(" |\n")
+	}
+	return b.String()
+}
+
+// maxPrecision 工具参数 precision 允许的最大值
+const maxPrecision = 75
+
+// precisionArgument 读取 precision 参数，默认为 10，超出 0 到 maxPrecision 的范围时返回错误
+func precisionArgument(arguments map[string]any) (int32, error) {
+	precision := intArgument(arguments, "precision", 10)
+	if precision < 0 || precision > maxPrecision {
+		return 0, fmt.Errorf("precision must be between 0 and %d, got %d", maxPrecision, precision)
+	}
+	return int32(precision), nil
+}
+
+// intArgument 读取整数类型的参数，JSON 解码得到的数字为 float64
+func intArgument(arguments map[string]any, name string, fallback int) int {
+	switch v := arguments[name].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return fallback
 }
 
-func NewCalcServer() *server.MCPServer {
+func NewCalcServer(opts ...ServerOption) *server.MCPServer {
 	calcServer := &CalcServer{}
+	for _, opt := range opts {
+		opt(calcServer)
+	}
 
 	s := server.NewMCPServer(
 		"calculator-mcp",
@@ -142,5 +510,33 @@ func NewCalcServer() *server.MCPServer {
 	}
 	s.AddTool(tool, calcServer.handleToolCall)
 
+	log.Printf("Adding linsolve tool...")
+	s.AddTool(mcp.Tool{
+		Name:        "linsolve",
+		Description: linsolveDescriptionEN,
+		InputSchema: linsolveInputSchema,
+	}, calcServer.handleLinsolve)
+
+	log.Printf("Adding simplify tool...")
+	s.AddTool(mcp.Tool{
+		Name:        "simplify",
+		Description: simplifyDescriptionEN,
+		InputSchema: simplifyInputSchema,
+	}, calcServer.handleSimplify)
+
+	log.Printf("Adding amortize tool...")
+	s.AddTool(mcp.Tool{
+		Name:        "amortize",
+		Description: amortizeDescriptionEN,
+		InputSchema: amortizeInputSchema,
+	}, calcServer.handleAmortize)
+
+	log.Printf("Adding rates tool...")
+	s.AddTool(mcp.Tool{
+		Name:        "rates",
+		Description: ratesDescriptionEN,
+		InputSchema: ratesInputSchema,
+	}, calcServer.handleRates)
+
 	return s
 }
@@ -0,0 +1,393 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+func TestRunCalcConcurrentPrecision(t *testing.T) {
+	tests := []struct {
+		expression string
+		precision  int32
+		expected   string
+	}{
+		{"10 / 3", 3, "3.333"},
+		{"2 / 3", 20, "0.66666666666666666667"},
+		{"1 / 7", 40, "0.1428571428571428571428571428571428571429"},
+		{"sqrt(2)", 30, "1.41421356237309504880168872421"},
+		{"sqrt(3)", 60, "1.732050807568877293527446341505872366942805253810380628055807"},
+		{"PI / 4", 50, "0.78539816339744830961566084581987572104929234984378"},
+	}
+
+	s := &CalcServer{}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for _, test := range tests {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := s.runCalc(test.expression, test.precision)
+				if err != nil {
+					t.Errorf("对于输入 %s (精度 %d): 计算失败: %v", test.expression, test.precision, err)
+					return
+				}
+				if result != test.expected {
+					t.Errorf("对于输入 %s (精度 %d): 期望 %s, 得到 %s", test.expression, test.precision, test.expected, result)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+func TestHandleToolCall(t *testing.T) {
+	s := &CalcServer{}
+	tests := []struct {
+		arguments map[string]any
+		expected  string
+	}{
+		{map[string]any{"expression": "1 / 3"}, "0.3333333333"},
+		{map[string]any{"expression": "1 / 3", "precision": float64(4)}, "0.3333"},
+		{map[string]any{"expression": "2 / 3", "precision": float64(2), "rounding": "down"}, "0.66"},
+		{map[string]any{"expression": "0.125", "precision": float64(2), "rounding": "half_even"}, "0.12"},
+		{map[string]any{"expression": "200000 / 3", "precision": float64(3), "precision_mode": "significant"}, "66700"},
+		{map[string]any{"expression": "sqrt(0 - 4)", "precision": float64(2)}, "0 + 2i"},
+		{map[string]any{"expression": "1/3*3", "exact": true}, "1"},
+		{map[string]any{"expression": "1/3+1/4", "exact": true}, "7/12 ≈ 0.5833333333"},
+		{map[string]any{"expression": "asin(0.5)", "angle_unit": "deg"}, "30"},
+		{map[string]any{"expression": "sin(30°)", "precision": float64(4)}, "0.5"},
+		{map[string]any{"expression": "5!", "precision": float64(2)}, "120"},
+		{map[string]any{"expression": "beta(2, 3)", "precision": float64(6)}, "0.083333"},
+		{map[string]any{"expression": "factor(168)"}, "2^3 * 3 * 7"},
+		{map[string]any{"expression": "stddev(2,4,4,4,5,5,7,9)", "precision": float64(2)}, "2"},
+		{map[string]any{"expression": "1 - normcdf(6)", "precision": float64(20)}, "0.00000000098658764504"},
+		{map[string]any{"expression": "pmt(0.005, 360, 200000)", "precision": float64(2)}, "-1199.1"},
+		{map[string]any{"expression": "pmt(0.05 / 12, 360, 200000)", "precision": float64(2)}, "-1073.64"},
+		{map[string]any{"expression": "5 km + 300 m in mi", "precision": float64(4)}, "3.2933 mi"},
+		{map[string]any{"expression": "0xF0 | 0x0F", "base": "hex"}, "0xff"},
+		{map[string]any{"expression": "1 << 10", "base": "bin"}, "0b10000000000"},
+		{map[string]any{"expression": "interval(2.9, 3.1) * 2", "precision": float64(2)}, "6.00 ± 0.20"},
+		{map[string]any{"expression": "(3±0.1) * (2±0.2)", "precision": float64(3), "uncertainty": "gaussian"}, "6.000 ± 0.632"},
+		{map[string]any{"expression": "inv([1, 2; 3, 4])", "precision": float64(1)}, "2×2 matrix\n\n| | 1 | 2 |\n|---|---:|---:|\n| 1 | -2 | 1 |\n| 2 | 1.5 | -0.5 |\n"},
+		{map[string]any{"expression": "diff(x^2*sin(x), x)"}, "2*x*sin(x) + x^2*cos(x)"},
+		{map[string]any{"expression": "diff(sin(x), x)", "angle_unit": "deg"}, "PI/180*cos(x)"},
+	}
+
+	for _, test := range tests {
+		res, err := s.handleToolCall(test.arguments)
+		if err != nil {
+			t.Fatalf("参数 %v: 调用失败: %v", test.arguments, err)
+		}
+		text := res.Content[0].(map[string]any)["text"]
+		if text != test.expected {
+			t.Errorf("参数 %v: 期望 %s, 得到 %v", test.arguments, test.expected, text)
+		}
+	}
+
+	// precision 超出 0 到 75 的范围时返回错误，而不是按该精度计算
+	for _, precision := range []float64{-1, 76, 100000} {
+		if _, err := s.handleToolCall(map[string]any{"expression": "1 / 3", "precision": precision}); err == nil || !strings.Contains(err.Error(), "precision must be between 0 and 75") {
+			t.Errorf("precision %v: 期望范围错误, 得到 %v", precision, err)
+		}
+		if _, err := s.handleLinsolve(map[string]any{"matrix": []any{[]any{float64(1)}}, "rhs": []any{float64(1)}, "precision": precision}); err == nil || !strings.Contains(err.Error(), "precision must be between 0 and 75") {
+			t.Errorf("linsolve precision %v: 期望范围错误, 得到 %v", precision, err)
+		}
+	}
+}
+
+func TestHandleAmortize(t *testing.T) {
+	s := &CalcServer{}
+	res, err := s.handleAmortize(map[string]any{"principal": float64(1000), "annual_rate": 0.12, "years": float64(1)})
+	if err != nil {
+		t.Fatalf("调用失败: %v", err)
+	}
+	table := res.Content[0].(map[string]any)["text"].(string)
+	for _, row := range []string{
+		"| 1 | 88.85 | 10.00 | 78.85 | 921.15 |",
+		"| 11 | 88.85 | 1.75 | 87.10 | 87.96 |",
+		"| 12 | 88.84 | 0.88 | 87.96 | 0.00 |",
+	} {
+		if !strings.Contains(table, row) {
+			t.Errorf("表格中缺少 %s:\n%s", row, table)
+		}
+	}
+
+	var data struct {
+		TotalInterest string `json:"total_interest"`
+		Schedule      []struct {
+			Period  int    `json:"period"`
+			Balance string `json:"balance"`
+		} `json:"schedule"`
+	}
+	if err := json.Unmarshal([]byte(res.Content[1].(map[string]any)["text"].(string)), &data); err != nil {
+		t.Fatalf("JSON 解析失败: %v", err)
+	}
+	if len(data.Schedule) != 12 || data.Schedule[11].Balance != "0.00" || data.TotalInterest != "66.19" {
+		t.Errorf("JSON 结果不正确: %+v", data)
+	}
+
+	// 利息 10.005 按银行家舍入为 10.00
+	res, err = s.handleAmortize(map[string]any{"principal": 1000.5, "annual_rate": 0.01, "years": float64(1), "frequency": "annual"})
+	if err != nil {
+		t.Fatalf("调用失败: %v", err)
+	}
+	if table := res.Content[0].(map[string]any)["text"].(string); !strings.Contains(table, "| 1 | 1010.50 | 10.00 | 1000.50 | 0.00 |") {
+		t.Errorf("表格不正确:\n%s", table)
+	}
+
+	if _, err := s.handleAmortize(map[string]any{"principal": float64(1000), "annual_rate": 0.1, "years": 0.3}); err == nil {
+		t.Errorf("期数不是整数时应当返回错误")
+	}
+	if _, err := s.handleAmortize(map[string]any{"principal": "1000.005", "annual_rate": 0.1, "years": float64(1)}); err == nil || !strings.Contains(err.Error(), "分") {
+		t.Errorf("本金不足一分时应当返回错误, 得到 %v", err)
+	}
+	if _, err := s.handleAmortize(map[string]any{"principal": "1000.500", "annual_rate": 0.1, "years": float64(1)}); err != nil {
+		t.Errorf("本金末尾的零不应导致错误: %v", err)
+	}
+}
+
+func TestHandleRates(t *testing.T) {
+	s := &CalcServer{}
+	if _, err := s.handleRates(map[string]any{}); err == nil {
+		t.Errorf("没有汇率表时应当返回错误")
+	}
+
+	res, err := s.handleRates(map[string]any{
+		"action": "update",
+		"base":   "USD",
+		"as_of":  "2024-01-31",
+		"rates":  map[string]any{"EUR": 0.92, "JPY": "148.2"},
+	})
+	if err != nil {
+		t.Fatalf("调用失败: %v", err)
+	}
+	if table := res.Content[0].(map[string]any)["text"].(string); !strings.Contains(table, "| JPY | 148.2 |") {
+		t.Errorf("表格不正确:\n%s", table)
+	}
+
+	// 以 EUR 报价的汇率换算为以 USD 为基准
+	res, err = s.handleRates(map[string]any{
+		"action": "update",
+		"base":   "EUR",
+		"as_of":  "2024-02-01",
+		"rates":  map[string]any{"CNY": "7.8"},
+	})
+	if err != nil {
+		t.Fatalf("调用失败: %v", err)
+	}
+	var data ratesJSON
+	if err := json.Unmarshal([]byte(res.Content[1].(map[string]any)["text"].(string)), &data); err != nil {
+		t.Fatalf("JSON 解析失败: %v", err)
+	}
+	if data.Base != "USD" || data.RatesAsOf != "2024-02-01" || data.Rates["CNY"] != "7.176" || data.Rates["USD"] != "1" {
+		t.Errorf("JSON 结果不正确: %+v", data)
+	}
+
+	res, err = s.handleToolCall(map[string]any{"expression": "100 USD + 50 EUR in JPY"})
+	if err != nil {
+		t.Fatalf("调用失败: %v", err)
+	}
+	if text := res.Content[0].(map[string]any)["text"]; text != "22874 JPY" {
+		t.Errorf("期望 22874 JPY, 得到 %v", text)
+	}
+	var result map[string]string
+	if err := json.Unmarshal([]byte(res.Content[1].(map[string]any)["text"].(string)), &result); err != nil {
+		t.Fatalf("JSON 解析失败: %v", err)
+	}
+	if result["rates_as_of"] != "2024-02-01" {
+		t.Errorf("期望 rates_as_of 为 2024-02-01, 得到 %v", result)
+	}
+
+	if _, err := s.handleRates(map[string]any{"action": "update", "base": "GBP", "rates": map[string]any{"CHF": 1.1}}); err == nil {
+		t.Errorf("基准货币不在汇率表中时应当返回错误")
+	}
+
+	// 以 EUR 报价时给出的 USD 汇率与汇率表不完全互逆，基准货币的汇率保持为 1
+	if _, err := s.handleRates(map[string]any{"action": "update", "base": "EUR", "rates": map[string]any{"USD": 1.087, "GBP": 0.86}}); err != nil {
+		t.Errorf("更新汇率失败: %v", err)
+	}
+	if converted, err := s.runCalc("100 USD in USD", 10); err != nil || converted != "100.00 USD" {
+		t.Errorf("期望 100.00 USD, 得到 %s, %v", converted, err)
+	}
+	if _, err := s.handleRates(map[string]any{"action": "update", "base": "EUR", "rates": map[string]any{"EUR": 2, "CHF": 1.1}}); err == nil {
+		t.Errorf("报价货币的汇率不为 1 时应当返回错误")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rates.csv")
+	if err := os.WriteFile(path, []byte("currency,rate,as_of\nEUR,1,2024-03-01\nUSD,1.08,2024-03-01\nKWD,0.332,2024-03-01\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// 只能重新加载启动时指定的汇率表文件
+	if _, err := s.handleRates(map[string]any{"action": "load", "file": path}); err == nil {
+		t.Errorf("没有指定汇率表文件时应当返回错误")
+	}
+	s.ratesFile = path
+	if _, err := s.handleRates(map[string]any{"action": "load"}); err != nil {
+		t.Fatalf("调用失败: %v", err)
+	}
+	converted, err := s.runCalc("10 EUR in KWD", 10)
+	if err != nil {
+		t.Fatalf("计算失败: %v", err)
+	}
+	if converted != "3.320 KWD" {
+		t.Errorf("期望 3.320 KWD, 得到 %s", converted)
+	}
+
+	// 读取文件前先检查扩展名与大小
+	if _, err := calculator.LoadRates(filepath.Join(dir, "missing.txt")); err == nil || os.IsNotExist(err) {
+		t.Errorf("期望不支持的格式错误, 得到 %v", err)
+	}
+	large := filepath.Join(dir, "large.csv")
+	if err := os.WriteFile(large, make([]byte, 2<<20), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := calculator.LoadRates(large); err == nil {
+		t.Errorf("超过大小限制的汇率表文件应当返回错误")
+	}
+}
+
+func TestHandleLinsolve(t *testing.T) {
+	s := &CalcServer{}
+	res, err := s.handleLinsolve(map[string]any{
+		"matrix":    []any{[]any{float64(2), float64(1)}, []any{float64(1), "3"}},
+		"rhs":       []any{float64(3), float64(5)},
+		"precision": float64(4),
+	})
+	if err != nil {
+		t.Fatalf("调用失败: %v", err)
+	}
+	table := res.Content[0].(map[string]any)["text"].(string)
+	for _, row := range []string{
+		"| x1 | 0.8 |",
+		"| x2 | 1.4 |",
+		"| 1 | 3.618 | [0.5257, 0.8507] |",
+		"| 2 | 1.382 | [0.8507, -0.5257] |",
+	} {
+		if !strings.Contains(table, row) {
+			t.Errorf("表格中缺少 %s:\n%s", row, table)
+		}
+	}
+
+	var data struct {
+		Status   string   `json:"status"`
+		Solution []string `json:"solution"`
+		Eigen    struct {
+			Values  []string   `json:"values"`
+			Vectors [][]string `json:"vectors"`
+		} `json:"eigen"`
+	}
+	if err := json.Unmarshal([]byte(res.Content[1].(map[string]any)["text"].(string)), &data); err != nil {
+		t.Fatalf("JSON 解析失败: %v", err)
+	}
+	if data.Status != "solved" || len(data.Solution) != 2 || data.Solution[0] != "0.8" ||
+		len(data.Eigen.Values) != 2 || data.Eigen.Values[0] != "3.618" || data.Eigen.Vectors[1][1] != "-0.5257" {
+		t.Errorf("JSON 结果不正确: %+v", data)
+	}
+
+	tests := []struct {
+		matrix []any
+		rhs    []any
+		status string
+	}{
+		{[]any{[]any{float64(1), float64(2)}, []any{float64(2), float64(4)}}, []any{float64(5), float64(10)}, "underdetermined"},
+		{[]any{[]any{float64(1), float64(2)}, []any{float64(2), float64(4)}}, []any{float64(5), float64(11)}, "inconsistent"},
+		{[]any{[]any{float64(1), float64(2), float64(3)}}, []any{float64(6)}, "underdetermined"},
+		{[]any{[]any{float64(1)}, []any{float64(1)}}, []any{float64(1), float64(2)}, "inconsistent"},
+		{[]any{[]any{float64(1)}, []any{float64(2)}}, []any{float64(1), float64(2)}, "solved"},
+	}
+	for _, test := range tests {
+		res, err := s.handleLinsolve(map[string]any{"matrix": test.matrix, "rhs": test.rhs})
+		if err != nil {
+			t.Fatalf("矩阵 %v: 调用失败: %v", test.matrix, err)
+		}
+		var data struct {
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal([]byte(res.Content[1].(map[string]any)["text"].(string)), &data); err != nil {
+			t.Fatalf("JSON 解析失败: %v", err)
+		}
+		if data.Status != test.status {
+			t.Errorf("矩阵 %v: 期望状态 %s, 得到 %s", test.matrix, test.status, data.Status)
+		}
+	}
+
+	// 有复特征值时仍给出解，并说明无法计算特征值
+	res, err = s.handleLinsolve(map[string]any{
+		"matrix": []any{[]any{float64(0), float64(1)}, []any{float64(-1), float64(0)}},
+		"rhs":    []any{float64(1), float64(1)},
+	})
+	if err != nil {
+		t.Fatalf("调用失败: %v", err)
+	}
+	if text := res.Content[0].(map[string]any)["text"].(string); !strings.Contains(text, "| x1 | -1 |") || !strings.Contains(text, "复特征值") {
+		t.Errorf("结果不正确:\n%s", text)
+	}
+
+	// 不可对角化的矩阵只给出特征值，并说明特征向量线性相关；重特征值的单位矩阵仍有两个特征向量
+	for _, test := range []struct {
+		matrix  []any
+		vectors int
+	}{
+		{[]any{[]any{float64(1), float64(1)}, []any{float64(0), float64(1)}}, 0},
+		{[]any{[]any{float64(1), float64(0)}, []any{float64(0), float64(1)}}, 2},
+	} {
+		res, err := s.handleLinsolve(map[string]any{"matrix": test.matrix, "rhs": []any{float64(1), float64(1)}})
+		if err != nil {
+			t.Fatalf("矩阵 %v: 调用失败: %v", test.matrix, err)
+		}
+		var data struct {
+			Eigen struct {
+				Values  []string   `json:"values"`
+				Vectors [][]string `json:"vectors"`
+			} `json:"eigen"`
+			EigenError string `json:"eigen_error"`
+		}
+		if err := json.Unmarshal([]byte(res.Content[1].(map[string]any)["text"].(string)), &data); err != nil {
+			t.Fatalf("JSON 解析失败: %v", err)
+		}
+		if len(data.Eigen.Values) != 2 || data.Eigen.Values[0] != "1" || data.Eigen.Values[1] != "1" || len(data.Eigen.Vectors) != test.vectors {
+			t.Errorf("矩阵 %v: JSON 结果不正确: %+v", test.matrix, data)
+		}
+		if defective := strings.Contains(data.EigenError, "线性相关"); defective != (test.vectors == 0) {
+			t.Errorf("矩阵 %v: eigen_error 不正确: %q", test.matrix, data.EigenError)
+		}
+	}
+
+	if _, err := s.handleLinsolve(map[string]any{"matrix": []any{[]any{float64(1), float64(2)}, []any{float64(3)}}, "rhs": []any{float64(1), float64(2)}}); err == nil {
+		t.Errorf("各行元素个数不同时应当返回错误")
+	}
+}
+
+func TestHandleSimplify(t *testing.T) {
+	s := &CalcServer{}
+	tests := []struct {
+		arguments map[string]any
+		expected  string
+	}{
+		{map[string]any{"expression": "x + x"}, "2*x"},
+		{map[string]any{"expression": "sin(t)^2 + cos(t)^2 + ln(E^t)"}, "t + 1"},
+		{map[string]any{"expression": "diff(sin(x), x)", "angle_unit": "deg"}, "PI*cos(x)/180"},
+	}
+	for _, test := range tests {
+		res, err := s.handleSimplify(test.arguments)
+		if err != nil {
+			t.Fatalf("对于参数 %v: 调用失败: %v", test.arguments, err)
+		}
+		if result := res.Content[0].(map[string]any)["text"].(string); result != test.expected {
+			t.Errorf("对于参数 %v: 期望 %s, 得到 %s", test.arguments, test.expected, result)
+		}
+	}
+
+	for _, arguments := range []map[string]any{{}, {"expression": "x +"}, {"expression": "1/(x - x)"}} {
+		if _, err := s.handleSimplify(arguments); err == nil {
+			t.Errorf("对于参数 %v: 期望返回错误", arguments)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/to404hanga/calculator-mcp/ast"
+	"github.com/to404hanga/calculator-mcp/calculator"
+)
+
+const simplifyDescriptionEN = `Symbolic Simplifier
+Simplifies an expression in one or more variables and returns the simplified expression:
+
+- expression: The expression to simplify, e.g., x + x or sin(x)^2 + cos(x)^2;
+  every identifier other than a constant (PI, E) or function name is a variable
+- angle_unit: Angle unit used by derivatives of trigonometric functions: "rad" (default), "deg" or "grad"
+
+The simplifier folds numeric constants exactly, collects like terms (x + x = 2*x, x*x = x^2),
+applies identities such as x*1 = x, x^0 = 1, ln(E^x) = x, log(x^n, x) = n and sin(x)^2 + cos(x)^2 = 1,
+and puts terms and factors in a canonical order: terms by descending degree with the constant last.
+diff(expr, x) inside the expression is differentiated first, so simplify(diff(x/(x + 1), x)) = 1/(x + 1)^2.
+Numeric factors are distributed over sums, but products and powers of sums are not expanded.
+
+The result uses the calc tool's syntax, where ^ binds tighter than * and / and groups from the right,
+so it can be passed back to calc, e.g., simplify(1 - x/2) = -x/2 + 1.`
+
+var simplifyInputSchema = mcp.ToolInputSchema{
+	Type: "object",
+	Properties: map[string]any{
+		"expression": map[string]any{
+			"type":        "string",
+			"description": "The expression to simplify",
+		},
+		"angle_unit": map[string]any{
+			"type":        "string",
+			"description": "The angle unit used by trigonometric derivatives: rad (default), deg or grad",
+		},
+	},
+	Required: []string{"expression"},
+}
+
+// simplify 解析并化简符号表达式，返回化简结果的中缀形式
+func simplify(expression string, opts ...calculator.Option) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = "", fmt.Errorf("internal error: %v", r)
+		}
+	}()
+
+	calc := calculator.NewCalculator(10, opts...)
+	node, err := ast.NewParser(expression, calc).ParseSymbolic()
+	if err != nil {
+		return "", err
+	}
+	node, err = ast.Simplify(node, calc)
+	if err != nil {
+		return "", err
+	}
+	return ast.Infix(node), nil
+}
+
+func (s *CalcServer) handleSimplify(arguments map[string]any) (*mcp.CallToolResult, error) {
+	log.Printf("handleSimplify called with arguments: %+v", arguments)
+
+	expression, ok := arguments["expression"].(string)
+	if !ok {
+		return nil, fmt.Errorf("expression is required")
+	}
+	var opts []calculator.Option
+	if name, ok := arguments["angle_unit"].(string); ok {
+		unit, err := calculator.ParseAngleUnit(name)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, calculator.WithAngleUnit(unit))
+	}
+
+	result, err := simplify(expression, opts...)
+	if err != nil {
+		log.Printf("Error running simplify: %v", err)
+		return nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []any{
+			map[string]any{
+				"type": "text",
+				"text": result,
+			},
+		},
+	}, nil
+}